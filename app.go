@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -39,6 +40,10 @@ type SearchResult struct {
 	PrimaryActionLabel   string `json:"primaryActionLabel"`
 	SecondaryActionLabel string `json:"secondaryActionLabel,omitempty"`
 	SupportsActions      bool   `json:"supportsActions"`
+	// TitleMatches and SubtitleMatches mark the runes the query matched, for
+	// the frontend to highlight.
+	TitleMatches    []search.Range `json:"titleMatches,omitempty"`
+	SubtitleMatches []search.Range `json:"subtitleMatches,omitempty"`
 }
 
 type ContextAction struct {
@@ -47,6 +52,9 @@ type ContextAction struct {
 	Icon        string `json:"icon"`
 	Shortcut    string `json:"shortcut,omitempty"`
 	Destructive bool   `json:"destructive,omitempty"`
+	// Children makes the action a submenu ("Open With…") that lists them
+	// instead of running anything itself.
+	Children []ContextAction `json:"children,omitempty"`
 }
 
 type CommandDefinition struct {
@@ -68,7 +76,7 @@ type BlightConfig struct {
 	FirstRun     bool     `json:"firstRun"`
 	Hotkey       string   `json:"hotkey"`
 	MaxClipboard int      `json:"maxClipboard"`
-	IndexDirs    []string `json:"indexDirs,omitempty"`
+	IndexDirs    []string `json:"indexDirs,omitempty"` // deprecated: migrated to IndexRoots
 
 	// Search behaviour
 	MaxResults  int `json:"maxResults"`
@@ -92,11 +100,23 @@ type BlightConfig struct {
 	LastIndexedAt  time.Time `json:"lastIndexedAt,omitempty"`
 
 	// File index behaviour
-	DisableFolderIndex bool `json:"disableFolderIndex,omitempty"`
+	DisableFolderIndex bool         `json:"disableFolderIndex,omitempty"`
+	ContentSearch      bool         `json:"contentSearch,omitempty"`
+	IndexRoots         []files.Root `json:"indexRoots"`
+	IndexRules         *files.Rules `json:"indexRules,omitempty"`
+
+	// Recent files: the "recent" keyword and the optional home screen section
+	ShowRecentFiles bool     `json:"showRecentFiles,omitempty"`
+	RecentRoots     []string `json:"recentRoots,omitempty"` // empty: every index root
+	RecentIgnore    []string `json:"recentIgnore"`
 
 	// Web search
 	SearchEngineURL string `json:"searchEngineURL,omitempty"`
 
+	// Ranking overrides the default category weights, caps and tie-break
+	// order (see search.DefaultProfile); nil keeps the defaults.
+	Ranking *search.Profile `json:"ranking,omitempty"`
+
 	// User-defined aliases and commands
 	Aliases     map[string]string   `json:"aliases,omitempty"`
 	Commands    []CommandDefinition `json:"commands,omitempty"`
@@ -106,10 +126,14 @@ type BlightConfig struct {
 type App struct {
 	ctx          context.Context
 	config       BlightConfig
-	scanner      *apps.Scanner
-	usage        *search.UsageTracker
-	clipboard    *commands.ClipboardHistory
-	fileIdx      *files.FileIndex
+	scanner      appCatalog
+	usage        usageStore
+	clipboard    clipboardHistory
+	fileIdx      fileIndex
+	providers    *search.Registry[SearchResult]
+	searchMu     sync.Mutex
+	searchCancel context.CancelFunc // cancels the in-flight Search query
+	lastQuery    string             // the query whose results are showing; guarded by searchMu
 	hotkey       *hotkey.HotkeyManager
 	tray         *tray.TrayIcon
 	visible      atomic.Bool
@@ -117,14 +141,23 @@ type App struct {
 	settingsOpen atomic.Bool // prevents spawning multiple settings windows
 	version      string
 	settingsMode bool
+
+	// savedIdx is the saved file index, loaded on demand for rule previews
+	// and root status in the standalone settings window.
+	savedIdx     *files.FileIndex
+	savedIdxOnce sync.Once
 }
 
 func NewApp(version string) *App {
-	return &App{version: version}
+	a := &App{version: version}
+	a.providers = a.newProviderRegistry()
+	return a
 }
 
 func NewSettingsApp(version string) *App {
-	return &App{version: version, settingsMode: true}
+	a := &App{version: version, settingsMode: true}
+	a.providers = a.newProviderRegistry()
+	return a
 }
 
 func (a *App) startup(ctx context.Context) {
@@ -146,7 +179,7 @@ func (a *App) startup(ctx context.Context) {
 	go func() {
 		a.scanner.Scan()
 		log.Info("app scanner ready", map[string]interface{}{"appCount": len(a.scanner.Apps())})
-		runtime.EventsEmit(a.ctx, "appsReady")
+		a.emit("appsReady")
 	}()
 
 	a.usage = search.NewUsageTracker()
@@ -157,18 +190,31 @@ func (a *App) startup(ctx context.Context) {
 	go a.clipboard.PollClipboard()
 	log.Debug("clipboard polling started")
 
-	a.fileIdx = files.NewFileIndex(a.config.IndexDirs, func(status files.IndexStatus) {
+	a.fileIdx = files.NewFileIndex(a.config.IndexRoots, func(status files.IndexStatus) {
 		log.Debug("index status changed", map[string]interface{}{"state": status.State, "message": status.Message, "count": status.Count})
 		runtime.EventsEmit(ctx, "indexStatus", status)
 		if status.State == "ready" {
-			a.config.LastIndexedAt = time.Now()
+			a.config.LastIndexedAt = a.fileIdx.LastIndexed()
 			_ = a.saveConfig()
 		}
 	})
-	const staleAge = 72 * time.Hour
-	if a.fileIdx.IsStale(staleAge) {
-		a.fileIdx.Start()
+	if a.config.IndexRules != nil {
+		if err := a.fileIdx.SetRules(*a.config.IndexRules); err != nil {
+			log.Error("invalid index rules, using defaults", map[string]interface{}{"error": err.Error()})
+		}
 	}
+	a.fileIdx.SetContentSearch(a.config.ContentSearch)
+	go func() {
+		if err := a.fileIdx.Load(); err != nil && !os.IsNotExist(err) {
+			log.Warn("file index cache unusable, rebuilding", map[string]interface{}{"error": err.Error()})
+		}
+		const staleAge = 72 * time.Hour
+		if a.fileIdx.IsStale(staleAge) {
+			a.fileIdx.Start()
+		} else {
+			a.fileIdx.Watch()
+		}
+	}()
 	log.Info("file indexer started")
 
 	hotkeyStr := a.config.Hotkey
@@ -199,6 +245,7 @@ func (a *App) startup(ctx context.Context) {
 func (a *App) settingsOnlyStartup(ctx context.Context) {
 	a.ctx = ctx
 	a.loadConfig()
+	a.usage = search.NewUsageTracker()
 	runtime.EventsEmit(ctx, "openSettings")
 }
 
@@ -220,6 +267,15 @@ func (a *App) shutdown(ctx context.Context) {
 	log.Info("cleanup complete")
 }
 
+// emit sends an event to the frontend. Before startup there is no Wails
+// context to send it through (as when tests drive the App), and it is dropped.
+func (a *App) emit(event string, data ...interface{}) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, event, data...)
+}
+
 func (a *App) CheckForUpdates() UpdateInfo {
 	u := updater.New("devatblight/blight")
 	log := debug.Get()
@@ -339,7 +395,8 @@ func (a *App) ToggleWindow() {
 		runtime.WindowHide(a.ctx)
 		a.visible.Store(false)
 	} else {
-		a.loadConfig()
+		a.reloadConfig()
+		a.usage.Reload()
 		a.lastShownAt.Store(time.Now().UnixNano())
 		// Reset to compact height and re-centre so the search bar is always at
 		// a predictable screen position; results will grow downward from there.
@@ -352,7 +409,8 @@ func (a *App) ToggleWindow() {
 }
 
 func (a *App) ShowWindow() {
-	a.loadConfig()
+	a.reloadConfig()
+	a.usage.Reload()
 	a.lastShownAt.Store(time.Now().UnixNano())
 	a.resetWindowForShow()
 	runtime.WindowShow(a.ctx)
@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 
+	"blight/internal/commands"
 	"blight/internal/debug"
 	"blight/internal/files"
 	"blight/internal/hotkey"
+	"blight/internal/search"
 	"blight/internal/startup"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -41,9 +44,23 @@ func defaultConfig() BlightConfig {
 		FooterHints:         "always",
 		StartOnStartup:      false,
 		HideNotifyIcon:      false,
+		IndexRoots:          files.DefaultRoots(),
+		IndexRules:          defaultIndexRules(),
+		RecentIgnore:        defaultRecentIgnore(),
 	}
 }
 
+// defaultRecentIgnore hides files that change constantly without being
+// anything the user saved: partial downloads, editor swap and lock files.
+func defaultRecentIgnore() []string {
+	return []string{"*.tmp", "*.part", "*.crdownload", "*.download", "~$*", ".~lock.*", "*.swp", "*.lock"}
+}
+
+func defaultIndexRules() *files.Rules {
+	r := files.DefaultRules()
+	return &r
+}
+
 func (a *App) loadConfig() {
 	data, err := os.ReadFile(a.configPath())
 	if err != nil {
@@ -78,6 +95,17 @@ func (a *App) loadConfig() {
 	if a.config.FooterHints == "" {
 		a.config.FooterHints = "always"
 	}
+	if a.config.IndexRules == nil {
+		a.config.IndexRules = defaultIndexRules()
+	}
+
+	// Migrate indexDirs → IndexRoots (extra folders on top of the defaults)
+	if len(a.config.IndexDirs) > 0 {
+		for _, d := range a.config.IndexDirs {
+			a.config.IndexRoots = append(a.config.IndexRoots, files.Root{Path: d})
+		}
+		a.config.IndexDirs = nil
+	}
 
 	// Migrate aliases → CommandDefinitions (idempotent: skip keywords already present)
 	if len(a.config.Aliases) > 0 {
@@ -108,6 +136,33 @@ func (a *App) loadConfig() {
 	}
 }
 
+// reloadConfig re-reads the config, which the settings window saves from
+// another process, and hands the file index the settings it keeps itself,
+// rescanning if the roots or rules changed.
+func (a *App) reloadConfig() {
+	roots, rules := a.config.IndexRoots, a.config.IndexRules
+	a.loadConfig()
+	if a.fileIdx == nil {
+		return
+	}
+	a.fileIdx.SetContentSearch(a.config.ContentSearch)
+	rescan := false
+	if !reflect.DeepEqual(roots, a.config.IndexRoots) {
+		a.fileIdx.SetRoots(a.config.IndexRoots)
+		rescan = true
+	}
+	if !reflect.DeepEqual(rules, a.config.IndexRules) {
+		if err := a.fileIdx.SetRules(*a.config.IndexRules); err != nil {
+			debug.Get().Error("invalid index rules, keeping the previous ones", map[string]interface{}{"error": err.Error()})
+		} else {
+			rescan = true
+		}
+	}
+	if rescan {
+		go a.fileIdx.Reindex()
+	}
+}
+
 func (a *App) saveConfig() error {
 	if err := os.MkdirAll(a.configDir(), 0755); err != nil {
 		return err
@@ -165,15 +220,42 @@ func (a *App) SaveSettings(cfg BlightConfig) error {
 			a.clipboard.SetMaxSize(cfg.MaxClipboard)
 		}
 	}
-	if cfg.IndexDirs != nil {
-		dirsChanged := !stringSlicesEqual(a.config.IndexDirs, cfg.IndexDirs)
-		a.config.IndexDirs = cfg.IndexDirs
+	if cfg.IndexRoots != nil {
+		rootsChanged := !reflect.DeepEqual(a.config.IndexRoots, cfg.IndexRoots)
+		a.config.IndexRoots = cfg.IndexRoots
+		if a.fileIdx != nil {
+			a.fileIdx.SetRoots(cfg.IndexRoots)
+			if rootsChanged {
+				go a.fileIdx.Reindex()
+			}
+		}
+	}
+	if cfg.IndexRules != nil {
+		rulesChanged := !reflect.DeepEqual(a.config.IndexRules, cfg.IndexRules)
 		if a.fileIdx != nil {
-			a.fileIdx.UpdateDirs(cfg.IndexDirs)
-			if dirsChanged {
+			if err := a.fileIdx.SetRules(*cfg.IndexRules); err != nil {
+				return err
+			}
+			if rulesChanged {
 				go a.fileIdx.Reindex()
 			}
 		}
+		a.config.IndexRules = cfg.IndexRules
+	}
+	if cfg.RecentIgnore != nil {
+		if err := files.CheckPatterns(cfg.RecentIgnore); err != nil {
+			return err
+		}
+		a.config.RecentIgnore = cfg.RecentIgnore
+	}
+	if cfg.RecentRoots != nil {
+		a.config.RecentRoots = cfg.RecentRoots
+	}
+	if cfg.Ranking != nil {
+		if err := cfg.Ranking.Validate(); err != nil {
+			return err
+		}
+		a.config.Ranking = cfg.Ranking
 	}
 	if cfg.MaxResults > 0 {
 		a.config.MaxResults = cfg.MaxResults
@@ -202,6 +284,11 @@ func (a *App) SaveSettings(cfg BlightConfig) error {
 	a.config.ShowPlaceholder = cfg.ShowPlaceholder
 	a.config.HideNotifyIcon = cfg.HideNotifyIcon
 	a.config.DisableFolderIndex = cfg.DisableFolderIndex
+	a.config.ContentSearch = cfg.ContentSearch
+	a.config.ShowRecentFiles = cfg.ShowRecentFiles
+	if a.fileIdx != nil {
+		a.fileIdx.SetContentSearch(cfg.ContentSearch)
+	}
 
 	if cfg.Aliases != nil {
 		a.config.Aliases = cfg.Aliases
@@ -267,6 +354,9 @@ func (a *App) ImportSettings(data string) error {
 		return fmt.Errorf("invalid settings JSON: %w", err)
 	}
 	a.config = cfg
+	if a.fileIdx != nil {
+		a.fileIdx.SetContentSearch(cfg.ContentSearch)
+	}
 	return a.saveConfig()
 }
 
@@ -285,6 +375,113 @@ func (a *App) GetUsageScores() map[string]int {
 	return scores
 }
 
+// GetRankingDefaults returns the ranking profile used for categories the
+// user has not tuned, for the settings view to show.
+func (a *App) GetRankingDefaults() search.Profile {
+	return search.DefaultProfile()
+}
+
+// UsageStat describes one item the usage tracker knows about, for the
+// statistics view in settings.
+type UsageStat struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+	LastUsed int64  `json:"lastUsed"` // Unix timestamp, 0 if never launched
+	Score    int    `json:"score"`
+	// Hours and Weekdays count launches by local hour (0-23) and weekday
+	// (Sunday = 0); either may be empty for items recorded before they
+	// were tracked.
+	Hours    []int             `json:"hours"`
+	Weekdays []int             `json:"weekdays"`
+	History  []search.DayCount `json:"history"` // launches per day, oldest first
+	Queries  []string          `json:"queries"` // queries the item was chosen from
+}
+
+// GetUsageStats returns everything the usage tracker has recorded, highest
+// score first, so users can see why items rank where they do.
+func (a *App) GetUsageStats() []UsageStat {
+	if a.usage == nil {
+		return []UsageStat{}
+	}
+	items := a.usage.Items()
+	stats := make([]UsageStat, len(items))
+	for i, it := range items {
+		title, category := describeUsageID(it.ID)
+		stats[i] = UsageStat{
+			ID:       it.ID,
+			Title:    title,
+			Category: category,
+			Count:    it.Count,
+			LastUsed: it.LastUsed,
+			Score:    it.Score,
+			Hours:    it.Hours,
+			Weekdays: it.Weekdays,
+			History:  it.History,
+			Queries:  it.Queries,
+		}
+	}
+	return stats
+}
+
+// describeUsageID turns a result ID into a title and category for display.
+// IDs without a known prefix are application names.
+func describeUsageID(id string) (title, category string) {
+	for _, p := range []struct{ prefix, category string }{
+		{"file-open:", "Files"},
+		{"dir-open:", "Folders"},
+		{"cmd-url:", "Commands"},
+		{"cmd-copy:", "Commands"},
+		{"cmd-path:", "Commands"},
+		{"cmd-shell:", "Commands"},
+		{"web-search:", "Web"},
+		{"url-open:", "Web"},
+		{"calc-result:", "Calculator"},
+		{"alias:", "Aliases"},
+	} {
+		if rest, ok := strings.CutPrefix(id, p.prefix); ok {
+			if p.category == "Files" || p.category == "Folders" {
+				return filepath.Base(rest), p.category
+			}
+			return rest, p.category
+		}
+	}
+	if rest, ok := strings.CutPrefix(id, "sys-"); ok {
+		for _, cmd := range commands.SystemCommands {
+			if cmd.ID == rest {
+				return cmd.Name, "System"
+			}
+		}
+		return rest, "System"
+	}
+	if strings.HasPrefix(id, "clip-") {
+		return "Clipboard entry", "Clipboard"
+	}
+	return id, "Applications"
+}
+
+// ForgetUsage erases what has been learned about one item, so it ranks as if
+// it had never been used.
+func (a *App) ForgetUsage(id string) error {
+	if a.usage == nil {
+		return fmt.Errorf("usage tracking is not available")
+	}
+	a.usage.Forget(id)
+	debug.Get().Info("usage forgotten", map[string]interface{}{"id": id})
+	return nil
+}
+
+// ResetUsage erases all recorded usage and learned queries.
+func (a *App) ResetUsage() error {
+	if a.usage == nil {
+		return fmt.Errorf("usage tracking is not available")
+	}
+	a.usage.Reset()
+	debug.Get().Info("usage reset")
+	return nil
+}
+
 // GetAliases returns the current alias map (trigger → expansion).
 func (a *App) GetAliases() map[string]string {
 	if a.config.Aliases == nil {
@@ -381,6 +578,32 @@ func (a *App) ClearIndex() {
 	a.fileIdx.ClearIndex()
 }
 
+// GetIndexStatus returns the indexer state together with per-root file and
+// folder counts and the errors from the last scan.
 func (a *App) GetIndexStatus() files.IndexStatus {
-	return a.fileIdx.Status()
+	idx := a.indexForSettings()
+	status := idx.Status()
+	status.Roots = idx.RootStatuses()
+	return status
+}
+
+// PreviewIndexRule reports which indexed files and folders an exclude pattern
+// would remove, so the settings UI can show its effect before saving.
+func (a *App) PreviewIndexRule(rule string) (files.RulePreview, error) {
+	return a.indexForSettings().PreviewExclude(rule)
+}
+
+// indexForSettings returns the live file index, or in the standalone settings
+// window, which has none, the one the launcher last saved.
+func (a *App) indexForSettings() fileIndex {
+	if a.fileIdx != nil {
+		return a.fileIdx
+	}
+	a.savedIdxOnce.Do(func() {
+		a.savedIdx = files.NewFileIndex(a.config.IndexRoots, nil)
+		if err := a.savedIdx.Load(); err != nil && !os.IsNotExist(err) {
+			debug.Get().Warn("saved file index unavailable", map[string]interface{}{"error": err.Error()})
+		}
+	})
+	return a.savedIdx
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"blight/internal/files"
+)
+
+// recordingIndex records the settings reloadConfig hands the file index.
+type recordingIndex struct {
+	fileIndex
+	roots     []files.Root
+	rules     files.Rules
+	reindexed chan struct{}
+}
+
+func (x *recordingIndex) SetContentSearch(bool)        {}
+func (x *recordingIndex) SetRoots(roots []files.Root)  { x.roots = roots }
+func (x *recordingIndex) SetRules(r files.Rules) error { x.rules = r; return nil }
+func (x *recordingIndex) Reindex()                     { x.reindexed <- struct{}{} }
+
+func TestReloadConfig_RescansWhenRootsChange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	a := NewApp("test")
+	a.loadConfig()
+	idx := &recordingIndex{reindexed: make(chan struct{}, 1)}
+	a.fileIdx = idx
+
+	a.reloadConfig()
+	select {
+	case <-idx.reindexed:
+		t.Fatal("reloading an unchanged config should not rescan")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The settings window saves from its own process.
+	settings := NewApp("test")
+	settings.loadConfig()
+	settings.config.IndexRoots = []files.Root{{Path: "/home/ana/notes", MaxDepth: 2}}
+	if err := settings.saveConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	a.reloadConfig()
+	select {
+	case <-idx.reindexed:
+	case <-time.After(time.Second):
+		t.Fatal("changed roots were not rescanned")
+	}
+	if !slices.Equal(idx.roots, settings.config.IndexRoots) {
+		t.Errorf("index roots = %+v, want %+v", idx.roots, settings.config.IndexRoots)
+	}
+}
+
+func TestReloadConfig_RescansWhenRulesChange(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	a := NewApp("test")
+	a.loadConfig()
+	idx := &recordingIndex{reindexed: make(chan struct{}, 1)}
+	a.fileIdx = idx
+
+	settings := NewApp("test")
+	settings.loadConfig()
+	settings.config.IndexRules = &files.Rules{Exclude: []string{"Archive/"}, IgnoreFiles: true}
+	if err := settings.saveConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	a.reloadConfig()
+	select {
+	case <-idx.reindexed:
+	case <-time.After(time.Second):
+		t.Fatal("changed rules were not rescanned")
+	}
+	if !slices.Equal(idx.rules.Exclude, []string{"Archive/"}) || !idx.rules.IgnoreFiles {
+		t.Errorf("index rules = %+v, want the saved ones", idx.rules)
+	}
+}
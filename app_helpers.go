@@ -1,64 +1,13 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	goruntime "runtime"
 	"strings"
+	"time"
 )
 
-// enrichResult sets Kind, PrimaryActionLabel, SecondaryActionLabel, and
-// SupportsActions on a SearchResult based on its Category and ID.
-func enrichResult(r SearchResult) SearchResult {
-	switch r.Category {
-	case "Applications", "Pinned", "Recent", "Suggested":
-		r.Kind = "app"
-		r.PrimaryActionLabel = "Open"
-		r.SecondaryActionLabel = "Run as admin"
-		r.SupportsActions = true
-	case "Commands", "Aliases":
-		r.Kind = "command"
-		r.PrimaryActionLabel = "Run"
-		if !strings.HasPrefix(r.ID, "cmd-needs-arg:") {
-			r.SecondaryActionLabel = "Copy value"
-			r.SupportsActions = true
-		}
-	case "Files":
-		if r.ID == "no-results" {
-			return r
-		}
-		r.Kind = "file"
-		r.PrimaryActionLabel = "Open"
-		r.SecondaryActionLabel = "Show in Explorer"
-		r.SupportsActions = true
-	case "Folders":
-		r.Kind = "folder"
-		r.PrimaryActionLabel = "Open"
-		r.SecondaryActionLabel = "Open in Terminal"
-		r.SupportsActions = true
-	case "Clipboard":
-		r.Kind = "clipboard"
-		r.PrimaryActionLabel = "Copy"
-		r.SupportsActions = true
-	case "System":
-		r.Kind = "system"
-		r.PrimaryActionLabel = "Run"
-	case "Web":
-		r.Kind = "web"
-		r.PrimaryActionLabel = "Search"
-	case "Calculator":
-		r.Kind = "calc"
-		r.PrimaryActionLabel = "Copy result"
-	}
-	return r
-}
-
-func enrichResults(rs []SearchResult) []SearchResult {
-	for i := range rs {
-		rs[i] = enrichResult(rs[i])
-	}
-	return rs
-}
-
 // icon returns a Segoe MDL2/Fluent glyph on Windows and a plain emoji on other platforms.
 // Segoe PUA codepoints are meaningless outside Windows, so we fall back to emoji elsewhere.
 func icon(winGlyph, fallback string) string {
@@ -105,6 +54,26 @@ func isURL(s string) bool {
 		strings.HasPrefix(sl, "ftp://")
 }
 
+// relativeTime describes t relative to now, e.g. "5 min ago" or "yesterday".
+func relativeTime(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%d min ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d h ago", int(d.Hours()))
+	case d < 48*time.Hour:
+		return "yesterday"
+	case d < 7*24*time.Hour:
+		return fmt.Sprintf("%d days ago", int(d.Hours()/24))
+	case t.Year() == now.Year():
+		return t.Format("Jan 2")
+	}
+	return t.Format("Jan 2, 2006")
+}
+
 // isAbsPath returns true if s looks like an absolute filesystem path on any
 // supported platform: Windows drive paths (C:\, C:/), UNC paths (\\server\),
 // and Unix-style absolute paths (/home/...).
@@ -120,15 +89,3 @@ func isAbsPath(s string) bool {
 	}
 	return false
 }
-
-func stringSlicesEqual(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	for i := range a {
-		if a[i] != b[i] {
-			return false
-		}
-	}
-	return true
-}
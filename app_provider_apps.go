@@ -0,0 +1,231 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"blight/internal/apps"
+	"blight/internal/search"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// appProvider searches installed applications. It owns any ID that is the name
+// of a scanned app, so it must be registered after the prefixed providers.
+type appProvider struct{ a *App }
+
+func (p appProvider) Name() string   { return "Applications" }
+func (p appProvider) Prefix() string { return "" }
+
+func (p appProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	a := p.a
+	allApps, names := a.scanner.Snapshot()
+	learned := a.usage.QueryScores(query)
+	usageScores := make([]int, len(allApps))
+	for i, app := range allApps {
+		usageScores[i] = a.usage.Score(app.Name) + learned[app.Name]
+		if slices.Contains(a.config.PinnedItems, app.Name) {
+			usageScores[i] += 100
+		}
+	}
+	// Usage counts once, as RankAndCap weighs it: ranking the matches by it
+	// here too keeps well-used apps that match a little worse within limit.
+	profile := a.rankingProfile()
+	rank := func(m search.Match) int {
+		return profile.Normalize(m.Score, usageScores[m.Index], "Applications")
+	}
+	matches := search.Fuzzy(query, names, nil)
+	matches = append(matches, keywordMatches(query, allApps, matches)...)
+	slices.SortStableFunc(matches, func(x, y search.Match) int { return cmp.Compare(rank(y), rank(x)) })
+	limit := min(len(matches), a.maxResults())
+	out := make([]search.Scored[SearchResult], 0, limit)
+	for _, m := range matches[:limit] {
+		app := allApps[m.Index]
+		out = append(out, search.Scored[SearchResult]{
+			Item: SearchResult{
+				ID: app.Name, Title: app.Name, Subtitle: appSubtitle(app), Category: "Applications", Path: app.Path,
+				TitleMatches: search.Ranges(m.Positions),
+			},
+			Score: m.Score,
+			Usage: usageScores[m.Index],
+			Cat:   "Applications",
+		})
+	}
+	return out
+}
+
+// keywordMatches finds the apps whose name did not match query but whose
+// generic name or keywords do ("browser" finds Firefox through its generic
+// name "Web Browser"), for any word starting with query. They score half
+// what the same match on a name would, so apps named like the query come
+// first. A single letter starts too many keywords to mean anything, and
+// matches none.
+func keywordMatches(query string, allApps []apps.AppEntry, named []search.Match) []search.Match {
+	q := search.Fold(strings.TrimSpace(query))
+	if utf8.RuneCountInString(q) < 2 {
+		return nil
+	}
+	matched := make(map[int]bool, len(named))
+	for _, m := range named {
+		matched[m.Index] = true
+	}
+	var out []search.Match
+	for i, app := range allApps {
+		if matched[i] {
+			continue
+		}
+		best := 0
+		for _, term := range append([]string{app.GenericName}, app.Keywords...) {
+			t := search.Fold(term)
+			if wordPrefix(t, q) {
+				best = max(best, search.MatchScore(q, t))
+			}
+		}
+		if best > 0 {
+			out = append(out, search.Match{Score: best / 2, Index: i})
+		}
+	}
+	return out
+}
+
+// wordPrefix reports whether any word of s, or s as a whole, starts with q.
+func wordPrefix(s, q string) bool {
+	if strings.HasPrefix(s, q) {
+		return true
+	}
+	for _, w := range strings.Fields(s) {
+		if strings.HasPrefix(w, q) {
+			return true
+		}
+	}
+	return false
+}
+
+// appSubtitle describes app under its name: its desktop entry's comment or
+// generic name when it has one, otherwise "Application" for shortcuts and
+// the executable's path for the rest.
+func appSubtitle(app apps.AppEntry) string {
+	switch {
+	case app.Comment != "":
+		return app.Comment
+	case app.GenericName != "":
+		return app.GenericName
+	case app.IsLnk:
+		return "Application"
+	default:
+		return prettifyPath(app.Path)
+	}
+}
+
+func (p appProvider) find(id string) (apps.AppEntry, bool) {
+	if p.a.scanner == nil {
+		return apps.AppEntry{}, false
+	}
+	for _, app := range p.a.scanner.Apps() {
+		if app.Name == id {
+			return app, true
+		}
+	}
+	return apps.AppEntry{}, false
+}
+
+func (p appProvider) Owns(id string) bool {
+	_, ok := p.find(id)
+	return ok
+}
+
+func (p appProvider) Execute(id, action string) string {
+	a := p.a
+	target, ok := p.find(id)
+	if !ok {
+		return "not found"
+	}
+
+	switch action {
+	case "":
+		a.usage.Record(id)
+		if err := apps.Launch(target); err != nil {
+			return err.Error()
+		}
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	case "open":
+		a.usage.Record(id)
+		if err := apps.Launch(target); err != nil {
+			return err.Error()
+		}
+		runtime.WindowHide(a.ctx)
+		return "ok"
+	case "admin":
+		a.usage.Record(id)
+		if err := runAsAdmin(target.Path); err != nil {
+			return err.Error()
+		}
+		runtime.WindowHide(a.ctx)
+		return "ok"
+	case "explorer":
+		explorerSelect(target.Path)
+		return "ok"
+	case "copy-path":
+		runtime.ClipboardSetText(a.ctx, target.Path)
+		return "ok"
+	case "pin":
+		if a.TogglePinned(id) {
+			return "pinned"
+		}
+		return "unpinned"
+	}
+	if actionID, ok := strings.CutPrefix(action, desktopActionPrefix); ok {
+		a.usage.Record(id)
+		if err := apps.LaunchAction(target, actionID); err != nil {
+			return err.Error()
+		}
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	}
+	return "unknown action"
+}
+
+func (p appProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "app"
+	r.PrimaryActionLabel = "Open"
+	r.SecondaryActionLabel = "Run as admin"
+	r.SupportsActions = true
+	return r
+}
+
+func (p appProvider) Actions(id string) []ContextAction {
+	pinLabel := "Pin to Top"
+	pinIcon := icon("\uE718", "📌")
+	for _, pinned := range p.a.config.PinnedItems {
+		if pinned == id {
+			pinLabel = "Unpin from Top"
+			pinIcon = icon("\uE77A", "📌")
+			break
+		}
+	}
+	actions := []ContextAction{
+		{ID: "open", Label: "Open", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+	}
+	// The app's own launch variants ("New Private Window") follow Open.
+	if app, ok := p.find(id); ok {
+		for _, da := range app.Actions {
+			actions = append(actions, ContextAction{ID: desktopActionPrefix + da.ID, Label: da.Name, Icon: icon("\uE8A7", "↗")})
+		}
+	}
+	return append(actions,
+		ContextAction{ID: "admin", Label: elevateLabel(), Icon: icon("\uE7EF", "🛡️"), Shortcut: "⌃↵"},
+		ContextAction{ID: "explorer", Label: revealLabel(), Icon: icon("\uE8B7", "📂")},
+		ContextAction{ID: "copy-path", Label: "Copy Path", Icon: icon("\uE8C8", "📋")},
+		ContextAction{ID: "pin", Label: pinLabel, Icon: pinIcon},
+	)
+}
+
+// desktopActionPrefix marks the context action IDs that start an app through
+// one of its desktop actions (see apps.DesktopAction).
+const desktopActionPrefix = "desktop-action:"
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAppProvider_DesktopActions(t *testing.T) {
+	a := newFixtureApp(t)
+	var ids []string
+	for _, action := range a.GetContextActions("Firefox") {
+		ids = append(ids, action.ID)
+	}
+	want := []string{"open", "desktop-action:new-window", "desktop-action:new-private-window", "admin", "explorer", "copy-path", "pin"}
+	if !slices.Equal(ids, want) {
+		t.Errorf("Firefox actions = %q, want %q", ids, want)
+	}
+
+	ids = ids[:0]
+	for _, action := range a.GetContextActions("Slack") {
+		ids = append(ids, action.ID)
+	}
+	if want := []string{"open", "admin", "explorer", "copy-path", "pin"}; !slices.Equal(ids, want) {
+		t.Errorf("Slack actions = %q, want %q", ids, want)
+	}
+}
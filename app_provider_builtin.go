@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"blight/internal/commands"
+	"blight/internal/search"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// webProvider offers "Open URL" for URL-like queries and owns the web-search
+// fallback result that Search appends to every result list.
+type webProvider struct{ a *App }
+
+func (p webProvider) Name() string   { return "Web" }
+func (p webProvider) Prefix() string { return "" }
+
+func (p webProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	if !isURL(query) {
+		return nil
+	}
+	return []search.Scored[SearchResult]{{
+		Item:  SearchResult{ID: "url-open:" + query, Title: "Open URL", Subtitle: query, Category: "Web"},
+		Score: 9500,
+		Cat:   "Web",
+	}}
+}
+
+func (p webProvider) Owns(id string) bool {
+	return strings.HasPrefix(id, "web-search:") || strings.HasPrefix(id, "url-open:")
+}
+
+func (p webProvider) Execute(id, action string) string {
+	a := p.a
+	if action != "" {
+		return "unknown action"
+	}
+	target := strings.TrimPrefix(id, "url-open:")
+	if strings.HasPrefix(id, "web-search:") {
+		tmpl := a.config.SearchEngineURL
+		if tmpl == "" {
+			tmpl = "https://www.google.com/search?q=%s"
+		}
+		target = strings.ReplaceAll(tmpl, "%s", url.QueryEscape(strings.TrimPrefix(id, "web-search:")))
+	}
+	runtime.BrowserOpenURL(a.ctx, target)
+	runtime.WindowHide(a.ctx)
+	a.visible.Store(false)
+	return "ok"
+}
+
+func (p webProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "web"
+	r.PrimaryActionLabel = "Search"
+	return r
+}
+
+func (p webProvider) Actions(_ string) []ContextAction { return []ContextAction{} }
+
+// webSearchResult is the "search the web" fallback shown below the results.
+func webSearchResult(query string) SearchResult {
+	return SearchResult{
+		ID:       "web-search:" + query,
+		Title:    "Search the web for \"" + query + "\"",
+		Subtitle: "Opens in your default browser",
+		Category: "Web",
+	}
+}
+
+// calcProvider evaluates arithmetic queries inline.
+type calcProvider struct{ a *App }
+
+func (p calcProvider) Name() string   { return "Calculator" }
+func (p calcProvider) Prefix() string { return "" }
+
+func (p calcProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	if !commands.IsCalcQuery(query) {
+		return nil
+	}
+	calc := commands.Evaluate(query)
+	if !calc.Valid {
+		return nil
+	}
+	return []search.Scored[SearchResult]{{
+		Item:  SearchResult{ID: "calc-result:" + calc.Result, Title: calc.Result, Subtitle: calc.Expression + " — press Enter to copy", Category: "Calculator"},
+		Score: 9000,
+		Cat:   "Calculator",
+	}}
+}
+
+func (p calcProvider) Owns(id string) bool { return strings.HasPrefix(id, "calc-result:") }
+
+func (p calcProvider) Execute(id, action string) string {
+	if action != "" {
+		return "unknown action"
+	}
+	runtime.ClipboardSetText(p.a.ctx, strings.TrimPrefix(id, "calc-result:"))
+	return "copied"
+}
+
+func (p calcProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "calc"
+	r.PrimaryActionLabel = "Copy result"
+	return r
+}
+
+func (p calcProvider) Actions(_ string) []ContextAction { return []ContextAction{} }
+
+// clipboardProvider lists clipboard history when the query starts with one of
+// its keywords ("cb", "clip", "clipboard").
+type clipboardProvider struct{ a *App }
+
+func (p clipboardProvider) Name() string   { return "Clipboard" }
+func (p clipboardProvider) Prefix() string { return "" }
+
+func (p clipboardProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	queryLower := strings.ToLower(query)
+	if !strings.HasPrefix(queryLower, "cb ") && !strings.HasPrefix(queryLower, "clip ") &&
+		queryLower != "clipboard" && queryLower != "cb" && queryLower != "clip" {
+		return nil
+	}
+	limit := p.a.rankingProfile().Caps["Clipboard"]
+	var out []search.Scored[SearchResult]
+	for i, entry := range p.a.clipboard.Entries() {
+		if i >= limit {
+			break
+		}
+		preview := entry.Content
+		if len(preview) > 80 {
+			preview = preview[:80] + "…"
+		}
+		out = append(out, search.Scored[SearchResult]{
+			Item:  SearchResult{ID: fmt.Sprintf("clip-%d", i), Title: preview, Subtitle: "Clipboard — press Enter to copy", Category: "Clipboard"},
+			Score: 8000 - i*10,
+			Cat:   "Clipboard",
+		})
+	}
+	return out
+}
+
+func (p clipboardProvider) Owns(id string) bool { return strings.HasPrefix(id, "clip-") }
+
+func (p clipboardProvider) Execute(id, action string) string {
+	var idx int
+	fmt.Sscanf(strings.TrimPrefix(id, "clip-"), "%d", &idx)
+	switch action {
+	case "", "copy", "open":
+		if p.a.clipboard.CopyToClipboard(idx) {
+			return "copied"
+		}
+		return "error"
+	case "delete":
+		p.a.clipboard.Delete(idx)
+		return "ok"
+	}
+	return "unknown action"
+}
+
+func (p clipboardProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "clipboard"
+	r.PrimaryActionLabel = "Copy"
+	r.SupportsActions = true
+	return r
+}
+
+func (p clipboardProvider) Actions(_ string) []ContextAction {
+	return []ContextAction{
+		{ID: "copy", Label: "Copy", Icon: icon("\uE8C8", "📋"), Shortcut: "↵"},
+		{ID: "delete", Label: "Delete", Icon: icon("\uE74D", "🗑️"), Destructive: true},
+	}
+}
+
+// systemProvider matches platform system commands (lock, sleep, restart…).
+type systemProvider struct{ a *App }
+
+func (p systemProvider) Name() string   { return "System" }
+func (p systemProvider) Prefix() string { return "" }
+
+func (p systemProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	queryLower := strings.ToLower(query)
+	learned := p.a.usage.QueryScores(query)
+	var out []search.Scored[SearchResult]
+	for _, cmd := range commands.SystemCommands {
+		cmdName := strings.ToLower(cmd.Name)
+		matched := false
+		s := 0
+		if cmdName == queryLower {
+			matched = true
+			s = 10000
+		} else if strings.HasPrefix(cmdName, queryLower) {
+			matched = true
+			s = 5000 + len(queryLower)*10
+		} else if strings.Contains(cmdName, queryLower) {
+			matched = true
+			s = 2000 + len(queryLower)*5
+		}
+		if !matched {
+			for _, keyword := range cmd.Keywords {
+				if strings.Contains(keyword, queryLower) {
+					matched = true
+					s = 1500
+					break
+				}
+			}
+		}
+		if !matched {
+			// "restrat", "shutdwon"
+			if s = search.TypoScore(queryLower, cmd.Name); s > 0 {
+				matched = true
+			}
+		}
+		if !matched {
+			continue
+		}
+		out = append(out, search.Scored[SearchResult]{
+			Item: SearchResult{
+				ID: "sys-" + cmd.ID, Title: cmd.Name, Subtitle: cmd.Subtitle, Icon: cmd.Icon, Category: "System",
+				TitleMatches: search.Highlight(query, cmd.Name),
+			},
+			Score: s,
+			Usage: p.a.usage.Score("sys-"+cmd.ID) + learned["sys-"+cmd.ID],
+			Cat:   "System",
+		})
+	}
+	return out
+}
+
+func (p systemProvider) Owns(id string) bool { return strings.HasPrefix(id, "sys-") }
+
+func (p systemProvider) Execute(id, action string) string {
+	switch action {
+	case "":
+		p.a.usage.Record(id)
+	case "run":
+	default:
+		return "unknown action"
+	}
+	if err := commands.ExecuteSystemCommand(strings.TrimPrefix(id, "sys-")); err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
+
+func (p systemProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "system"
+	r.PrimaryActionLabel = "Run"
+	return r
+}
+
+func (p systemProvider) Actions(_ string) []ContextAction {
+	return []ContextAction{
+		{ID: "run", Label: "Run", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+	}
+}
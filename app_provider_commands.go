@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	goruntime "runtime"
+	"strings"
+
+	"blight/internal/search"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// commandProvider matches built-in and user commands by keyword in the global
+// search and owns every cmd-* result ID.
+type commandProvider struct{ a *App }
+
+func (p commandProvider) Name() string   { return "Commands" }
+func (p commandProvider) Prefix() string { return "" }
+
+func (p commandProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	a := p.a
+	qLower := strings.ToLower(query)
+	learned := a.usage.QueryScores(query)
+	var out []search.Scored[SearchResult]
+	for _, cmd := range a.allCommands() {
+		kw := strings.ToLower(cmd.Keyword)
+		kwMatch := qLower == kw || strings.HasPrefix(qLower, kw+" ")
+		kwPrefix := strings.HasPrefix(kw, qLower) && qLower != kw
+		if !kwMatch && !kwPrefix {
+			continue
+		}
+		arg := ""
+		if strings.HasPrefix(qLower, kw+" ") {
+			arg = query[len(kw)+1:]
+		}
+		var id, subtitle string
+		if cmd.RequiresArgument && arg == "" {
+			id = "cmd-needs-arg:" + cmd.ID
+			subtitle = "Type an argument"
+		} else {
+			resolved := resolveCommandTemplate(cmd, arg)
+			id = commandResultID(cmd.ActionType, resolved)
+			subtitle = resolved
+		}
+		s := 3000
+		if kwMatch {
+			s = 8000
+		}
+		if cmd.Pinned {
+			s += 3000
+		}
+		usage := 0
+		if id != "cmd-needs-arg:"+cmd.ID {
+			usage = a.usage.Score(id) + learned[id]
+		}
+		r := SearchResult{ID: id, Title: cmd.Title, Subtitle: subtitle, Icon: cmd.Icon, Category: "Commands"}
+		// Only the keyword part of the query can show up in the title.
+		r.TitleMatches = search.Highlight(strings.TrimSuffix(query, " "+arg), cmd.Title)
+		out = append(out, search.Scored[SearchResult]{Item: r, Score: s, Usage: usage, Cat: "Commands"})
+	}
+	return out
+}
+
+func (p commandProvider) Owns(id string) bool {
+	return strings.HasPrefix(id, "cmd-url:") || strings.HasPrefix(id, "cmd-copy:") ||
+		strings.HasPrefix(id, "cmd-path:") || strings.HasPrefix(id, "cmd-shell:") ||
+		strings.HasPrefix(id, "cmd-needs-arg:")
+}
+
+func (p commandProvider) Execute(id, action string) string {
+	a := p.a
+	if strings.HasPrefix(id, "cmd-needs-arg:") {
+		if action == "" {
+			return "needs-arg"
+		}
+		return "unknown action"
+	}
+
+	switch action {
+	case "", "run":
+	case "copy":
+		parts := strings.SplitN(id, ":", 2)
+		if len(parts) == 2 {
+			runtime.ClipboardSetText(a.ctx, parts[1])
+			return "copied"
+		}
+		return "unknown action"
+	default:
+		return "unknown action"
+	}
+
+	a.usage.Record(id)
+	switch {
+	case strings.HasPrefix(id, "cmd-url:"):
+		runtime.BrowserOpenURL(a.ctx, strings.TrimPrefix(id, "cmd-url:"))
+	case strings.HasPrefix(id, "cmd-copy:"):
+		runtime.ClipboardSetText(a.ctx, strings.TrimPrefix(id, "cmd-copy:"))
+		return "copied"
+	case strings.HasPrefix(id, "cmd-path:"):
+		shellOpen(strings.TrimPrefix(id, "cmd-path:"))
+	case strings.HasPrefix(id, "cmd-shell:"):
+		cmd := strings.TrimPrefix(id, "cmd-shell:")
+		var c *exec.Cmd
+		if goruntime.GOOS == "windows" {
+			c = exec.Command("cmd.exe", "/c", cmd)
+		} else {
+			c = exec.Command("sh", "-c", cmd)
+		}
+		configureSettingsCommand(c)
+		if err := c.Start(); err != nil {
+			return err.Error()
+		}
+	}
+	runtime.WindowHide(a.ctx)
+	a.visible.Store(false)
+	return "ok"
+}
+
+func (p commandProvider) Enrich(r SearchResult) SearchResult {
+	return enrichCommandResult(r)
+}
+
+// enrichCommandResult decorates command-like results (commands and aliases).
+func enrichCommandResult(r SearchResult) SearchResult {
+	r.Kind = "command"
+	r.PrimaryActionLabel = "Run"
+	if !strings.HasPrefix(r.ID, "cmd-needs-arg:") {
+		r.SecondaryActionLabel = "Copy value"
+		r.SupportsActions = true
+	}
+	return r
+}
+
+func (p commandProvider) Actions(id string) []ContextAction {
+	if strings.HasPrefix(id, "cmd-needs-arg:") {
+		return []ContextAction{}
+	}
+	return []ContextAction{
+		{ID: "run", Label: "Run", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+		{ID: "copy", Label: "Copy Value", Icon: icon("\uE8C8", "📋"), Shortcut: "⌃↵"},
+	}
+}
+
+// commandPaletteProvider answers ">" queries with the full command list. It
+// produces the same IDs as commandProvider, which owns and executes them.
+type commandPaletteProvider struct{ commandProvider }
+
+func (p commandPaletteProvider) Prefix() string     { return ">" }
+func (p commandPaletteProvider) Owns(_ string) bool { return false }
+
+// Query returns commands matching term in definition order. Empty term returns
+// all commands.
+func (p commandPaletteProvider) Query(_ context.Context, term string) []search.Scored[SearchResult] {
+	var out []search.Scored[SearchResult]
+	termLower := strings.ToLower(strings.TrimSpace(term))
+	for _, cmd := range p.a.allCommands() {
+		kw := strings.ToLower(cmd.Keyword)
+		title := strings.ToLower(cmd.Title)
+		desc := strings.ToLower(cmd.Description)
+		if termLower == "" ||
+			strings.HasPrefix(kw, termLower) ||
+			strings.Contains(title, termLower) ||
+			strings.Contains(desc, termLower) ||
+			strings.HasPrefix(termLower, kw+" ") ||
+			termLower == kw {
+			arg := ""
+			if strings.HasPrefix(termLower, kw+" ") {
+				arg = term[len(kw)+1:]
+			}
+			var id, subtitle string
+			if cmd.RequiresArgument && arg == "" {
+				id = "cmd-needs-arg:" + cmd.ID
+				subtitle = cmd.Description
+				if subtitle == "" {
+					subtitle = "Type an argument"
+				}
+			} else {
+				resolved := resolveCommandTemplate(cmd, arg)
+				id = commandResultID(cmd.ActionType, resolved)
+				subtitle = resolved
+			}
+			r := SearchResult{
+				ID:       id,
+				Title:    cmd.Title,
+				Subtitle: subtitle,
+				Icon:     cmd.Icon,
+				Category: "Commands",
+			}
+			if arg == "" {
+				r.TitleMatches = search.Highlight(term, r.Title)
+				r.SubtitleMatches = search.HighlightTerms(term, r.Subtitle)
+			}
+			out = append(out, search.Scored[SearchResult]{Item: r, Cat: "Commands"})
+		}
+	}
+	// Keep definition order through the ranking pass.
+	for i := range out {
+		out[i].Score = len(out) - i
+	}
+	return out
+}
+
+// aliasProvider matches user aliases by trigger prefix.
+type aliasProvider struct{ a *App }
+
+func (p aliasProvider) Name() string   { return "Aliases" }
+func (p aliasProvider) Prefix() string { return "" }
+
+func (p aliasProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	if len(p.a.config.Aliases) == 0 {
+		return nil
+	}
+	var out []search.Scored[SearchResult]
+	qFolded := search.Fold(strings.TrimSpace(query))
+	learned := p.a.usage.QueryScores(query)
+	for trigger, expansion := range p.a.config.Aliases {
+		tFolded := search.Fold(trigger)
+		if strings.HasPrefix(tFolded, qFolded) {
+			id := "alias:" + trigger
+			out = append(out, search.Scored[SearchResult]{
+				Item:  SearchResult{ID: id, Title: trigger, Subtitle: expansion, Category: "Aliases"},
+				Score: search.MatchScore(qFolded, tFolded),
+				Usage: learned[id],
+				Cat:   "Aliases",
+			})
+		}
+	}
+	return out
+}
+
+func (p aliasProvider) Owns(id string) bool { return strings.HasPrefix(id, "alias:") }
+
+func (p aliasProvider) Execute(id, action string) string {
+	a := p.a
+	trigger := strings.TrimPrefix(id, "alias:")
+	expansion, ok := a.config.Aliases[trigger]
+	if !ok {
+		return "not found"
+	}
+	switch action {
+	case "":
+		if strings.HasPrefix(expansion, "http://") || strings.HasPrefix(expansion, "https://") {
+			runtime.BrowserOpenURL(a.ctx, expansion)
+			runtime.WindowHide(a.ctx)
+			a.visible.Store(false)
+			return "ok"
+		}
+		runtime.ClipboardSetText(a.ctx, expansion)
+		return "copied"
+	case "open":
+		if strings.HasPrefix(expansion, "http://") || strings.HasPrefix(expansion, "https://") {
+			runtime.BrowserOpenURL(a.ctx, expansion)
+			runtime.WindowHide(a.ctx)
+			a.visible.Store(false)
+		} else {
+			runtime.ClipboardSetText(a.ctx, expansion)
+		}
+		return "ok"
+	case "copy":
+		runtime.ClipboardSetText(a.ctx, expansion)
+		return "ok"
+	case "delete-alias":
+		delete(a.config.Aliases, trigger)
+		_ = a.saveConfig()
+		return "ok"
+	}
+	return "unknown action"
+}
+
+func (p aliasProvider) Enrich(r SearchResult) SearchResult {
+	return enrichCommandResult(r)
+}
+
+func (p aliasProvider) Actions(_ string) []ContextAction {
+	return []ContextAction{
+		{ID: "open", Label: "Use", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+		{ID: "copy", Label: "Copy Expansion", Icon: icon("\uE8C8", "📋"), Shortcut: "⌃↵"},
+		{ID: "delete-alias", Label: "Delete Alias", Icon: icon("\uE74D", "🗑️"), Destructive: true},
+	}
+}
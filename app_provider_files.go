@@ -0,0 +1,504 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"blight/internal/apps"
+	"blight/internal/debug"
+	"blight/internal/files"
+	"blight/internal/search"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// fileProvider searches the file index by name, narrowed by any filters in
+// the query (see files.ParseQuery).
+type fileProvider struct{ a *App }
+
+func (p fileProvider) Name() string   { return "Files" }
+func (p fileProvider) Prefix() string { return "" }
+
+// fileSearchBudget lets the index providers finish on large indexes; results
+// that miss the initial wait window are streamed to the frontend.
+const fileSearchBudget = 2 * time.Second
+
+func (p fileProvider) Budget() time.Duration { return fileSearchBudget }
+
+func (p fileProvider) Query(ctx context.Context, query string) []search.Scored[SearchResult] {
+	a := p.a
+	text, filter, err := files.ParseQuery(query, time.Now())
+	if err != nil {
+		return []search.Scored[SearchResult]{{
+			Item: SearchResult{ID: "no-results", Title: "Invalid filter", Subtitle: err.Error(), Category: "Files"},
+			Cat:  "Files",
+		}}
+	}
+	if len(text) < 2 && filter.IsZero() {
+		return nil
+	}
+	status := a.fileIdx.Status()
+	if status.State != "ready" {
+		return nil
+	}
+	fileScores := usageByPrefix(a.usageScores(query), "file-open:")
+	fileResults := a.fileIdx.SearchFilesFiltered(ctx, text, filter, fileScores)
+	limit := min(len(fileResults), a.maxResults())
+	out := make([]search.Scored[SearchResult], 0, limit)
+	for i, f := range fileResults[:limit] {
+		r := SearchResult{ID: "file-open:" + f.Path, Title: f.Name, Subtitle: prettifyPath(f.Dir), Category: "Files", Path: f.Path}
+		r.TitleMatches, r.SubtitleMatches = entryMatches(text, r.Title, r.Subtitle)
+		out = append(out, search.Scored[SearchResult]{
+			Item: r, Score: entryRelevance(text, f, i), Usage: fileScores[f.Path], Cat: "Files",
+		})
+	}
+	return out
+}
+
+func (p fileProvider) Owns(id string) bool {
+	return strings.HasPrefix(id, "file-open:") || strings.HasPrefix(id, "file-reveal:")
+}
+
+func (p fileProvider) Execute(id, action string) string {
+	a := p.a
+	if strings.HasPrefix(id, "file-reveal:") {
+		if action != "" {
+			return "unknown action"
+		}
+		explorerSelect(strings.TrimPrefix(id, "file-reveal:"))
+		return "ok"
+	}
+
+	filePath := strings.TrimPrefix(id, "file-open:")
+	switch action {
+	case "":
+		a.usage.Record("file-open:" + filePath)
+		shellOpen(filePath)
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	case "open":
+		shellOpen(filePath)
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	case "explorer":
+		explorerSelect(filePath)
+		return "ok"
+	case "copy-path":
+		runtime.ClipboardSetText(a.ctx, filePath)
+		return "ok"
+	case "copy-name":
+		runtime.ClipboardSetText(a.ctx, filepath.Base(filePath))
+		return "ok"
+	}
+	if name, ok := strings.CutPrefix(action, openWithPrefix); ok {
+		app, found := appProvider{a}.find(name)
+		if !found {
+			return "not found"
+		}
+		a.usage.Record("file-open:" + filePath)
+		if err := apps.OpenWith(app, filePath); err != nil {
+			return err.Error()
+		}
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	}
+	return "unknown action"
+}
+
+func (p fileProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "file"
+	r.PrimaryActionLabel = "Open"
+	r.SecondaryActionLabel = "Show in Explorer"
+	r.SupportsActions = true
+	return r
+}
+
+func (p fileProvider) Actions(id string) []ContextAction {
+	if strings.HasPrefix(id, "file-reveal:") {
+		return []ContextAction{}
+	}
+	actions := []ContextAction{
+		{ID: "open", Label: "Open", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+	}
+	if openWith := p.openWith(strings.TrimPrefix(id, "file-open:")); len(openWith) > 0 {
+		actions = append(actions, ContextAction{ID: "open-with", Label: "Open With…", Icon: icon("\uE7AC", "🗂"), Children: openWith})
+	}
+	return append(actions,
+		ContextAction{ID: "explorer", Label: revealLabel(), Icon: icon("\uE8B7", "📂"), Shortcut: "⌃↵"},
+		ContextAction{ID: "copy-path", Label: "Copy Path", Icon: icon("\uE8C8", "📋")},
+		ContextAction{ID: "copy-name", Label: "Copy Name", Icon: icon("\uE70F", "📝")},
+	)
+}
+
+// openWithPrefix marks the context action IDs that open a file with another
+// app than its default; the rest of the ID is the app's name.
+const openWithPrefix = "open-with:"
+
+// openWith lists the apps that open files of path's type (see
+// apps.Handlers), as the entries of the Open With… submenu.
+func (p fileProvider) openWith(path string) []ContextAction {
+	if p.a.scanner == nil {
+		return nil
+	}
+	var out []ContextAction
+	for _, app := range apps.Handlers(p.a.scanner.Apps(), apps.MimeType(path)) {
+		out = append(out, ContextAction{ID: openWithPrefix + app.Name, Label: app.Name, Icon: icon("\uE768", "▶")})
+	}
+	return out
+}
+
+// entryMatches highlights what the query text matched in an index entry's
+// name and, for queries that name folders or hold several terms (see
+// search.PathQuery), in the path shown beneath it.
+func entryMatches(text, title, subtitle string) (titleMatches, subtitleMatches []search.Range) {
+	if search.PathQuery(text) {
+		return search.HighlightTerms(text, title), search.HighlightTerms(text, subtitle)
+	}
+	return search.Highlight(text, title), nil
+}
+
+// filterOnlyRelevance is the relevance of the best result of a query that is
+// only a filter ("ext:pdf"), which matches every result equally; the rest keep
+// the index's order below it.
+const filterOnlyRelevance = 4000
+
+// entryRelevance scores query text against the index entry at position rank
+// of the results, as the index scored it: by search.PathScore for path
+// queries, otherwise by search.MatchScore against the name.
+func entryRelevance(text string, e files.FileEntry, rank int) int {
+	q := search.Fold(strings.TrimSpace(text))
+	switch {
+	case q == "":
+		return filterOnlyRelevance - rank*50
+	case search.PathQuery(q):
+		return search.PathScore(q, search.Fold(e.Path))
+	default:
+		return search.MatchScore(q, search.Fold(e.Name))
+	}
+}
+
+// folderProvider searches the folder index by name.
+type folderProvider struct{ a *App }
+
+func (p folderProvider) Name() string   { return "Folders" }
+func (p folderProvider) Prefix() string { return "" }
+
+func (p folderProvider) Budget() time.Duration { return fileSearchBudget }
+
+func (p folderProvider) Query(ctx context.Context, query string) []search.Scored[SearchResult] {
+	a := p.a
+	if a.config.DisableFolderIndex {
+		return nil
+	}
+	// An invalid filter is reported by the file provider.
+	text, filter, err := files.ParseQuery(query, time.Now())
+	if err != nil || (len(text) < 2 && filter.IsZero()) {
+		return nil
+	}
+	status := a.fileIdx.Status()
+	if status.State != "ready" {
+		return nil
+	}
+	dirScores := usageByPrefix(a.usageScores(query), "dir-open:")
+	dirResults := a.fileIdx.SearchDirsFiltered(ctx, text, filter, dirScores)
+	limit := min(len(dirResults), max(3, a.maxResults()/2))
+	out := make([]search.Scored[SearchResult], 0, limit)
+	for i, d := range dirResults[:limit] {
+		r := SearchResult{ID: "dir-open:" + d.Path, Title: d.Name, Subtitle: prettifyPath(d.Path), Category: "Folders", Path: d.Path}
+		r.TitleMatches, r.SubtitleMatches = entryMatches(text, r.Title, r.Subtitle)
+		out = append(out, search.Scored[SearchResult]{
+			Item: r, Score: entryRelevance(text, d, i), Usage: dirScores[d.Path], Cat: "Folders",
+		})
+	}
+	return out
+}
+
+func (p folderProvider) Owns(id string) bool { return strings.HasPrefix(id, "dir-open:") }
+
+func (p folderProvider) Execute(id, action string) string {
+	a := p.a
+	dirPath := strings.TrimPrefix(id, "dir-open:")
+	switch action {
+	case "":
+		a.usage.Record("dir-open:" + dirPath)
+		shellOpen(dirPath)
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	case "open":
+		shellOpen(dirPath)
+		runtime.WindowHide(a.ctx)
+		a.visible.Store(false)
+		return "ok"
+	case "terminal":
+		openInTerminal(dirPath)
+		return "ok"
+	case "copy-path":
+		runtime.ClipboardSetText(a.ctx, dirPath)
+		return "ok"
+	}
+	return "unknown action"
+}
+
+func (p folderProvider) Enrich(r SearchResult) SearchResult {
+	r.Kind = "folder"
+	r.PrimaryActionLabel = "Open"
+	r.SecondaryActionLabel = "Open in Terminal"
+	r.SupportsActions = true
+	return r
+}
+
+func (p folderProvider) Actions(_ string) []ContextAction {
+	return []ContextAction{
+		{ID: "open", Label: "Open", Icon: icon("\uE768", "▶"), Shortcut: "↵"},
+		{ID: "terminal", Label: "Open in Terminal", Icon: icon("\uE756", "⌨"), Shortcut: "⌃↵"},
+		{ID: "copy-path", Label: "Copy Path", Icon: icon("\uE8C8", "📋")},
+	}
+}
+
+// contentProvider searches inside indexed text files ("in: quarterly report").
+// Its results are owned by the file provider, so they open the file.
+type contentProvider struct{ a *App }
+
+func (p contentProvider) Name() string   { return "Content" }
+func (p contentProvider) Prefix() string { return "" }
+
+// Claims takes "in:" on its own or followed by a space. Without the space,
+// "in:" is the folder filter of a file query ("report in:~/Documents").
+func (p contentProvider) Claims(query string) bool {
+	rest, ok := strings.CutPrefix(query, "in:")
+	return ok && (rest == "" || rest[0] == ' ')
+}
+
+func (p contentProvider) Budget() time.Duration { return fileSearchBudget }
+
+func (p contentProvider) Owns(_ string) bool { return false }
+
+func (p contentProvider) Execute(_, _ string) string { return "not found" }
+
+func (p contentProvider) Query(ctx context.Context, query string) []search.Scored[SearchResult] {
+	a := p.a
+	query = strings.TrimPrefix(query, "in:")
+	if !a.config.ContentSearch {
+		return contentHint("File content search is off", "Turn on Search File Contents in Settings → File Index")
+	}
+	query = strings.TrimSpace(query)
+	if len([]rune(query)) < 2 {
+		return contentHint("Search inside files", "Type a word or phrase from a text, source or config file")
+	}
+
+	matches := a.fileIdx.SearchContent(ctx, query, a.maxResults())
+	if len(matches) == 0 {
+		if st := a.fileIdx.ContentStatus(); !st.Ready {
+			return contentHint("Still reading file contents…", fmt.Sprintf("%d files indexed so far", st.Files))
+		}
+		return nil
+	}
+	out := make([]search.Scored[SearchResult], len(matches))
+	for i, m := range matches {
+		out[i] = search.Scored[SearchResult]{
+			Item: SearchResult{
+				ID:       "file-open:" + m.Path,
+				Title:    filepath.Base(m.Path),
+				Subtitle: fmt.Sprintf("%d: %s", m.Line, m.Text),
+				Category: "Content",
+				Path:     m.Path,
+			},
+			Score: len(matches) - i,
+			Cat:   "Content",
+		}
+	}
+	return out
+}
+
+// contentHint is a single unactionable result explaining why content search
+// has nothing to show.
+func contentHint(title, subtitle string) []search.Scored[SearchResult] {
+	return []search.Scored[SearchResult]{{
+		Item: SearchResult{ID: "no-results", Title: title, Subtitle: subtitle, Category: "Content"},
+		Cat:  "Content",
+	}}
+}
+
+// recentProvider lists the most recently modified files when the query starts
+// with "recent". The rest of the query narrows the list by name and filters
+// ("recent invoice", "recent ext:png in:~/Desktop").
+type recentProvider struct{ a *App }
+
+func (p recentProvider) Name() string   { return "Recent Files" }
+func (p recentProvider) Prefix() string { return "" }
+
+func (p recentProvider) Budget() time.Duration { return fileSearchBudget }
+
+func (p recentProvider) Query(ctx context.Context, query string) []search.Scored[SearchResult] {
+	rest, ok := strings.CutPrefix(strings.ToLower(query), "recent")
+	if !ok || (rest != "" && rest[0] != ' ') {
+		return nil
+	}
+	// An invalid filter is reported by the file provider.
+	text, filter, err := files.ParseQuery(query[len("recent"):], time.Now())
+	if err != nil {
+		return nil
+	}
+	results := p.a.recentFiles(ctx, text, filter, p.a.maxResults())
+	out := make([]search.Scored[SearchResult], len(results))
+	for i, r := range results {
+		out[i] = search.Scored[SearchResult]{Item: r, Score: 8000 - i*10, Cat: "Recent Files"}
+	}
+	return out
+}
+
+func (p recentProvider) Owns(_ string) bool { return false }
+
+func (p recentProvider) Execute(_, _ string) string { return "not found" }
+
+// recentFiles returns up to limit of the most recently modified indexed files
+// as results owned by the file provider, honouring the recent files settings.
+func (a *App) recentFiles(ctx context.Context, text string, filter files.Filter, limit int) []SearchResult {
+	if a.fileIdx == nil || a.fileIdx.Status().State != "ready" {
+		return nil
+	}
+	entries, err := a.fileIdx.RecentFiles(ctx, text, filter, files.RecentOptions{
+		Roots:  a.config.RecentRoots,
+		Ignore: a.config.RecentIgnore,
+	}, limit)
+	if err != nil {
+		debug.Get().Warn("recent files unavailable", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+	now := time.Now()
+	out := make([]SearchResult, len(entries))
+	for i, e := range entries {
+		out[i] = SearchResult{
+			ID:       "file-open:" + e.Path,
+			Title:    e.Name,
+			Subtitle: relativeTime(e.ModTime, now) + " · " + prettifyPath(e.Dir),
+			Category: "Recent Files",
+			Path:     e.Path,
+		}
+	}
+	return out
+}
+
+// usageByPrefix extracts the usage scores whose ID starts with prefix, keyed
+// by the remainder of the ID (the file or folder path).
+func usageByPrefix(all map[string]int, prefix string) map[string]int {
+	out := make(map[string]int, len(all))
+	for k, v := range all {
+		if strings.HasPrefix(k, prefix) {
+			out[strings.TrimPrefix(k, prefix)] = v
+		}
+	}
+	return out
+}
+
+// pathProvider browses the filesystem directly when the query is a path
+// ("~/Documents/", "/etc/", "C:\Users\"). Its results are owned by the file
+// and folder providers.
+type pathProvider struct{ a *App }
+
+func (p pathProvider) Name() string   { return "Path" }
+func (p pathProvider) Prefix() string { return "" }
+
+func (p pathProvider) Claims(query string) bool {
+	return strings.HasPrefix(query, "~") || isAbsPath(query)
+}
+
+func (p pathProvider) Owns(_ string) bool { return false }
+
+func (p pathProvider) Execute(_, _ string) string { return "not found" }
+
+func (p pathProvider) Query(_ context.Context, query string) []search.Scored[SearchResult] {
+	results := p.a.searchPath(query)
+	out := make([]search.Scored[SearchResult], len(results))
+	for i, r := range results {
+		// Directory listing order is kept: folders first, then files.
+		out[i] = search.Scored[SearchResult]{Item: r, Score: len(results) - i, Cat: r.Category}
+	}
+	return out
+}
+
+func (a *App) searchPath(query string) []SearchResult {
+	home, _ := os.UserHomeDir()
+
+	expanded := filepath.FromSlash(query)
+	if strings.HasPrefix(expanded, "~") {
+		expanded = home + expanded[1:]
+	}
+
+	var searchDir, filter string
+	last := expanded[len(expanded)-1]
+	if last == filepath.Separator || last == '/' {
+		searchDir = filepath.Clean(expanded)
+		filter = ""
+	} else {
+		searchDir = filepath.Dir(expanded)
+		filter = filepath.Base(expanded)
+		if searchDir == "." {
+			searchDir = home
+		}
+	}
+
+	entries, err := os.ReadDir(searchDir)
+	if err != nil {
+		return []SearchResult{{
+			ID:       "no-results",
+			Title:    "Directory not found",
+			Subtitle: prettifyPath(searchDir),
+			Category: "Files",
+		}}
+	}
+
+	filterLower := strings.ToLower(filter)
+	limit := a.maxResults() * 2
+
+	var dirs, fileResults []SearchResult
+	for _, entry := range entries {
+		if len(dirs)+len(fileResults) >= limit {
+			break
+		}
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if filterLower != "" && !strings.Contains(strings.ToLower(name), filterLower) {
+			continue
+		}
+		path := filepath.Join(searchDir, name)
+		if entry.IsDir() {
+			dirs = append(dirs, SearchResult{
+				ID:       "dir-open:" + path,
+				Title:    name,
+				Subtitle: prettifyPath(path),
+				Category: "Folders",
+				Path:     path,
+			})
+		} else {
+			fileResults = append(fileResults, SearchResult{
+				ID:       "file-open:" + path,
+				Title:    name,
+				Subtitle: prettifyPath(searchDir),
+				Category: "Files",
+				Path:     path,
+			})
+		}
+	}
+
+	results := append(dirs, fileResults...)
+	if len(results) == 0 {
+		return []SearchResult{{
+			ID:       "no-results",
+			Title:    "No matches in " + prettifyPath(searchDir),
+			Subtitle: "Try a different name",
+			Category: "Files",
+		}}
+	}
+	return results
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFileProvider_OpenWith(t *testing.T) {
+	a := newFixtureApp(t)
+	// Keep the machine's mimeapps.list files out of the associations.
+	empty := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", empty)
+	t.Setenv("XDG_CONFIG_DIRS", empty)
+	t.Setenv("XDG_DATA_HOME", empty)
+	t.Setenv("XDG_DATA_DIRS", empty)
+
+	actions := a.GetContextActions("file-open:/home/ana/Documents/page.html")
+	var ids []string
+	var openWith []ContextAction
+	for _, action := range actions {
+		ids = append(ids, action.ID)
+		if action.ID == "open-with" {
+			openWith = action.Children
+		}
+	}
+	if want := []string{"open", "open-with", "explorer", "copy-path", "copy-name"}; !slices.Equal(ids, want) {
+		t.Errorf("file actions = %q, want %q", ids, want)
+	}
+	var labels []string
+	for _, child := range openWith {
+		labels = append(labels, child.Label)
+	}
+	// Firefox declares text/html; the editors follow as text/plain ones.
+	if want := []string{"Firefox", "Code", "Text Editor"}; !slices.Equal(labels, want) {
+		t.Errorf("Open With = %q, want %q", labels, want)
+	}
+
+	ids = ids[:0]
+	for _, action := range a.GetContextActions("file-open:/home/ana/Pictures/photo.png") {
+		ids = append(ids, action.ID)
+	}
+	if want := []string{"open", "explorer", "copy-path", "copy-name"}; !slices.Equal(ids, want) {
+		t.Errorf("actions without handlers = %q, want %q", ids, want)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"blight/internal/search"
+)
+
+// resultProvider is a search provider that also knows how to decorate its own
+// results for the frontend and which context actions they offer.
+type resultProvider interface {
+	search.Provider[SearchResult]
+	// Enrich sets Kind, PrimaryActionLabel, SecondaryActionLabel and
+	// SupportsActions on a result owned by this provider.
+	Enrich(r SearchResult) SearchResult
+	// Actions returns the context menu entries for a result owned by this provider.
+	Actions(id string) []ContextAction
+}
+
+// newProviderRegistry registers every built-in result source. Order matters:
+// it decides which provider claims a triggered query and which one owns an ID
+// when two providers could match, so the catch-all app provider goes last
+// among the ID owners.
+func (a *App) newProviderRegistry() *search.Registry[SearchResult] {
+	reg := search.NewRegistry[SearchResult]()
+	reg.Register(pathProvider{a})
+	reg.Register(contentProvider{a})
+	reg.Register(commandPaletteProvider{commandProvider{a}})
+	reg.Register(webProvider{a})
+	reg.Register(aliasProvider{a})
+	reg.Register(commandProvider{a})
+	reg.Register(calcProvider{a})
+	reg.Register(clipboardProvider{a})
+	reg.Register(recentProvider{a})
+	reg.Register(systemProvider{a})
+	reg.Register(folderProvider{a})
+	reg.Register(fileProvider{a})
+	reg.Register(appProvider{a})
+	return reg
+}
+
+// owner returns the provider that produced id, if any.
+func (a *App) owner(id string) (resultProvider, bool) {
+	p, ok := a.providers.Owner(id)
+	if !ok {
+		return nil, false
+	}
+	rp, ok := p.(resultProvider)
+	return rp, ok
+}
+
+// enrichResult lets the owning provider decorate r. Results without an owner
+// (e.g. the "no-results" placeholder) are returned unchanged.
+func (a *App) enrichResult(r SearchResult) SearchResult {
+	if p, ok := a.owner(r.ID); ok {
+		return p.Enrich(r)
+	}
+	return r
+}
+
+func (a *App) enrichResults(rs []SearchResult) []SearchResult {
+	for i := range rs {
+		rs[i] = a.enrichResult(rs[i])
+	}
+	return rs
+}
@@ -1,15 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/url"
-	"os"
-	"path/filepath"
-	"slices"
 	"strings"
+	"time"
 
-	"blight/internal/commands"
 	"blight/internal/debug"
+	"blight/internal/files"
 	"blight/internal/search"
 )
 
@@ -66,323 +65,117 @@ func commandResultID(actionType, resolved string) string {
 	}
 }
 
-// searchCommands returns commands matching term. Empty term returns all commands.
-func (a *App) searchCommands(term string) []SearchResult {
-	var results []SearchResult
-	termLower := strings.ToLower(strings.TrimSpace(term))
-	for _, cmd := range a.allCommands() {
-		kw := strings.ToLower(cmd.Keyword)
-		title := strings.ToLower(cmd.Title)
-		desc := strings.ToLower(cmd.Description)
-		if termLower == "" ||
-			strings.HasPrefix(kw, termLower) ||
-			strings.Contains(title, termLower) ||
-			strings.Contains(desc, termLower) ||
-			strings.HasPrefix(termLower, kw+" ") ||
-			termLower == kw {
-			arg := ""
-			if strings.HasPrefix(termLower, kw+" ") {
-				arg = term[len(kw)+1:]
-			}
-			var id, subtitle string
-			if cmd.RequiresArgument && arg == "" {
-				id = "cmd-needs-arg:" + cmd.ID
-				subtitle = cmd.Description
-				if subtitle == "" {
-					subtitle = "Type an argument"
-				}
-			} else {
-				resolved := resolveCommandTemplate(cmd, arg)
-				id = commandResultID(cmd.ActionType, resolved)
-				subtitle = resolved
-			}
-			results = append(results, SearchResult{
-				ID:       id,
-				Title:    cmd.Title,
-				Subtitle: subtitle,
-				Icon:     cmd.Icon,
-				Category: "Commands",
-			})
-		}
-	}
-	if len(results) == 0 {
-		return enrichResults([]SearchResult{{
-			ID:       "web-search:" + term,
-			Title:    "Search the web for \"" + term + "\"",
-			Subtitle: "Opens in your default browser",
-			Category: "Web",
-		}})
-	}
-	return enrichResults(results)
-}
+// searchWait is how long Search waits for providers before answering with
+// what it has. Providers that finish later (within their own budget) are
+// delivered through the searchResultsPartial event.
+const searchWait = 40 * time.Millisecond
 
-func (a *App) Search(query string) []SearchResult {
-	log := debug.Get()
-	if query == "" {
-		return a.getDefaultResults()
-	}
-
-	if strings.HasPrefix(query, ">") {
-		return a.searchCommands(strings.TrimPrefix(query, ">"))
-	}
-
-	if strings.HasPrefix(query, "~") || isAbsPath(query) {
-		return a.searchPath(query)
-	}
+// SearchPartial is the payload of the searchResultsPartial event: the full,
+// re-ranked result list for Query once a slow provider has answered.
+type SearchPartial struct {
+	Query   string         `json:"query"`
+	Results []SearchResult `json:"results"`
+}
 
-	caps := search.DefaultCaps()
-	var scored []search.Scored[SearchResult]
+// beginSearch cancels the previous in-flight query, remembers query as the one
+// on screen, and returns the context for the new one.
+func (a *App) beginSearch(query string) context.Context {
+	parent := a.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	a.searchMu.Lock()
+	if a.searchCancel != nil {
+		a.searchCancel()
+	}
+	a.searchCancel = cancel
+	a.lastQuery = query
+	a.searchMu.Unlock()
+	return ctx
+}
 
-	if isURL(query) {
-		scored = append(scored, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: "url-open:" + query, Title: "Open URL", Subtitle: query, Category: "Web"},
-			Score: 9500,
-			Cat:   "Web",
-		})
+// usageScores returns every item's usage score, boosting the items chosen
+// before after typing query.
+func (a *App) usageScores(query string) map[string]int {
+	all := a.usage.AllScores()
+	for id, s := range a.usage.QueryScores(query) {
+		all[id] += s
 	}
+	return all
+}
 
-	if len(a.config.Aliases) > 0 {
-		qLower := strings.ToLower(query)
-		for trigger, expansion := range a.config.Aliases {
-			if strings.HasPrefix(strings.ToLower(trigger), qLower) {
-				scored = append(scored, search.Scored[SearchResult]{
-					Item:  SearchResult{ID: "alias:" + trigger, Title: trigger, Subtitle: expansion, Category: "Aliases"},
-					Score: 4000,
-					Cat:   "Aliases",
-				})
-			}
-		}
+// learnSelection credits id to the query whose results it was chosen from.
+func (a *App) learnSelection(id string) {
+	a.searchMu.Lock()
+	query := a.lastQuery
+	a.searchMu.Unlock()
+	if query != "" {
+		a.usage.Learn(query, id)
 	}
+}
 
-	scored = append(scored, a.searchCommandsScored(query)...)
+func (a *App) Search(query string) []SearchResult {
+	return a.search(query, searchWait)
+}
 
-	if commands.IsCalcQuery(query) {
-		calc := commands.Evaluate(query)
-		if calc.Valid {
-			scored = append(scored, search.Scored[SearchResult]{
-				Item:  SearchResult{ID: "calc-result:" + calc.Result, Title: calc.Result, Subtitle: calc.Expression + " — press Enter to copy", Category: "Calculator"},
-				Score: 9000,
-				Cat:   "Calculator",
-			})
-		}
+// search is Search waiting up to wait for providers before answering.
+func (a *App) search(query string, wait time.Duration) []SearchResult {
+	log := debug.Get()
+	if query == "" {
+		a.beginSearch("")
+		return a.getDefaultResults()
 	}
 
-	queryLower := strings.ToLower(query)
-	if strings.HasPrefix(queryLower, "cb ") || strings.HasPrefix(queryLower, "clip ") || queryLower == "clipboard" || queryLower == "cb" || queryLower == "clip" {
-		for i, entry := range a.clipboard.Entries() {
-			if i >= caps["Clipboard"] {
-				break
-			}
-			preview := entry.Content
-			if len(preview) > 80 {
-				preview = preview[:80] + "…"
-			}
-			scored = append(scored, search.Scored[SearchResult]{
-				Item:  SearchResult{ID: fmt.Sprintf("clip-%d", i), Title: preview, Subtitle: "Clipboard — press Enter to copy", Category: "Clipboard"},
-				Score: 8000 - i*10,
-				Cat:   "Clipboard",
-			})
+	ctx := a.beginSearch(query)
+	providers, routed, exclusive := a.providers.Route(query)
+	scored := search.Fanout(ctx, providers, routed, wait, func(all []search.Scored[SearchResult]) {
+		results := a.assembleResults(query, routed, exclusive, all)
+		if ctx.Err() != nil {
+			return
 		}
-	}
-
-	scored = append(scored, a.searchSystemCommandsScored(query)...)
-	scored = append(scored, a.searchAppsScored(query)...)
-	scored = append(scored, a.searchDirsScored(query)...)
-	scored = append(scored, a.searchFilesScored(query)...)
-
-	results := search.RankAndCap(scored, caps)
-
-	if len(results) == 0 {
-		log.Debug("search no results — web fallback", map[string]interface{}{"query": query})
-		return enrichResults([]SearchResult{{
-			ID:       "web-search:" + query,
-			Title:    "Search the web for \"" + query + "\"",
-			Subtitle: "Opens in your default browser",
-			Category: "Web",
-		}})
-	}
-
-	results = append(results, SearchResult{
-		ID:       "web-search:" + query,
-		Title:    "Search the web for \"" + query + "\"",
-		Subtitle: "Opens in your default browser",
-		Category: "Web",
+		log.Debug("search late results", map[string]interface{}{"query": query, "results": len(results)})
+		a.emit("searchResultsPartial", SearchPartial{Query: query, Results: results})
 	})
 
+	results := a.assembleResults(query, routed, exclusive, scored)
 	log.Debug("search", map[string]interface{}{"query": query, "results": len(results)})
-	return enrichResults(results)
+	return results
 }
 
-func (a *App) searchCommandsScored(query string) []search.Scored[SearchResult] {
-	qLower := strings.ToLower(query)
-	var out []search.Scored[SearchResult]
-	for _, cmd := range a.allCommands() {
-		kw := strings.ToLower(cmd.Keyword)
-		kwMatch := qLower == kw || strings.HasPrefix(qLower, kw+" ")
-		kwPrefix := strings.HasPrefix(kw, qLower) && qLower != kw
-		if !kwMatch && !kwPrefix {
-			continue
-		}
-		arg := ""
-		if strings.HasPrefix(qLower, kw+" ") {
-			arg = query[len(kw)+1:]
-		}
-		var id, subtitle string
-		if cmd.RequiresArgument && arg == "" {
-			id = "cmd-needs-arg:" + cmd.ID
-			subtitle = "Type an argument"
-		} else {
-			resolved := resolveCommandTemplate(cmd, arg)
-			id = commandResultID(cmd.ActionType, resolved)
-			subtitle = resolved
-		}
-		s := 3000
-		if kwMatch {
-			s = 8000
-		}
-		if cmd.Pinned {
-			s += 3000
-		}
-		if id != "cmd-needs-arg:"+cmd.ID {
-			s += a.usage.Score(id)
-		}
-		out = append(out, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: id, Title: cmd.Title, Subtitle: subtitle, Icon: cmd.Icon, Category: "Commands"},
-			Score: s,
-			Cat:   "Commands",
-		})
+// rankingProfile returns the default ranking profile with the user's overrides
+// from the config applied.
+func (a *App) rankingProfile() search.Profile {
+	p := search.DefaultProfile()
+	if a.config.Ranking != nil {
+		p = p.With(*a.config.Ranking)
 	}
-	return out
+	return p
 }
 
-func (a *App) searchSystemCommandsScored(query string) []search.Scored[SearchResult] {
-	queryLower := strings.ToLower(query)
-	var out []search.Scored[SearchResult]
-	for _, cmd := range commands.SystemCommands {
-		cmdName := strings.ToLower(cmd.Name)
-		matched := false
-		s := 0
-		if cmdName == queryLower {
-			matched = true
-			s = 10000
-		} else if strings.HasPrefix(cmdName, queryLower) {
-			matched = true
-			s = 5000 + len(queryLower)*10
-		} else if strings.Contains(cmdName, queryLower) {
-			matched = true
-			s = 2000 + len(queryLower)*5
-		}
-		if !matched {
-			for _, keyword := range cmd.Keywords {
-				if strings.Contains(keyword, queryLower) {
-					matched = true
-					s = 1500
-					break
-				}
-			}
-		}
-		if !matched {
-			continue
-		}
-		s += a.usage.Score("sys-" + cmd.ID)
-		out = append(out, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: "sys-" + cmd.ID, Title: cmd.Name, Subtitle: cmd.Subtitle, Icon: cmd.Icon, Category: "System"},
-			Score: s,
-			Cat:   "System",
-		})
-	}
-	return out
-}
-
-func (a *App) searchAppsScored(query string) []search.Scored[SearchResult] {
-	allApps, names := a.scanner.Snapshot()
-	usageScores := make([]int, len(allApps))
-	for i, app := range allApps {
-		usageScores[i] = a.usage.Score(app.Name)
-		if slices.Contains(a.config.PinnedItems, app.Name) {
-			usageScores[i] += 100
-		}
-	}
-	matches := search.Fuzzy(query, names, usageScores)
-	limit := min(len(matches), a.maxResults())
-	out := make([]search.Scored[SearchResult], 0, limit)
-	for _, m := range matches[:limit] {
-		app := allApps[m.Index]
-		subtitle := "Application"
-		if !app.IsLnk {
-			subtitle = prettifyPath(app.Path)
-		}
-		out = append(out, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: app.Name, Title: app.Name, Subtitle: subtitle, Category: "Applications", Path: app.Path},
-			Score: m.Score,
-			Cat:   "Applications",
-		})
+// assembleResults ranks and caps scored provider output and appends the web
+// search fallback.
+func (a *App) assembleResults(query, routed string, exclusive bool, scored []search.Scored[SearchResult]) []SearchResult {
+	// A triggered provider (">" palette, path browsing) already decides how
+	// many results to show and in what order, so only the global search is
+	// weighted and capped per category.
+	profile := a.rankingProfile()
+	if exclusive {
+		profile = search.Profile{}
 	}
-	return out
-}
-
-func (a *App) searchDirsScored(query string) []search.Scored[SearchResult] {
-	if len(query) < 2 || a.config.DisableFolderIndex {
-		return nil
-	}
-	status := a.fileIdx.Status()
-	if status.State != "ready" {
-		return nil
-	}
-	allScores := a.usage.AllScores()
-	dirScores := make(map[string]int, len(allScores))
-	for k, v := range allScores {
-		if strings.HasPrefix(k, "dir-open:") {
-			dirScores[strings.TrimPrefix(k, "dir-open:")] = v
-		}
-	}
-	dirResults := a.fileIdx.SearchDirs(query, dirScores)
-	limit := min(len(dirResults), max(3, a.maxResults()/2))
-	out := make([]search.Scored[SearchResult], 0, limit)
-	for i, d := range dirResults[:limit] {
-		s := 5000 - i*50
-		if us := dirScores[d.Path]; us > 0 {
-			s += us
-		}
-		out = append(out, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: "dir-open:" + d.Path, Title: d.Name, Subtitle: prettifyPath(d.Path), Category: "Folders", Path: d.Path},
-			Score: s,
-			Cat:   "Folders",
-		})
+	for i := range scored {
+		scored[i].Name, scored[i].Key = scored[i].Item.Title, scored[i].Item.ID
 	}
-	return out
-}
+	results := search.RankAndCap(scored, profile)
 
-func (a *App) searchFilesScored(query string) []search.Scored[SearchResult] {
-	if len(query) < 2 {
-		return nil
-	}
-	status := a.fileIdx.Status()
-	if status.State != "ready" {
-		return nil
-	}
-	allScores := a.usage.AllScores()
-	fileScores := make(map[string]int, len(allScores))
-	for k, v := range allScores {
-		if strings.HasPrefix(k, "file-open:") {
-			fileScores[strings.TrimPrefix(k, "file-open:")] = v
-		}
+	if len(results) == 0 {
+		debug.Get().Debug("search no results — web fallback", map[string]interface{}{"query": query})
+		return a.enrichResults([]SearchResult{webSearchResult(routed)})
 	}
-	fileResults := a.fileIdx.SearchFiles(query, fileScores)
-	limit := min(len(fileResults), a.maxResults())
-	out := make([]search.Scored[SearchResult], 0, limit)
-	for i, f := range fileResults[:limit] {
-		s := 4000 - i*50
-		if us := fileScores[f.Path]; us > 0 {
-			s += us
-		}
-		out = append(out, search.Scored[SearchResult]{
-			Item:  SearchResult{ID: "file-open:" + f.Path, Title: f.Name, Subtitle: prettifyPath(f.Dir), Category: "Files", Path: f.Path},
-			Score: s,
-			Cat:   "Files",
-		})
+	if !exclusive {
+		results = append(results, webSearchResult(query))
 	}
-	return out
+	return a.enrichResults(results)
 }
 
 func (a *App) getDefaultResults() []SearchResult {
@@ -394,14 +187,10 @@ func (a *App) getDefaultResults() []SearchResult {
 		pinnedSet[pinnedID] = true
 		for _, app := range allApps {
 			if app.Name == pinnedID {
-				subtitle := "Application"
-				if !app.IsLnk {
-					subtitle = prettifyPath(app.Path)
-				}
 				results = append(results, SearchResult{
 					ID:       app.Name,
 					Title:    app.Name,
-					Subtitle: subtitle,
+					Subtitle: appSubtitle(app),
 					Category: "Pinned",
 					Path:     app.Path,
 				})
@@ -430,20 +219,20 @@ func (a *App) getDefaultResults() []SearchResult {
 		if a.usage.Score(app.Name) > 0 {
 			category = "Recent"
 		}
-		subtitle := "Application"
-		if !app.IsLnk {
-			subtitle = prettifyPath(app.Path)
-		}
 		results = append(results, SearchResult{
 			ID:       app.Name,
 			Title:    app.Name,
-			Subtitle: subtitle,
+			Subtitle: appSubtitle(app),
 			Category: category,
 			Path:     app.Path,
 		})
 		added++
 	}
 
+	if a.config.ShowRecentFiles {
+		results = append(results, a.recentFiles(context.Background(), "", files.Filter{}, 4)...)
+	}
+
 	if a.clipboard != nil {
 		for i, entry := range a.clipboard.Entries() {
 			if i >= 3 {
@@ -462,83 +251,5 @@ func (a *App) getDefaultResults() []SearchResult {
 		}
 	}
 
-	return enrichResults(results)
-}
-
-func (a *App) searchPath(query string) []SearchResult {
-	home, _ := os.UserHomeDir()
-
-	expanded := filepath.FromSlash(query)
-	if strings.HasPrefix(expanded, "~") {
-		expanded = home + expanded[1:]
-	}
-
-	var searchDir, filter string
-	last := expanded[len(expanded)-1]
-	if last == filepath.Separator || last == '/' {
-		searchDir = filepath.Clean(expanded)
-		filter = ""
-	} else {
-		searchDir = filepath.Dir(expanded)
-		filter = filepath.Base(expanded)
-		if searchDir == "." {
-			searchDir = home
-		}
-	}
-
-	entries, err := os.ReadDir(searchDir)
-	if err != nil {
-		return []SearchResult{{
-			ID:       "no-results",
-			Title:    "Directory not found",
-			Subtitle: prettifyPath(searchDir),
-			Category: "Files",
-		}}
-	}
-
-	filterLower := strings.ToLower(filter)
-	limit := a.maxResults() * 2
-
-	var dirs, fileResults []SearchResult
-	for _, entry := range entries {
-		if len(dirs)+len(fileResults) >= limit {
-			break
-		}
-		name := entry.Name()
-		if strings.HasPrefix(name, ".") {
-			continue
-		}
-		if filterLower != "" && !strings.Contains(strings.ToLower(name), filterLower) {
-			continue
-		}
-		path := filepath.Join(searchDir, name)
-		if entry.IsDir() {
-			dirs = append(dirs, SearchResult{
-				ID:       "dir-open:" + path,
-				Title:    name,
-				Subtitle: prettifyPath(path),
-				Category: "Folders",
-				Path:     path,
-			})
-		} else {
-			fileResults = append(fileResults, SearchResult{
-				ID:       "file-open:" + path,
-				Title:    name,
-				Subtitle: prettifyPath(searchDir),
-				Category: "Files",
-				Path:     path,
-			})
-		}
-	}
-
-	results := append(dirs, fileResults...)
-	if len(results) == 0 {
-		return []SearchResult{{
-			ID:       "no-results",
-			Title:    "No matches in " + prettifyPath(searchDir),
-			Subtitle: "Try a different name",
-			Category: "Files",
-		}}
-	}
-	return enrichResults(results)
+	return a.enrichResults(results)
 }
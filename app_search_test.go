@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blight/internal/apps"
+	"blight/internal/commands"
+	"blight/internal/files"
+	"blight/internal/search"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// fixtureCorpus is the content of testdata/search/corpus.json: everything
+// App.Search reads, in place of the installed apps, the file index, the
+// clipboard and the usage history.
+type fixtureCorpus struct {
+	Config BlightConfig `json:"config"`
+	Apps   []struct {
+		Name string `json:"name"`
+		Path string `json:"path"`
+		// Raw marks an executable rather than a launcher entry, which shows
+		// its path instead of "Application".
+		Raw         bool                 `json:"raw"`
+		GenericName string               `json:"genericName"`
+		Comment     string               `json:"comment"`
+		Keywords    []string             `json:"keywords"`
+		Actions     []apps.DesktopAction `json:"actions"`
+		MimeTypes   []string             `json:"mimeTypes"`
+	} `json:"apps"`
+	Files     []string                  `json:"files"`
+	Folders   []string                  `json:"folders"`
+	Usage     map[string]int            `json:"usage"`
+	Learned   map[string]map[string]int `json:"learned"`
+	Clipboard []string                  `json:"clipboard"`
+}
+
+// newFixtureApp returns an App whose sources are in-memory fakes filled from
+// testdata/search/corpus.json.
+func newFixtureApp(t *testing.T) *App {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "search", "corpus.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c fixtureCorpus
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatalf("corpus.json: %v", err)
+	}
+	// prettifyPath shortens paths under the home folder.
+	t.Setenv("HOME", "/home/ana")
+	t.Setenv("USERPROFILE", "/home/ana")
+
+	a := NewApp("test")
+	a.config = c.Config
+	// System commands differ per platform; leave them out so the snapshot
+	// is the same everywhere.
+	a.config.Ranking = &search.Profile{Weights: map[string]float64{"System": 0}}
+
+	catalog := &fakeCatalog{}
+	for _, app := range c.Apps {
+		entry := apps.AppEntry{
+			Name: app.Name, Path: app.Path, IsLnk: !app.Raw,
+			GenericName: app.GenericName, Comment: app.Comment, Keywords: app.Keywords, Actions: app.Actions,
+			MimeTypes: app.MimeTypes,
+		}
+		if strings.HasSuffix(app.Path, ".desktop") {
+			entry.DesktopID = path.Base(app.Path)
+		}
+		catalog.apps = append(catalog.apps, entry)
+	}
+	a.scanner = catalog
+
+	idx := &fakeIndex{}
+	for _, p := range c.Files {
+		idx.files = append(idx.files, fixtureEntry(p, false))
+	}
+	for _, p := range c.Folders {
+		idx.dirs = append(idx.dirs, fixtureEntry(p, true))
+	}
+	a.fileIdx = idx
+
+	clip := &fakeClipboard{}
+	for _, s := range c.Clipboard {
+		clip.entries = append(clip.entries, commands.ClipboardEntry{Content: s})
+	}
+	a.clipboard = clip
+
+	a.usage = &fakeUsage{scores: c.Usage, learned: c.Learned}
+	return a
+}
+
+func fixtureEntry(p string, dir bool) files.FileEntry {
+	e := files.FileEntry{Name: path.Base(p), Path: p, Dir: path.Dir(p), IsDir: dir}
+	if !dir {
+		e.Ext = strings.ToLower(path.Ext(p))
+	}
+	return e
+}
+
+// TestSearchSnapshot runs every query in testdata/search/queries.txt through
+// App.Search over the fixture corpus and compares the ranked results with
+// testdata/search/results.golden. After an intended change to matching or
+// ranking, run
+//
+//	go test . -run SearchSnapshot -update
+//
+// and review the diff of the golden file.
+func TestSearchSnapshot(t *testing.T) {
+	a := newFixtureApp(t)
+	queries, err := readQueries(filepath.Join("testdata", "search", "queries.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	for _, q := range queries {
+		fmt.Fprintf(&b, "== %s\n", q)
+		// Wait for every provider, so late results are part of the snapshot.
+		for _, r := range a.search(q, time.Minute) {
+			fmt.Fprintf(&b, "%-13s %s", r.Category, r.Title)
+			if r.Subtitle != "" {
+				fmt.Fprintf(&b, "  ·  %s", r.Subtitle)
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	got := b.String()
+
+	golden := filepath.Join("testdata", "search", "results.golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test . -run SearchSnapshot -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("search results changed; run go test . -run SearchSnapshot -update and review the diff\ngot:\n%s", got)
+	}
+}
+
+// TestSearchIsDeterministic checks that the ranked results do not depend on
+// the order the concurrent providers finish in.
+func TestSearchIsDeterministic(t *testing.T) {
+	a := newFixtureApp(t)
+	queries, err := readQueries(filepath.Join("testdata", "search", "queries.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range queries {
+		want := a.search(q, time.Minute)
+		for i := 0; i < 5; i++ {
+			if got := a.search(q, time.Minute); !reflect.DeepEqual(got, want) {
+				t.Fatalf("%q: results differ between runs:\n%v\nvs\n%v", q, got, want)
+			}
+		}
+	}
+}
+
+// readQueries reads one query per line, skipping blank lines and # comments.
+func readQueries(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out, sc.Err()
+}
+
+// fakeCatalog is a fixed list of applications.
+type fakeCatalog struct{ apps []apps.AppEntry }
+
+func (c *fakeCatalog) Scan()                 {}
+func (c *fakeCatalog) Apps() []apps.AppEntry { return c.apps }
+
+func (c *fakeCatalog) Snapshot() ([]apps.AppEntry, []string) {
+	names := make([]string, len(c.apps))
+	for i, app := range c.apps {
+		names[i] = app.Name
+	}
+	return c.apps, names
+}
+
+// fakeIndex is a ready file index over fixed entries. It scores names (or
+// whole paths, for path queries) with search.MatchScore and search.PathScore
+// like files.FileIndex, without its trigram index and fuzzy fallback. The
+// embedded interface is nil: Search calls none of its other methods.
+type fakeIndex struct {
+	fileIndex
+	files, dirs []files.FileEntry
+}
+
+func (x *fakeIndex) Status() files.IndexStatus {
+	return files.IndexStatus{State: "ready", Count: len(x.files) + len(x.dirs)}
+}
+
+func (x *fakeIndex) SetContentSearch(bool) {}
+
+func (x *fakeIndex) SearchFilesFiltered(_ context.Context, query string, f files.Filter, usageScores map[string]int) []files.FileEntry {
+	if query == "" && f.IsZero() {
+		return nil
+	}
+	return fakeSearch(x.files, query, f, usageScores, 15)
+}
+
+func (x *fakeIndex) SearchDirsFiltered(_ context.Context, query string, f files.Filter, usageScores map[string]int) []files.FileEntry {
+	if (query == "" && f.IsZero()) || !f.MatchesFolders() {
+		return nil
+	}
+	return fakeSearch(x.dirs, query, f, usageScores, 8)
+}
+
+func fakeSearch(entries []files.FileEntry, query string, f files.Filter, usageScores map[string]int, limit int) []files.FileEntry {
+	q := search.Fold(strings.TrimSpace(query))
+	type scored struct {
+		e files.FileEntry
+		s int
+	}
+	var matches []scored
+	for _, e := range entries {
+		if !f.Match(e) {
+			continue
+		}
+		var s int
+		switch {
+		case q == "":
+			s = 1
+		case search.PathQuery(q):
+			s = search.PathScore(q, search.Fold(e.Path))
+		default:
+			s = search.MatchScore(q, search.Fold(e.Name))
+		}
+		if s > 0 {
+			matches = append(matches, scored{e, s + usageScores[e.Path]*100})
+		}
+	}
+	slices.SortFunc(matches, func(a, b scored) int {
+		if c := cmp.Compare(b.s, a.s); c != 0 {
+			return c
+		}
+		return strings.Compare(a.e.Path, b.e.Path)
+	})
+	out := make([]files.FileEntry, 0, min(len(matches), limit))
+	for _, m := range matches[:min(len(matches), limit)] {
+		out = append(out, m.e)
+	}
+	return out
+}
+
+// fakeClipboard is a fixed clipboard history.
+type fakeClipboard struct{ entries []commands.ClipboardEntry }
+
+func (c *fakeClipboard) Entries() []commands.ClipboardEntry { return c.entries }
+func (c *fakeClipboard) CopyToClipboard(i int) bool         { return i >= 0 && i < len(c.entries) }
+func (c *fakeClipboard) Delete(i int)                       { c.entries = slices.Delete(c.entries, i, i+1) }
+func (c *fakeClipboard) SetMaxSize(int)                     {}
+func (c *fakeClipboard) PollClipboard()                     {}
+
+// fakeUsage serves fixed usage scores, and learned scores for exact queries
+// only (search.UsageTracker also credits their prefixes). Recording and
+// learning are ignored so every query sees the same scores.
+type fakeUsage struct {
+	scores  map[string]int
+	learned map[string]map[string]int
+}
+
+func (u *fakeUsage) Record(string)             {}
+func (u *fakeUsage) Learn(string, string)      {}
+func (u *fakeUsage) Score(id string) int       { return u.scores[id] }
+func (u *fakeUsage) Items() []search.ItemUsage { return nil }
+func (u *fakeUsage) Forget(string)             {}
+func (u *fakeUsage) Reset()                    {}
+func (u *fakeUsage) Reload()                   {}
+
+func (u *fakeUsage) AllScores() map[string]int {
+	out := make(map[string]int, len(u.scores))
+	for id, s := range u.scores {
+		out[id] = s
+	}
+	return out
+}
+
+func (u *fakeUsage) QueryScores(query string) map[string]int {
+	out := make(map[string]int)
+	for id, s := range u.learned[search.Fold(query)] {
+		out[id] = s
+	}
+	return out
+}
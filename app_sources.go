@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"blight/internal/apps"
+	"blight/internal/commands"
+	"blight/internal/files"
+	"blight/internal/search"
+)
+
+// The App reaches installed applications, the file index, clipboard history
+// and usage through these interfaces rather than the concrete types, so that
+// Search can run over in-memory fixtures in tests (see app_search_test.go).
+// Each lists exactly what the App uses.
+
+// appCatalog lists installed applications (apps.Scanner).
+type appCatalog interface {
+	Scan()
+	Apps() []apps.AppEntry
+	Snapshot() ([]apps.AppEntry, []string)
+}
+
+// fileIndex is the searchable index of files, folders and their contents
+// (files.FileIndex).
+type fileIndex interface {
+	Start()
+	Reindex()
+	CancelIndex()
+	ClearIndex()
+	Load() error
+	Watch()
+	IsStale(maxAge time.Duration) bool
+	LastIndexed() time.Time
+	Status() files.IndexStatus
+	RootStatuses() []files.RootStatus
+	SetRoots(roots []files.Root)
+	SetRules(r files.Rules) error
+	PreviewExclude(rule string) (files.RulePreview, error)
+
+	SearchFilesFiltered(ctx context.Context, query string, f files.Filter, usageScores map[string]int) []files.FileEntry
+	SearchDirsFiltered(ctx context.Context, query string, f files.Filter, usageScores map[string]int) []files.FileEntry
+	RecentFiles(ctx context.Context, text string, f files.Filter, opts files.RecentOptions, limit int) ([]files.FileEntry, error)
+
+	SetContentSearch(enabled bool)
+	ContentStatus() files.ContentStatus
+	SearchContent(ctx context.Context, query string, limit int) []files.ContentMatch
+}
+
+// clipboardHistory keeps recently copied text (commands.ClipboardHistory).
+type clipboardHistory interface {
+	Entries() []commands.ClipboardEntry
+	CopyToClipboard(index int) bool
+	Delete(index int)
+	SetMaxSize(n int)
+	PollClipboard()
+}
+
+// usageStore records which results get chosen, and after which queries
+// (search.UsageTracker).
+type usageStore interface {
+	Record(id string)
+	Score(id string) int
+	AllScores() map[string]int
+	Learn(query, id string)
+	QueryScores(query string) map[string]int
+	Items() []search.ItemUsage
+	Forget(id string)
+	Reset()
+	Reload()
+}
+
+var (
+	_ appCatalog       = (*apps.Scanner)(nil)
+	_ fileIndex        = (*files.FileIndex)(nil)
+	_ clipboardHistory = (*commands.ClipboardHistory)(nil)
+	_ usageStore       = (*search.UsageTracker)(nil)
+)
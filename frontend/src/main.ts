@@ -29,7 +29,7 @@ import {
 import { EventsOn } from '../wailsjs/runtime/runtime';
 import { main, files } from '../wailsjs/go/models';
 
-import { escapeHtml, highlightMatch } from './modules/utils';
+import { escapeHtml, highlightMatch, highlightRanges } from './modules/utils';
 import { getFallbackIcon } from './modules/icons';
 import { showConfirmModal } from './modules/modal';
 import { Toast, ToastType } from './modules/toast';
@@ -59,6 +59,10 @@ class Blight {
     private searchSeq = 0;
     private debounceTimer: ReturnType<typeof setTimeout> | null = null;
     private currentQuery = '';
+    // The query whose Search RPC is in flight, and the latest results the
+    // backend streamed for it before the RPC returned.
+    private pendingQuery: string | null = null;
+    private pendingResults: main.SearchResult[] | null = null;
     private activeFilter: string | null = null;
     private searchDelay = 120;
 
@@ -510,6 +514,23 @@ class Blight {
         EventsOn('appsReady', () => {
             if (!this.visibleQuery()) this.loadDefaultResults();
         });
+
+        // Backend streams the re-ranked list when a slow source (e.g. the file
+        // index) answers after Search has already returned. Ignore stale queries;
+        // keep those for the query still in flight until its RPC resolves.
+        EventsOn(
+            'searchResultsPartial',
+            (partial: { query: string; results: main.SearchResult[] }) => {
+                if (partial.query === this.pendingQuery) {
+                    this.pendingResults = partial.results;
+                    return;
+                }
+                if (partial.query !== this.currentQuery) return;
+                this.results = partial.results;
+                this._displayResults = [];
+                this.renderResults();
+            }
+        );
     }
 
     private visibleQuery(): string {
@@ -554,12 +575,18 @@ class Blight {
             const seq = ++this.searchSeq;
             // Safety valve: clear the spinner if the backend RPC hangs.
             const safetyOff = setTimeout(() => this.setLoading(false), 5000);
+            this.pendingQuery = query;
+            this.pendingResults = null;
             const results = await Search(query);
             clearTimeout(safetyOff);
             this.setLoading(false);
             if (seq !== this.searchSeq) return;
+            // Results streamed during the RPC are newer than its response.
+            const partial = this.pendingQuery === query ? this.pendingResults : null;
+            this.pendingQuery = null;
+            this.pendingResults = null;
             this.currentQuery = query;
-            this.results = results;
+            this.results = partial ?? results;
             this._displayResults = [];
             this.selectedIndex = 0;
             this.renderResults();
@@ -569,6 +596,8 @@ class Blight {
     async loadDefaultResults(): Promise<void> {
         const seq = ++this.searchSeq;
         this.currentQuery = '';
+        this.pendingQuery = null;
+        this.pendingResults = null;
         this.results = [];
         this._displayResults = [];
         this.selectedIndex = 0;
@@ -702,7 +731,12 @@ class Blight {
                 ? `<div class="result-icon"><img src="${iconSrc}" alt=""/></div>`
                 : `<div class="result-icon result-icon-fallback" data-icon-index="${index}">${getFallbackIcon(result.category)}</div>`;
 
-            const titleHtml = highlightMatch(result.title, this.currentQuery);
+            // Prefer the backend's ranges: it knows how the result matched
+            // (acronyms, folded accents, path segments).
+            const titleHtml = result.titleMatches
+                ? highlightRanges(result.title, result.titleMatches)
+                : highlightMatch(result.title, this.currentQuery);
+            const subtitleHtml = highlightRanges(result.subtitle, result.subtitleMatches ?? []);
 
             const freq = this.usageScores.get(result.id) ?? 0;
             const freqDot =
@@ -720,7 +754,7 @@ class Blight {
                     ${iconHtml}
                     <div class="result-text">
                         <div class="result-title">${titleHtml}</div>
-                        <div class="result-subtitle">${escapeHtml(result.subtitle)}</div>
+                        <div class="result-subtitle">${subtitleHtml}</div>
                     </div>
                     ${pinBadge}${freqDot}
                     <div class="result-badge">${escapeHtml(result.category)}</div>
@@ -950,6 +984,13 @@ class Blight {
             case 'delete-alias':
                 this.showToast('Alias deleted', title, 'info');
                 break;
+            default:
+                // An app's own desktop actions ("New Private Window") and
+                // the apps of a file's Open With… submenu.
+                if (actionId.startsWith('desktop-action:') || actionId.startsWith('open-with:')) {
+                    if (response === 'ok') this.showToast('Launched', title, 'success');
+                    else this.showToast('Failed', response, 'error');
+                }
         }
     }
 
@@ -0,0 +1,49 @@
+import { describe, it, expect } from 'vitest';
+import { formatLastUsed, recentDays, usualTime } from '../usage-stats';
+
+describe('formatLastUsed', () => {
+    const now = new Date(2024, 5, 10, 12, 0, 0);
+    const ago = (secs: number) => Math.floor(now.getTime() / 1000) - secs;
+
+    it('describes never-launched items', () => {
+        expect(formatLastUsed(0, now)).toBe('never');
+    });
+
+    it('picks the largest whole unit', () => {
+        expect(formatLastUsed(ago(30), now)).toBe('just now');
+        expect(formatLastUsed(ago(60), now)).toBe('1 minute ago');
+        expect(formatLastUsed(ago(3 * 86400 + 5), now)).toBe('3 days ago');
+        expect(formatLastUsed(ago(15 * 86400), now)).toBe('2 weeks ago');
+    });
+});
+
+describe('usualTime', () => {
+    const hoursAt = (h: number, n: number) =>
+        Array.from({ length: 24 }, (_, i) => (i === h ? n : 0));
+
+    it('needs a few launches', () => {
+        expect(usualTime(hoursAt(9, 2), [0, 1, 1, 0, 0, 0, 0])).toBe('');
+        expect(usualTime(undefined, undefined)).toBe('');
+    });
+
+    it('names the peak hour and weekdays', () => {
+        expect(usualTime(hoursAt(9, 5), [0, 1, 1, 1, 1, 1, 0])).toBe('around 9 AM, weekdays');
+    });
+
+    it('names weekends and a dominant day', () => {
+        expect(usualTime(hoursAt(20, 4), [2, 0, 0, 0, 0, 0, 2])).toBe('around 8 PM, weekends');
+        expect(usualTime(hoursAt(0, 6), [1, 0, 0, 0, 0, 4, 1])).toBe('around 12 AM, mostly Fri');
+    });
+});
+
+describe('recentDays', () => {
+    it('fills the days without launches with zeros, oldest first', () => {
+        const now = new Date(2024, 0, 2, 8, 0, 0);
+        const history = [
+            { day: '2023-12-31', count: 2 },
+            { day: '2024-01-02', count: 1 },
+            { day: '2023-11-01', count: 9 },
+        ];
+        expect(recentDays(history, 4, now)).toEqual([0, 2, 0, 1]);
+    });
+});
@@ -1,5 +1,5 @@
 import { describe, it, expect } from 'vitest';
-import { escapeHtml, highlightMatch } from '../utils';
+import { escapeHtml, highlightMatch, highlightRanges } from '../utils';
 
 describe('escapeHtml', () => {
     it('escapes ampersand', () => {
@@ -82,3 +82,33 @@ describe('highlightMatch', () => {
         expect(result).toBe('hello');
     });
 });
+
+describe('highlightRanges', () => {
+    it('wraps each range in a match-chars span', () => {
+        const ranges = [
+            { start: 0, end: 1 },
+            { start: 7, end: 8 },
+        ];
+        expect(highlightRanges('Visual Studio Code', ranges)).toBe(
+            '<span class="match-chars">V</span>isual <span class="match-chars">S</span>tudio Code'
+        );
+    });
+
+    it('counts code points, not UTF-16 units', () => {
+        expect(highlightRanges('🚀 Rocket', [{ start: 2, end: 4 }])).toBe(
+            '🚀 <span class="match-chars">Ro</span>cket'
+        );
+    });
+
+    it('escapes HTML and skips out-of-range spans', () => {
+        const ranges = [
+            { start: 1, end: 2 },
+            { start: 0, end: 9 },
+        ];
+        expect(highlightRanges('<a>', ranges)).toBe('&lt;<span class="match-chars">a</span>&gt;');
+    });
+
+    it('returns escaped text without ranges', () => {
+        expect(highlightRanges('a & b', [])).toBe('a &amp; b');
+    });
+});
@@ -9,8 +9,11 @@ export class ContextMenu {
     private onAction: ContextActionCallback;
 
     private target: string | null = null;
+    private title = '';
     private actions: main.ContextAction[] = [];
     private selectedIndex = -1;
+    private x = 0;
+    private y = 0;
 
     constructor(menuEl: HTMLElement, onAction: ContextActionCallback) {
         this.menuEl = menuEl;
@@ -34,8 +37,15 @@ export class ContextMenu {
 
         this.actions = actions;
         this.selectedIndex = fromKeyboard ? 0 : -1;
+        this.title = resultTitle;
+        this.x = x;
+        this.y = y;
         this._render(resultTitle);
+        this._place();
+    }
 
+    private _place(): void {
+        const { x, y } = this;
         this.menuEl.style.left = '0px';
         this.menuEl.style.top = '0px';
         const rect = this.menuEl.getBoundingClientRect();
@@ -48,6 +58,7 @@ export class ContextMenu {
     hide(): void {
         this.menuEl.classList.add('hidden');
         this.target = null;
+        this.title = '';
         this.selectedIndex = -1;
         this.actions = [];
     }
@@ -68,14 +79,8 @@ export class ContextMenu {
                 break;
             case 'Enter':
                 e.preventDefault();
-                if (this.selectedIndex >= 0 && this.target) {
-                    const action = this.actions[this.selectedIndex];
-                    ExecuteContextAction(this.target, action.id).then((response) => {
-                        const title =
-                            this.menuEl.querySelector('.context-menu-header')?.textContent ?? '';
-                        this.hide();
-                        this.onAction(action.id, response, title);
-                    });
+                if (this.selectedIndex >= 0) {
+                    this._activate(this.actions[this.selectedIndex], true);
                 }
                 break;
             case 'Escape':
@@ -98,7 +103,7 @@ export class ContextMenu {
                 ? `<kbd class="context-action-shortcut">${escapeHtml(shortcut)}</kbd>`
                 : '';
             html += `
-                <button class="context-action${kbClass}${destructiveClass}" data-action="${action.id}" data-idx="${idx}">
+                <button class="context-action${kbClass}${destructiveClass}" data-action="${escapeHtml(action.id)}" data-idx="${idx}">
                     <span class="context-action-icon">${action.icon}</span>
                     <span class="context-action-label">${escapeHtml(action.label)}</span>
                     ${shortcutHtml}
@@ -109,12 +114,9 @@ export class ContextMenu {
         this.menuEl.classList.remove('hidden');
 
         this.menuEl.querySelectorAll<HTMLElement>('.context-action').forEach((btn) => {
-            btn.addEventListener('click', async () => {
-                const actionId = btn.dataset['action'];
-                if (!actionId || !this.target) return;
-                const response = await ExecuteContextAction(this.target, actionId);
-                this.hide();
-                this.onAction(actionId, response, resultTitle);
+            btn.addEventListener('click', () => {
+                const action = this.actions[parseInt(btn.dataset['idx'] ?? '-1', 10)];
+                if (action) this._activate(action, false);
             });
             btn.addEventListener('mouseenter', () => {
                 this.selectedIndex = parseInt(btn.dataset['idx'] ?? '0', 10);
@@ -123,6 +125,21 @@ export class ContextMenu {
         });
     }
 
+    // Runs an action, or opens its submenu in place ("Open With…" lists apps).
+    private async _activate(action: main.ContextAction, fromKeyboard: boolean): Promise<void> {
+        if (!this.target) return;
+        if (action.children && action.children.length > 0) {
+            this.actions = action.children;
+            this.selectedIndex = fromKeyboard ? 0 : -1;
+            this._render(action.label.replace(/…$/, ''));
+            this._place();
+            return;
+        }
+        const response = await ExecuteContextAction(this.target, action.id);
+        this.hide();
+        this.onAction(action.id, response, this.title);
+    }
+
     private _updateSelection(): void {
         this.menuEl.querySelectorAll('.context-action').forEach((btn, idx) => {
             btn.classList.toggle('kb-selected', idx === this.selectedIndex);
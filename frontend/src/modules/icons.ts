@@ -7,6 +7,8 @@ const CATEGORY_ICONS: Record<string, { glyph: string; color: string }> = {
     suggested: { glyph: '\uE737', color: 'rgba(92,154,255,0.65)' }, // Apps
     folders: { glyph: '\uE8B7', color: 'rgba(255,190,60,0.80)' }, // OpenFolderHorizontal
     files: { glyph: '\uE8A5', color: 'rgba(255,255,255,0.55)' }, // Document
+    'recent files': { glyph: '\uE81C', color: 'rgba(255,255,255,0.55)' }, // History
+    content: { glyph: '\uE8A5', color: 'rgba(255,255,255,0.55)' }, // Document
     web: { glyph: '\uE774', color: 'rgba(92,154,255,0.70)' }, // Globe2
     system: { glyph: '\uE770', color: 'rgba(255,255,255,0.50)' }, // System/PC
     calculator: { glyph: '\uE8EF', color: 'rgba(92,154,255,0.70)' }, // Calculator
@@ -33,7 +35,7 @@ function getSvgFallback(category: string): string {
             <path d="M3 7c0-1.1.9-2 2-2h4l2 2h8a2 2 0 012 2v9a2 2 0 01-2 2H5a2 2 0 01-2-2V7z" fill="rgba(255,200,80,0.12)" stroke="rgba(255,200,80,0.35)" stroke-width="1.5"/>
         </svg>`;
     }
-    if (c === 'files') {
+    if (c === 'files' || c === 'recent files' || c === 'content') {
         return `<svg width="100%" height="100%" viewBox="0 0 24 24" fill="none" xmlns="http://www.w3.org/2000/svg">
             <path d="M6 2C4.9 2 4 2.9 4 4v16c0 1.1.9 2 2 2h12c1.1 0 2-.9 2-2V8l-6-6H6z" fill="rgba(255,255,255,0.08)" stroke="rgba(255,255,255,0.15)" stroke-width="1"/>
             <path d="M14 2v6h6" fill="rgba(255,255,255,0.05)" stroke="rgba(255,255,255,0.15)" stroke-width="1"/>
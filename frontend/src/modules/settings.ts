@@ -5,11 +5,13 @@ import {
     GetStartupEnabled,
     GetDataDir,
     GetInstallDir,
+    GetRankingDefaults,
     OpenFolder,
     OpenFolderPicker,
     ReindexFiles,
     ClearIndex,
     CancelIndex,
+    GetIndexStatus,
     CheckForUpdates,
     Uninstall,
     CloseSettings,
@@ -22,14 +24,29 @@ import {
     GetCommands,
     SaveCommand,
     DeleteCommand,
+    GetUsageStats,
+    ForgetUsage,
+    ResetUsage,
     ResizeToContent,
 } from '../../wailsjs/go/main/App';
 import { marked, Renderer } from 'marked';
 import { EventsOn } from '../../wailsjs/runtime/runtime';
-import { main, files } from '../../wailsjs/go/models';
+import { main, files, search } from '../../wailsjs/go/models';
 import { escapeHtml, inputEl, selectEl } from './utils';
 import { ToastType } from './toast';
 import { showConfirmModal } from './modal';
+import { formatLastUsed, recentDays, usualTime } from './usage-stats';
+
+// The categories whose ranking the Search tab lets users tune.
+const RANKING_CATEGORIES = ['Applications', 'Commands', 'System', 'Folders', 'Files', 'Clipboard'];
+
+// splitList parses a comma-separated settings field, dropping blanks.
+function splitList(value: string | undefined): string[] {
+    return (value || '')
+        .split(',')
+        .map((s) => s.trim())
+        .filter((s) => s !== '');
+}
 
 export interface SettingsDeps {
     showToast: (msg: string, detail?: string, type?: ToastType) => void;
@@ -44,7 +61,9 @@ export interface SettingsDeps {
 export class Settings {
     private panelEl: HTMLElement;
     private deps: SettingsDeps;
-    private currentIndexDirs: string[] = [];
+    private currentIndexRoots: files.Root[] = [];
+    private rootStatuses: files.RootStatus[] = [];
+    private rankingPriority: string[] | undefined;
     private lastUpdateCheck = 0;
 
     // Hotkey recorder state
@@ -120,6 +139,9 @@ export class Settings {
             const showPlaceholder = inputEl('settings-show-placeholder');
             if (showPlaceholder) showPlaceholder.checked = config.showPlaceholder !== false;
 
+            this.rankingPriority = config.ranking?.priority;
+            this._loadRanking(config.ranking);
+
             // Appearance tab
             const theme = selectEl('settings-theme');
             if (theme) theme.value = config.theme || 'dark';
@@ -140,6 +162,14 @@ export class Settings {
             // Files tab
             const includeFolders = inputEl('settings-include-folders');
             if (includeFolders) includeFolders.checked = !config.disableFolderIndex;
+            const contentSearch = inputEl('settings-content-search');
+            if (contentSearch) contentSearch.checked = !!config.contentSearch;
+            const showRecent = inputEl('settings-show-recent-files');
+            if (showRecent) showRecent.checked = !!config.showRecentFiles;
+            const recentRoots = inputEl('settings-recent-roots');
+            if (recentRoots) recentRoots.value = (config.recentRoots || []).join(', ');
+            const recentIgnore = inputEl('settings-recent-ignore');
+            if (recentIgnore) recentIgnore.value = (config.recentIgnore || []).join(', ');
 
             // Updates tab
             const versionEl = document.getElementById('settings-version');
@@ -159,14 +189,18 @@ export class Settings {
                 })
                 .catch(() => {});
 
-            this.currentIndexDirs = config.indexDirs || [];
-            this._renderIndexDirs();
+            this.currentIndexRoots = config.indexRoots || [];
+            this._renderIndexRoots();
+            this._refreshRootStatuses();
 
             // Aliases tab
             this._loadAliasesTab();
 
             // Commands tab
             this._loadCommandsTab();
+
+            // Usage tab
+            this._loadUsageTab();
         } catch (e) {
             // eslint-disable-next-line no-console
             console.error('Failed to load settings:', e);
@@ -199,6 +233,7 @@ export class Settings {
         });
         this._bindTabKeyNav();
         this._bindAliasAdd();
+        this._bindUsageReset();
         this._bindHotkeyBadge();
         this._bindCommandsTab();
 
@@ -227,8 +262,13 @@ export class Settings {
                     startOnStartup: inputEl('settings-start-on-startup')?.checked ?? false,
                     hideNotifyIcon: inputEl('settings-hide-notify-icon')?.checked ?? false,
                     disableFolderIndex: !(inputEl('settings-include-folders')?.checked ?? true),
+                    contentSearch: inputEl('settings-content-search')?.checked ?? false,
+                    showRecentFiles: inputEl('settings-show-recent-files')?.checked ?? false,
+                    recentRoots: splitList(inputEl('settings-recent-roots')?.value),
+                    recentIgnore: splitList(inputEl('settings-recent-ignore')?.value),
                     searchEngineURL: inputEl('settings-search-engine-url')?.value?.trim() || '',
-                    indexDirs: this.currentIndexDirs,
+                    ranking: this._readRanking(),
+                    indexRoots: this.currentIndexRoots,
                 };
                 try {
                     const cfgObj = main.BlightConfig.createFrom(cfg);
@@ -266,9 +306,12 @@ export class Settings {
 
         document.getElementById('settings-add-dir')?.addEventListener('click', async () => {
             const dir = await OpenFolderPicker();
-            if (dir) {
-                this.currentIndexDirs = [...this.currentIndexDirs, dir];
-                this._renderIndexDirs();
+            if (dir && !this.currentIndexRoots.some((r) => r.path === dir)) {
+                this.currentIndexRoots = [
+                    ...this.currentIndexRoots,
+                    files.Root.createFrom({ path: dir }),
+                ];
+                this._renderIndexRoots();
             }
         });
 
@@ -282,6 +325,7 @@ export class Settings {
             const indexing = status.state === 'indexing';
             if (reindexBtn) reindexBtn.disabled = indexing;
             if (cancelBtn) cancelBtn.classList.toggle('hidden', !indexing);
+            if (status.state === 'ready') this._refreshRootStatuses();
         });
 
         // Updates tab
@@ -494,32 +538,138 @@ export class Settings {
         }) as string;
     }
 
-    private _renderIndexDirs(): void {
+    private async _refreshRootStatuses(): Promise<void> {
+        try {
+            const status = await GetIndexStatus();
+            this.rootStatuses = status.roots || [];
+            this._renderIndexRoots();
+        } catch {
+            /* non-critical */
+        }
+    }
+
+    private _rootStatusText(path: string): { text: string; error: boolean } {
+        const st = this.rootStatuses.find((s) => s.path === path);
+        if (!st) return { text: 'Not indexed yet', error: false };
+        if (st.error) return { text: st.error, error: true };
+        let text = `${st.files.toLocaleString()} files, ${st.folders.toLocaleString()} folders`;
+        if (st.unreadable) text += ` · ${st.unreadable} unreadable`;
+        return { text, error: false };
+    }
+
+    private _renderIndexRoots(): void {
         const container = document.getElementById('settings-index-dirs');
         if (!container) return;
-        const dirs = this.currentIndexDirs;
-        if (dirs.length === 0) {
+        const roots = this.currentIndexRoots;
+        if (roots.length === 0) {
             container.innerHTML =
-                '<div style="font-size:11px;color:var(--text-tertiary)">No extra directories added</div>';
+                '<div style="font-size:11px;color:var(--text-tertiary)">No folders indexed</div>';
             return;
         }
-        container.innerHTML = dirs
-            .map(
-                (d, i) => `
-            <div class="settings-dir-item">
-                <span class="settings-dir-path">${escapeHtml(d)}</span>
-                <button class="settings-dir-remove" data-index="${i}">✕</button>
+        container.innerHTML = roots
+            .map((r, i) => {
+                const status = this._rootStatusText(r.path);
+                const only = r.only || '';
+                return `
+            <div class="settings-dir-item settings-dir-item--root">
+                <div class="settings-dir-header">
+                    <span class="settings-dir-path" title="${escapeHtml(r.path)}">${escapeHtml(r.path)}</span>
+                    <button class="settings-dir-remove" data-index="${i}" title="Stop indexing this folder">✕</button>
+                </div>
+                <div class="settings-dir-status${status.error ? ' error' : ''}">${escapeHtml(status.text)}</div>
+                <div class="settings-dir-options">
+                    <label title="Folder levels to index below this one; 0 means unlimited">Depth
+                        <input class="settings-input settings-input-narrow" type="number" min="0" max="64"
+                            data-index="${i}" data-opt="maxDepth" value="${r.maxDepth || 0}" />
+                    </label>
+                    <select class="settings-select" data-index="${i}" data-opt="only">
+                        <option value=""${only === '' ? ' selected' : ''}>Files &amp; folders</option>
+                        <option value="files"${only === 'files' ? ' selected' : ''}>Files only</option>
+                        <option value="folders"${only === 'folders' ? ' selected' : ''}>Folders only</option>
+                    </select>
+                    <label><input type="checkbox" data-index="${i}" data-opt="includeHidden"${r.includeHidden ? ' checked' : ''} /> Hidden</label>
+                    <label><input type="checkbox" data-index="${i}" data-opt="followSymlinks"${r.followSymlinks ? ' checked' : ''} /> Follow links</label>
+                </div>
             </div>
-        `
-            )
+        `;
+            })
             .join('');
         container.querySelectorAll<HTMLElement>('.settings-dir-remove').forEach((btn) => {
             btn.addEventListener('click', () => {
                 const idx = parseInt(btn.dataset['index'] ?? '0', 10);
-                this.currentIndexDirs = this.currentIndexDirs.filter((_, i) => i !== idx);
-                this._renderIndexDirs();
+                this.currentIndexRoots = this.currentIndexRoots.filter((_, i) => i !== idx);
+                this._renderIndexRoots();
+            });
+        });
+        container
+            .querySelectorAll<HTMLInputElement | HTMLSelectElement>('[data-opt]')
+            .forEach((el) => {
+                el.addEventListener('change', () => {
+                    const root = this.currentIndexRoots[parseInt(el.dataset['index'] ?? '0', 10)];
+                    if (!root) return;
+                    switch (el.dataset['opt']) {
+                        case 'maxDepth':
+                            root.maxDepth = Math.max(0, parseInt(el.value || '0', 10) || 0);
+                            break;
+                        case 'only':
+                            root.only = el.value;
+                            break;
+                        case 'includeHidden':
+                            root.includeHidden = (el as HTMLInputElement).checked;
+                            break;
+                        case 'followSymlinks':
+                            root.followSymlinks = (el as HTMLInputElement).checked;
+                            break;
+                    }
+                });
             });
+    }
+
+    private async _loadRanking(overrides: search.Profile | undefined): Promise<void> {
+        const list = document.getElementById('ranking-list');
+        if (!list) return;
+        let defaults: search.Profile;
+        try {
+            defaults = await GetRankingDefaults();
+        } catch {
+            return;
+        }
+        list.innerHTML = RANKING_CATEGORIES.map((cat) => {
+            const weight = overrides?.weights?.[cat];
+            const cap = overrides?.caps?.[cat];
+            return `
+            <div class="settings-row-item">
+                <div class="settings-row-info">
+                    <div class="settings-row-name">${escapeHtml(cat)}</div>
+                </div>
+                <div class="settings-row-control">
+                    <span class="settings-input-unit">weight</span>
+                    <input class="settings-input ranking-weight" data-cat="${escapeHtml(cat)}" type="number" min="0" max="10" step="0.1" placeholder="${defaults.weights?.[cat] ?? 1}" value="${weight ?? ''}" />
+                    <span class="settings-input-unit">cap</span>
+                    <input class="settings-input ranking-cap" data-cat="${escapeHtml(cat)}" type="number" min="0" max="20" placeholder="${defaults.caps?.[cat] ?? '∞'}" value="${cap ?? ''}" />
+                </div>
+            </div>
+        `;
+        }).join('');
+    }
+
+    // _readRanking collects the weights and caps filled in on the Search tab.
+    // Empty fields are left out, so those categories keep their defaults.
+    private _readRanking(): search.Profile {
+        const weights: Record<string, number> = {};
+        const caps: Record<string, number> = {};
+        document.querySelectorAll<HTMLInputElement>('#ranking-list input').forEach((el) => {
+            const cat = el.dataset['cat'] ?? '';
+            const isWeight = el.classList.contains('ranking-weight');
+            const value = isWeight ? parseFloat(el.value) : parseInt(el.value, 10);
+            if (!cat || Number.isNaN(value)) return;
+            if (isWeight) {
+                weights[cat] = value;
+            } else {
+                caps[cat] = value;
+            }
         });
+        return search.Profile.createFrom({ weights, caps, priority: this.rankingPriority });
     }
 
     private async _loadAliasesTab(): Promise<void> {
@@ -596,6 +746,85 @@ export class Settings {
         });
     }
 
+    private async _loadUsageTab(): Promise<void> {
+        try {
+            const stats = await GetUsageStats();
+            this._renderUsage(stats);
+        } catch {
+            /* non-critical */
+        }
+    }
+
+    private _renderUsage(stats: main.UsageStat[]): void {
+        const list = document.getElementById('usage-list');
+        if (!list) return;
+        if (stats.length === 0) {
+            list.innerHTML =
+                '<div style="font-size:11px;color:var(--text-tertiary);padding:8px 0">Nothing recorded yet. Launch something from blight to start.</div>';
+            return;
+        }
+        list.innerHTML = stats
+            .map((st) => {
+                const details = [
+                    st.count === 1 ? '1 launch' : `${st.count} launches`,
+                    `last ${formatLastUsed(st.lastUsed)}`,
+                    usualTime(st.hours, st.weekdays),
+                    st.queries?.length ? `typed: ${st.queries.join(', ')}` : '',
+                ].filter((d) => d !== '');
+                const days = recentDays(st.history, 30);
+                const peak = Math.max(1, ...days);
+                const spark = days
+                    .map((n) => `<span style="height:${Math.round((n / peak) * 100)}%"></span>`)
+                    .join('');
+                return `
+            <div class="usage-item">
+                <div class="usage-info">
+                    <div class="usage-title" title="${escapeHtml(st.id)}">${escapeHtml(st.title)}</div>
+                    <div class="usage-detail">${escapeHtml(details.join(' · '))}</div>
+                </div>
+                <span class="cmd-type-badge">${escapeHtml(st.category)}</span>
+                <div class="usage-spark" title="Launches over the last 30 days">${spark}</div>
+                <span class="usage-score" title="Current ranking boost">${st.score}</span>
+                <button class="alias-remove" data-id="${escapeHtml(st.id)}" title="Forget this item">✕</button>
+            </div>
+        `;
+            })
+            .join('');
+        list.querySelectorAll<HTMLElement>('.alias-remove').forEach((btn) => {
+            btn.addEventListener('click', async () => {
+                const id = btn.dataset['id'] ?? '';
+                const st = stats.find((s) => s.id === id);
+                try {
+                    await ForgetUsage(id);
+                    await this._loadUsageTab();
+                    this.deps.showToast('Usage forgotten', st?.title ?? id, 'info');
+                } catch (e) {
+                    this.deps.showToast('Forget failed', String(e), 'error');
+                }
+            });
+        });
+    }
+
+    private _bindUsageReset(): void {
+        document.getElementById('usage-reset-btn')?.addEventListener('click', () => {
+            showConfirmModal(
+                'Reset all usage?',
+                'Every result will rank as if it had never been used. This cannot be undone.',
+                'Reset',
+                true,
+                async () => {
+                    try {
+                        await ResetUsage();
+                        await this._loadUsageTab();
+                        this.deps.showToast('Usage reset', '', 'info');
+                    } catch (e) {
+                        this.deps.showToast('Reset failed', String(e), 'error');
+                    }
+                }
+            );
+        });
+    }
+
     private _bindHotkeyBadge(): void {
         document.getElementById('settings-hotkey-edit')?.addEventListener('click', () => {
             const current =
@@ -0,0 +1,73 @@
+// Formatting for the Usage tab in settings, which shows what the usage tracker
+// has recorded about each item.
+
+const WEEKDAY_NAMES = ['Sun', 'Mon', 'Tue', 'Wed', 'Thu', 'Fri', 'Sat'];
+
+/** Describes a Unix timestamp relative to now, e.g. "3 days ago". */
+export function formatLastUsed(unix: number, now: Date = new Date()): string {
+    if (!unix) return 'never';
+    const secs = Math.max(0, Math.floor(now.getTime() / 1000) - unix);
+    const units: [number, string][] = [
+        [365 * 86400, 'year'],
+        [30 * 86400, 'month'],
+        [7 * 86400, 'week'],
+        [86400, 'day'],
+        [3600, 'hour'],
+        [60, 'minute'],
+    ];
+    for (const [size, name] of units) {
+        const n = Math.floor(secs / size);
+        if (n >= 1) return `${n} ${name}${n === 1 ? '' : 's'} ago`;
+    }
+    return 'just now';
+}
+
+/**
+ * Summarizes when an item is usually launched from its hour (0-23) and weekday
+ * (Sunday = 0) histograms, e.g. "around 9 AM, weekdays". Returns an empty
+ * string when there are too few launches to tell.
+ */
+export function usualTime(hours: number[] | undefined, weekdays: number[] | undefined): string {
+    const total = (weekdays ?? []).reduce((a, b) => a + b, 0);
+    if (!hours || hours.length !== 24 || !weekdays || weekdays.length !== 7 || total < 3) {
+        return '';
+    }
+    const peak = hours.indexOf(Math.max(...hours));
+    const h12 = peak % 12 === 0 ? 12 : peak % 12;
+    const parts = [`around ${h12} ${peak < 12 ? 'AM' : 'PM'}`];
+
+    const weekend = weekdays[0] + weekdays[6];
+    if (weekend === 0) {
+        parts.push('weekdays');
+    } else if (weekend === total) {
+        parts.push('weekends');
+    } else {
+        const day = weekdays.indexOf(Math.max(...weekdays));
+        if (weekdays[day] * 2 > total) parts.push(`mostly ${WEEKDAY_NAMES[day]}`);
+    }
+    return parts.join(', ');
+}
+
+/**
+ * Spreads per-day launch counts over the last `days` days ending today,
+ * oldest first, with zeros for days without launches.
+ */
+export function recentDays(
+    history: { day: string; count: number }[] | undefined,
+    days: number,
+    now: Date = new Date()
+): number[] {
+    const counts = new Map((history ?? []).map((h) => [h.day, h.count]));
+    const out: number[] = [];
+    for (let i = days - 1; i >= 0; i--) {
+        const d = new Date(now.getFullYear(), now.getMonth(), now.getDate() - i);
+        out.push(counts.get(dayKey(d)) ?? 0);
+    }
+    return out;
+}
+
+// dayKey formats a local date the way the backend keys history ("2006-01-02").
+function dayKey(d: Date): string {
+    const pad = (n: number) => String(n).padStart(2, '0');
+    return `${d.getFullYear()}-${pad(d.getMonth() + 1)}-${pad(d.getDate())}`;
+}
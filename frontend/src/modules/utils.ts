@@ -7,6 +7,23 @@ export function escapeHtml(str: string): string {
         .replace(/"/g, '&quot;');
 }
 
+/**
+ * Wraps the given ranges of text in match-chars spans. Ranges are half-open
+ * code point offsets, as the backend sends them with each result.
+ */
+export function highlightRanges(text: string, ranges: { start: number; end: number }[]): string {
+    const chars = Array.from(text);
+    let result = '';
+    let pos = 0;
+    for (const { start, end } of ranges) {
+        if (start < pos || end > chars.length || start >= end) continue;
+        result += escapeHtml(chars.slice(pos, start).join(''));
+        result += `<span class="match-chars">${escapeHtml(chars.slice(start, end).join(''))}</span>`;
+        pos = end;
+    }
+    return result + escapeHtml(chars.slice(pos).join(''));
+}
+
 export function highlightMatch(text: string, query: string): string {
     if (!query) return escapeHtml(text);
 
@@ -2,6 +2,7 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 import {files} from '../models';
+import {search} from '../models';
 
 export function CancelIndex():Promise<void>;
 
@@ -25,6 +26,8 @@ export function ExecuteContextAction(arg1:string,arg2:string):Promise<string>;
 
 export function ExportSettings():Promise<string>;
 
+export function ForgetUsage(arg1:string):Promise<void>;
+
 export function GetAliases():Promise<Record<string, string>>;
 
 export function GetCommands():Promise<Array<main.CommandDefinition>>;
@@ -41,10 +44,14 @@ export function GetIndexStatus():Promise<files.IndexStatus>;
 
 export function GetInstallDir():Promise<string>;
 
+export function GetRankingDefaults():Promise<search.Profile>;
+
 export function GetStartupEnabled():Promise<boolean>;
 
 export function GetUsageScores():Promise<Record<string, number>>;
 
+export function GetUsageStats():Promise<Array<main.UsageStat>>;
+
 export function GetVersion():Promise<string>;
 
 export function HideWindow():Promise<void>;
@@ -65,10 +72,14 @@ export function OpenSettingsWindow():Promise<void>;
 
 export function OpenURL(arg1:string):Promise<void>;
 
+export function PreviewIndexRule(arg1:string):Promise<files.RulePreview>;
+
 export function RefreshApps():Promise<void>;
 
 export function ReindexFiles():Promise<void>;
 
+export function ResetUsage():Promise<void>;
+
 export function ResizeToContent(arg1:number):Promise<void>;
 
 export function SaveAlias(arg1:string,arg2:string):Promise<void>;
@@ -46,6 +46,10 @@ export function ExportSettings() {
   return window['go']['main']['App']['ExportSettings']();
 }
 
+export function ForgetUsage(arg1) {
+  return window['go']['main']['App']['ForgetUsage'](arg1);
+}
+
 export function GetAliases() {
   return window['go']['main']['App']['GetAliases']();
 }
@@ -78,6 +82,10 @@ export function GetInstallDir() {
   return window['go']['main']['App']['GetInstallDir']();
 }
 
+export function GetRankingDefaults() {
+  return window['go']['main']['App']['GetRankingDefaults']();
+}
+
 export function GetStartupEnabled() {
   return window['go']['main']['App']['GetStartupEnabled']();
 }
@@ -86,6 +94,10 @@ export function GetUsageScores() {
   return window['go']['main']['App']['GetUsageScores']();
 }
 
+export function GetUsageStats() {
+  return window['go']['main']['App']['GetUsageStats']();
+}
+
 export function GetVersion() {
   return window['go']['main']['App']['GetVersion']();
 }
@@ -126,6 +138,10 @@ export function OpenURL(arg1) {
   return window['go']['main']['App']['OpenURL'](arg1);
 }
 
+export function PreviewIndexRule(arg1) {
+  return window['go']['main']['App']['PreviewIndexRule'](arg1);
+}
+
 export function RefreshApps() {
   return window['go']['main']['App']['RefreshApps']();
 }
@@ -134,6 +150,10 @@ export function ReindexFiles() {
   return window['go']['main']['App']['ReindexFiles']();
 }
 
+export function ResetUsage() {
+  return window['go']['main']['App']['ResetUsage']();
+}
+
 export function ResizeToContent(arg1) {
   return window['go']['main']['App']['ResizeToContent'](arg1);
 }
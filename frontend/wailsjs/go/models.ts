@@ -1,10 +1,31 @@
 export namespace files {
 	
+	export class RootStatus {
+	    path: string;
+	    files: number;
+	    folders: number;
+	    unreadable?: number;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RootStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.files = source["files"];
+	        this.folders = source["folders"];
+	        this.unreadable = source["unreadable"];
+	        this.error = source["error"];
+	    }
+	}
 	export class IndexStatus {
 	    state: string;
 	    message: string;
 	    count: number;
 	    total: number;
+	    roots?: RootStatus[];
 	
 	    static createFrom(source: any = {}) {
 	        return new IndexStatus(source);
@@ -16,6 +37,77 @@ export namespace files {
 	        this.message = source["message"];
 	        this.count = source["count"];
 	        this.total = source["total"];
+	        this.roots = this.convertValues(source["roots"], RootStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Root {
+	    path: string;
+	    maxDepth?: number;
+	    followSymlinks?: boolean;
+	    includeHidden?: boolean;
+	    only?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Root(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.path = source["path"];
+	        this.maxDepth = source["maxDepth"];
+	        this.followSymlinks = source["followSymlinks"];
+	        this.includeHidden = source["includeHidden"];
+	        this.only = source["only"];
+	    }
+	}
+	export class RulePreview {
+	    files: number;
+	    folders: number;
+	    samples: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RulePreview(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.files = source["files"];
+	        this.folders = source["folders"];
+	        this.samples = source["samples"];
+	    }
+	}
+	export class Rules {
+	    include?: string[];
+	    exclude?: string[];
+	    ignoreFiles?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new Rules(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.include = source["include"];
+	        this.exclude = source["exclude"];
+	        this.ignoreFiles = source["ignoreFiles"];
 	    }
 	}
 
@@ -75,7 +167,14 @@ export namespace main {
 	    // Go type: time
 	    lastIndexedAt?: any;
 	    disableFolderIndex?: boolean;
+	    contentSearch?: boolean;
+	    indexRoots: files.Root[];
+	    indexRules?: files.Rules;
+	    showRecentFiles?: boolean;
+	    recentRoots?: string[];
+	    recentIgnore: string[];
 	    searchEngineURL?: string;
+	    ranking?: search.Profile;
 	    aliases?: Record<string, string>;
 	    commands?: CommandDefinition[];
 	    pinnedItems?: string[];
@@ -104,7 +203,14 @@ export namespace main {
 	        this.hideNotifyIcon = source["hideNotifyIcon"];
 	        this.lastIndexedAt = this.convertValues(source["lastIndexedAt"], null);
 	        this.disableFolderIndex = source["disableFolderIndex"];
+	        this.contentSearch = source["contentSearch"];
+	        this.indexRoots = this.convertValues(source["indexRoots"], files.Root);
+	        this.indexRules = this.convertValues(source["indexRules"], files.Rules);
+	        this.showRecentFiles = source["showRecentFiles"];
+	        this.recentRoots = source["recentRoots"];
+	        this.recentIgnore = source["recentIgnore"];
 	        this.searchEngineURL = source["searchEngineURL"];
+	        this.ranking = this.convertValues(source["ranking"], search.Profile);
 	        this.aliases = source["aliases"];
 	        this.commands = this.convertValues(source["commands"], CommandDefinition);
 	        this.pinnedItems = source["pinnedItems"];
@@ -135,6 +241,7 @@ export namespace main {
 	    icon: string;
 	    shortcut?: string;
 	    destructive?: boolean;
+	    children?: ContextAction[];
 	
 	    static createFrom(source: any = {}) {
 	        return new ContextAction(source);
@@ -147,7 +254,26 @@ export namespace main {
 	        this.icon = source["icon"];
 	        this.shortcut = source["shortcut"];
 	        this.destructive = source["destructive"];
+	        this.children = this.convertValues(source["children"], ContextAction);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class SearchResult {
 	    id: string;
@@ -160,6 +286,8 @@ export namespace main {
 	    primaryActionLabel: string;
 	    secondaryActionLabel?: string;
 	    supportsActions: boolean;
+	    titleMatches?: search.Range[];
+	    subtitleMatches?: search.Range[];
 	
 	    static createFrom(source: any = {}) {
 	        return new SearchResult(source);
@@ -177,7 +305,27 @@ export namespace main {
 	        this.primaryActionLabel = source["primaryActionLabel"];
 	        this.secondaryActionLabel = source["secondaryActionLabel"];
 	        this.supportsActions = source["supportsActions"];
+	        this.titleMatches = this.convertValues(source["titleMatches"], search.Range);
+	        this.subtitleMatches = this.convertValues(source["subtitleMatches"], search.Range);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class UpdateInfo {
 	    available: boolean;
@@ -199,6 +347,103 @@ export namespace main {
 	        this.error = source["error"];
 	    }
 	}
+	export class UsageStat {
+	    id: string;
+	    title: string;
+	    category: string;
+	    count: number;
+	    lastUsed: number;
+	    score: number;
+	    hours: number[];
+	    weekdays: number[];
+	    history: search.DayCount[];
+	    queries: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageStat(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.category = source["category"];
+	        this.count = source["count"];
+	        this.lastUsed = source["lastUsed"];
+	        this.score = source["score"];
+	        this.hours = source["hours"];
+	        this.weekdays = source["weekdays"];
+	        this.history = this.convertValues(source["history"], search.DayCount);
+	        this.queries = source["queries"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace search {
+	
+	export class DayCount {
+	    day: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DayCount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.day = source["day"];
+	        this.count = source["count"];
+	    }
+	}
+	export class Profile {
+	    weights?: Record<string, number>;
+	    caps?: Record<string, number>;
+	    priority?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Profile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.weights = source["weights"];
+	        this.caps = source["caps"];
+	        this.priority = source["priority"];
+	    }
+	}
+	export class Range {
+	    start: number;
+	    end: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Range(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
 
 }
 
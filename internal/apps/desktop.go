@@ -0,0 +1,301 @@
+package apps
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DesktopAction is an additional way to start an application declared in a
+// [Desktop Action <id>] group of its desktop entry, such as "New Private
+// Window".
+type DesktopAction struct {
+	ID   string
+	Name string
+	Icon string
+	Exec string
+}
+
+// desktopEntry holds the keys of a .desktop file's [Desktop Entry] group
+// that the launcher uses, per the freedesktop Desktop Entry Specification.
+// Localized keys hold the value for the current locale.
+type desktopEntry struct {
+	entryType   string
+	name        string
+	genericName string
+	comment     string
+	icon        string
+	exec        string
+	tryExec     string
+	path        string
+	terminal    bool
+	noDisplay   bool
+	hidden      bool
+	keywords    []string
+	categories  []string
+	mimeTypes   []string
+	onlyShowIn  []string
+	notShowIn   []string
+	actions     []DesktopAction
+}
+
+// readDesktopEntry reads the .desktop file at path, localized for the user's
+// locale (see localeNames).
+func readDesktopEntry(path string) (desktopEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return desktopEntry{}, err
+	}
+	defer f.Close()
+	return parseDesktopEntry(f, localeNames())
+}
+
+// parseDesktopEntry parses a desktop entry. Localized keys ("Name[de]") take
+// the value for the first of locales that has one, and the unlocalized value
+// otherwise. Groups other than [Desktop Entry] and the [Desktop Action]
+// groups it lists in Actions= are ignored.
+func parseDesktopEntry(r io.Reader, locales []string) (desktopEntry, error) {
+	groups, err := parseGroups(r)
+	if err != nil {
+		return desktopEntry{}, err
+	}
+
+	g := desktopGroup{keys: groups["Desktop Entry"], locales: locales}
+	e := desktopEntry{
+		entryType:   g.raw("Type"),
+		name:        g.localized("Name"),
+		genericName: g.localized("GenericName"),
+		comment:     g.localized("Comment"),
+		icon:        g.localized("Icon"),
+		exec:        g.str("Exec"),
+		tryExec:     g.str("TryExec"),
+		path:        g.str("Path"),
+		terminal:    g.bool("Terminal"),
+		noDisplay:   g.bool("NoDisplay"),
+		hidden:      g.bool("Hidden"),
+		keywords:    splitList(g.localizedRaw("Keywords")),
+		categories:  splitList(g.raw("Categories")),
+		mimeTypes:   splitList(g.raw("MimeType")),
+		onlyShowIn:  splitList(g.raw("OnlyShowIn")),
+		notShowIn:   splitList(g.raw("NotShowIn")),
+	}
+	if e.entryType == "" {
+		e.entryType = "Application"
+	}
+	for _, id := range splitList(g.raw("Actions")) {
+		keys, ok := groups["Desktop Action "+id]
+		if !ok {
+			continue
+		}
+		ag := desktopGroup{keys: keys, locales: locales}
+		a := DesktopAction{ID: id, Name: ag.localized("Name"), Icon: ag.localized("Icon"), Exec: ag.str("Exec")}
+		if a.Name != "" {
+			e.actions = append(e.actions, a)
+		}
+	}
+	return e, nil
+}
+
+// parseGroups reads a file in the desktop entry format (desktop entries,
+// mimeapps.list) into its groups of keys and raw values. Comments and keys
+// outside a group are skipped, and of duplicate keys the first counts.
+func parseGroups(r io.Reader) (map[string]map[string]string, error) {
+	groups := make(map[string]map[string]string)
+	var group map[string]string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			name := line[1 : len(line)-1]
+			if groups[name] == nil {
+				groups[name] = make(map[string]string)
+			}
+			group = groups[name]
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || group == nil {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, dup := group[key]; !dup {
+			group[key] = strings.TrimSpace(value)
+		}
+	}
+	return groups, sc.Err()
+}
+
+// shouldShow reports whether a launcher should list e on a desktop whose
+// XDG_CURRENT_DESKTOP names are desktops. It leaves out entries that are not
+// applications, are hidden or deleted (NoDisplay, Hidden), are meant for
+// other desktops (OnlyShowIn, NotShowIn), or whose TryExec program is not
+// installed.
+func (e desktopEntry) shouldShow(desktops []string) bool {
+	if e.entryType != "Application" || e.noDisplay || e.hidden {
+		return false
+	}
+	if len(e.onlyShowIn) > 0 && !containsAny(e.onlyShowIn, desktops) {
+		return false
+	}
+	if containsAny(e.notShowIn, desktops) {
+		return false
+	}
+	if e.tryExec != "" {
+		if filepath.IsAbs(e.tryExec) {
+			info, err := os.Stat(e.tryExec)
+			if err != nil || info.IsDir() || info.Mode().Perm()&0o111 == 0 {
+				return false
+			}
+		} else if _, err := exec.LookPath(e.tryExec); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func containsAny(list, names []string) bool {
+	for _, n := range names {
+		for _, l := range list {
+			if strings.EqualFold(l, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// currentDesktops returns the desktop environments named in
+// XDG_CURRENT_DESKTOP ("ubuntu:GNOME").
+func currentDesktops() []string {
+	var out []string
+	for _, d := range strings.Split(os.Getenv("XDG_CURRENT_DESKTOP"), ":") {
+		if d != "" {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// localeNames returns the locale names to look up localized keys with, most
+// specific first, from the messages locale: "de_AT.UTF-8@euro" gives
+// de_AT@euro, de_AT, de@euro and de.
+func localeNames() []string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			return expandLocale(v)
+		}
+	}
+	return nil
+}
+
+func expandLocale(locale string) []string {
+	locale, modifier, _ := strings.Cut(locale, "@")
+	locale, _, _ = strings.Cut(locale, ".")
+	lang, country, _ := strings.Cut(locale, "_")
+	if lang == "" || lang == "C" || lang == "POSIX" {
+		return nil
+	}
+	var out []string
+	if country != "" && modifier != "" {
+		out = append(out, lang+"_"+country+"@"+modifier)
+	}
+	if country != "" {
+		out = append(out, lang+"_"+country)
+	}
+	if modifier != "" {
+		out = append(out, lang+"@"+modifier)
+	}
+	return append(out, lang)
+}
+
+// desktopGroup looks up keys of one group of a desktop entry.
+type desktopGroup struct {
+	keys    map[string]string
+	locales []string
+}
+
+// raw returns the value of key as written, escapes included.
+func (g desktopGroup) raw(key string) string { return g.keys[key] }
+
+// str returns the value of a string key with its escapes resolved.
+func (g desktopGroup) str(key string) string { return unescapeValue(g.keys[key]) }
+
+// localizedRaw returns the value of key for the best matching locale, as
+// written.
+func (g desktopGroup) localizedRaw(key string) string {
+	for _, l := range g.locales {
+		if v, ok := g.keys[key+"["+l+"]"]; ok {
+			return v
+		}
+	}
+	return g.keys[key]
+}
+
+// localized is localizedRaw with escapes resolved.
+func (g desktopGroup) localized(key string) string {
+	return unescapeValue(g.localizedRaw(key))
+}
+
+func (g desktopGroup) bool(key string) bool {
+	return strings.EqualFold(g.keys[key], "true")
+}
+
+// unescapeValue resolves the escapes of a string value: \s, \n, \t, \r and
+// \\. Unknown escapes are kept as written.
+func unescapeValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitList splits a list value at the semicolons that are not escaped as
+// "\;" and resolves each element's escapes. Empty elements, such as the one
+// after the customary trailing semicolon, are dropped.
+func splitList(s string) []string {
+	var out []string
+	start := 0
+	for i := 0; i <= len(s); i++ {
+		if i < len(s) && s[i] == '\\' {
+			i++
+			continue
+		}
+		if i == len(s) || s[i] == ';' {
+			item := strings.ReplaceAll(s[start:i], `\;`, ";")
+			if item = strings.TrimSpace(unescapeValue(item)); item != "" {
+				out = append(out, item)
+			}
+			start = i + 1
+		}
+	}
+	return out
+}
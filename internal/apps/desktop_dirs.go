@@ -0,0 +1,149 @@
+package apps
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Where Flatpak and Snap export the desktop entries of the apps they install.
+// Flatpak adds its folders to XDG_DATA_DIRS, but only in sessions started
+// after it was installed.
+const (
+	flatpakSystemData = "/var/lib/flatpak/exports/share"
+	snapApplications  = "/var/lib/snapd/desktop/applications"
+)
+
+func flatpakUserData(home string) string {
+	return filepath.Join(home, ".local", "share", "flatpak", "exports", "share")
+}
+
+// dataDirs returns the XDG base directories to look for data files in, most
+// important first: $XDG_DATA_HOME (~/.local/share by default), then
+// $XDG_DATA_DIRS (/usr/local/share:/usr/share by default), then the Flatpak
+// exports if XDG_DATA_DIRS does not list them.
+func dataDirs() []string {
+	home, _ := os.UserHomeDir()
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if !filepath.IsAbs(dataHome) {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	dirs := []string{dataHome}
+	sys := os.Getenv("XDG_DATA_DIRS")
+	if sys == "" {
+		sys = "/usr/local/share:/usr/share"
+	}
+	dirs = append(dirs, filepath.SplitList(sys)...)
+	dirs = append(dirs, flatpakUserData(home), flatpakSystemData)
+
+	var out []string
+	seen := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		// The spec ignores relative paths.
+		if !filepath.IsAbs(d) {
+			continue
+		}
+		d = filepath.Clean(d)
+		if !seen[d] {
+			seen[d] = true
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// applicationDirs returns the folders holding desktop entries, in the order
+// of precedence: the applications folder of every data directory, then the
+// one Snap exports to.
+func applicationDirs() []string {
+	var dirs []string
+	for _, d := range dataDirs() {
+		dirs = append(dirs, filepath.Join(d, "applications"))
+	}
+	return append(dirs, snapApplications)
+}
+
+// desktopFileID returns the ID of the desktop file at rel, a path relative to
+// its applications folder: the path with "/" replaced by "-", so that
+// "kde4/dolphin.desktop" is "kde4-dolphin.desktop".
+func desktopFileID(rel string) string {
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
+
+// scanDesktopEntries lists the applications whose desktop entries lie in
+// dirs, including subfolders, on a desktop named by desktops.
+//
+// Desktop entries are identified by their desktop file ID, and the first
+// file found for an ID, in the order of dirs, is the one that counts. A user
+// copy in ~/.local/share/applications thus replaces the system's, and one
+// that is Hidden or NoDisplay hides the app.
+//
+// Entries with the same name but different IDs, such as a distribution's
+// and a Flatpak's Firefox, are all listed; the launcher tells apps apart by
+// name, so all but the first get their origin appended ("Firefox (Flatpak)").
+func scanDesktopEntries(dirs []string, desktops []string) []AppEntry {
+	home, _ := os.UserHomeDir()
+	seenIDs := make(map[string]bool)
+	seenNames := make(map[string]bool)
+	var results []AppEntry
+	for _, root := range dirs {
+		origin := desktopOrigin(root, home)
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), ".desktop") {
+				return nil
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			id := desktopFileID(rel)
+			if seenIDs[id] {
+				return nil
+			}
+			seenIDs[id] = true
+
+			entry, err := readDesktopEntry(path)
+			if err != nil || !entry.shouldShow(desktops) {
+				return nil
+			}
+			name := entry.name
+			if name == "" {
+				name = strings.TrimSuffix(id, ".desktop")
+			}
+			if seenNames[strings.ToLower(name)] {
+				if origin == "" {
+					name += " (" + strings.TrimSuffix(id, ".desktop") + ")"
+				} else {
+					name += " (" + origin + ")"
+				}
+			}
+			seenNames[strings.ToLower(name)] = true
+			results = append(results, AppEntry{
+				Name:        name,
+				Path:        path,
+				GenericName: entry.genericName,
+				Comment:     entry.comment,
+				Keywords:    entry.keywords,
+				Categories:  entry.categories,
+				Actions:     entry.actions,
+				MimeTypes:   entry.mimeTypes,
+				DesktopID:   id,
+			})
+			return nil
+		})
+	}
+	return results
+}
+
+// desktopOrigin names the packaging system that exports desktop entries to
+// dir, or returns "" for an ordinary applications folder.
+func desktopOrigin(dir, home string) string {
+	switch {
+	case dir == snapApplications:
+		return "Snap"
+	case strings.HasPrefix(dir, flatpakSystemData), strings.HasPrefix(dir, flatpakUserData(home)):
+		return "Flatpak"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+//go:build !windows
+
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeDesktopFile(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[Desktop Entry]\nType=Application\n"+body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataDirs(t *testing.T) {
+	t.Setenv("HOME", "/home/u")
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_DATA_DIRS", "")
+	want := []string{
+		"/home/u/.local/share", "/usr/local/share", "/usr/share",
+		"/home/u/.local/share/flatpak/exports/share", "/var/lib/flatpak/exports/share",
+	}
+	if got := dataDirs(); !slices.Equal(got, want) {
+		t.Errorf("defaults: got %q, want %q", got, want)
+	}
+
+	t.Setenv("XDG_DATA_HOME", "/data/home")
+	t.Setenv("XDG_DATA_DIRS", "/var/lib/flatpak/exports/share/:relative:/opt/share:/usr/share")
+	want = []string{
+		"/data/home", "/var/lib/flatpak/exports/share", "/opt/share", "/usr/share",
+		"/home/u/.local/share/flatpak/exports/share",
+	}
+	if got := dataDirs(); !slices.Equal(got, want) {
+		t.Errorf("from environment: got %q, want %q", got, want)
+	}
+}
+
+func TestScanDesktopEntries(t *testing.T) {
+	root := t.TempDir()
+	user := filepath.Join(root, "home", "applications")
+	system := filepath.Join(root, "usr", "applications")
+	flatpak := filepath.Join(root, "flatpak", "applications")
+
+	// The user's copies replace the system's, or hide them.
+	writeDesktopFile(t, filepath.Join(user, "org.gnome.Terminal.desktop"), "Name=My Terminal\n")
+	writeDesktopFile(t, filepath.Join(system, "org.gnome.Terminal.desktop"), "Name=Terminal\n")
+	writeDesktopFile(t, filepath.Join(user, "htop.desktop"), "Name=Htop\nHidden=true\n")
+	writeDesktopFile(t, filepath.Join(system, "htop.desktop"), "Name=Htop\n")
+	// Subfolders are part of the ID; which of two files with one ID wins
+	// within a folder is up to the walk order.
+	writeDesktopFile(t, filepath.Join(system, "kde4", "dolphin.desktop"), "Name=Dolphin\n")
+	writeDesktopFile(t, filepath.Join(system, "kde4-dolphin.desktop"), "Name=Dolphin duplicate\n")
+	// Same name, different IDs.
+	writeDesktopFile(t, filepath.Join(system, "firefox.desktop"), "Name=Firefox\n")
+	writeDesktopFile(t, filepath.Join(system, "firefox-esr.desktop"), "Name=Firefox\n")
+	writeDesktopFile(t, filepath.Join(flatpak, "org.mozilla.firefox.desktop"), "Name=Firefox\n")
+	writeDesktopFile(t, filepath.Join(system, "notes.txt"), "Name=Not an entry\n")
+
+	var got []string
+	for _, app := range scanDesktopEntries([]string{user, system, filepath.Join(root, "missing"), flatpak}, nil) {
+		got = append(got, app.DesktopID+" = "+app.Name)
+	}
+	want := []string{
+		"org.gnome.Terminal.desktop = My Terminal",
+		"firefox-esr.desktop = Firefox",
+		"firefox.desktop = Firefox (firefox)",
+		"kde4-dolphin.desktop = Dolphin",
+		"org.mozilla.firefox.desktop = Firefox (org.mozilla.firefox)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("got\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestDesktopOrigin(t *testing.T) {
+	tests := map[string]string{
+		"/var/lib/flatpak/exports/share/applications":             "Flatpak",
+		"/home/u/.local/share/flatpak/exports/share/applications": "Flatpak",
+		"/var/lib/snapd/desktop/applications":                     "Snap",
+		"/usr/share/applications":                                 "",
+	}
+	for dir, want := range tests {
+		if got := desktopOrigin(dir, "/home/u"); got != want {
+			t.Errorf("desktopOrigin(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package apps
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// execContext is what the field codes of an Exec line refer to besides the
+// files being opened.
+type execContext struct {
+	name     string // %c, the translated Name
+	icon     string // %i, expanded to "--icon <Icon>"
+	location string // %k, the desktop file's path
+}
+
+// expandExec turns the Exec line of a desktop entry (with its string escapes
+// already resolved) into the commands to run to open files, following the
+// quoting rules and field codes of the Desktop Entry Specification:
+//
+//   - Arguments are separated by spaces and may be quoted with double quotes;
+//     a backslash escapes the next character, in or out of quotes.
+//   - %f and %u stand for one file or URL, %F and %U for all of them. A
+//     command with %f or %u is run once per file when there are several.
+//   - %i is "--icon" and the entry's icon, %c its name, %k its location and
+//     %% a percent sign.
+//   - The deprecated codes (%d, %D, %n, %N, %v, %m) and unknown ones are
+//     dropped.
+//
+// Codes standing alone as an argument that expand to nothing remove the
+// argument.
+func expandExec(exec string, files []string, ctx execContext) ([][]string, error) {
+	args, err := splitExec(exec)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty Exec line")
+	}
+
+	single, multi := false, false
+	for _, a := range args {
+		single = single || strings.Contains(a, "%f") || strings.Contains(a, "%u")
+		multi = multi || strings.Contains(a, "%F") || strings.Contains(a, "%U")
+	}
+	if single && !multi && len(files) > 1 {
+		cmds := make([][]string, len(files))
+		for i, f := range files {
+			cmds[i] = expandArgs(args, []string{f}, ctx)
+		}
+		return cmds, nil
+	}
+	return [][]string{expandArgs(args, files, ctx)}, nil
+}
+
+func expandArgs(args, files []string, ctx execContext) []string {
+	first := ""
+	if len(files) > 0 {
+		first = files[0]
+	}
+	var out []string
+	for _, a := range args {
+		switch a {
+		case "%F", "%U":
+			out = append(out, files...)
+			continue
+		case "%f", "%u":
+			if first != "" {
+				out = append(out, first)
+			}
+			continue
+		case "%i":
+			if ctx.icon != "" {
+				out = append(out, "--icon", ctx.icon)
+			}
+			continue
+		case "%d", "%D", "%n", "%N", "%v", "%m":
+			continue
+		}
+		out = append(out, expandCodes(a, first, ctx))
+	}
+	return out
+}
+
+// expandCodes expands the field codes inside a longer argument
+// ("--file=%f"), where lists stand for their first file.
+func expandCodes(arg, file string, ctx execContext) string {
+	if !strings.Contains(arg, "%") {
+		return arg
+	}
+	var b strings.Builder
+	for i := 0; i < len(arg); i++ {
+		if arg[i] != '%' || i+1 == len(arg) {
+			b.WriteByte(arg[i])
+			continue
+		}
+		i++
+		switch arg[i] {
+		case '%':
+			b.WriteByte('%')
+		case 'f', 'F', 'u', 'U':
+			b.WriteString(file)
+		case 'c':
+			b.WriteString(ctx.name)
+		case 'k':
+			b.WriteString(ctx.location)
+		case 'i':
+			b.WriteString(ctx.icon)
+		}
+	}
+	return b.String()
+}
+
+// splitExec splits an Exec line into arguments. Quotes group an argument
+// and a backslash escapes the next character; "%" is kept for expandExec.
+func splitExec(exec string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	inArg, quoted := false, false
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case c == '\\' && i+1 < len(exec):
+			i++
+			cur.WriteByte(exec[i])
+			inArg = true
+		case c == '"':
+			quoted = !quoted
+			inArg = true
+		case (c == ' ' || c == '\t' || c == '\n') && !quoted:
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteByte(c)
+			inArg = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("unterminated quote in Exec line %q", exec)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,79 @@
+package apps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandExec(t *testing.T) {
+	ctx := execContext{name: "Firefox", icon: "firefox", location: "/usr/share/applications/firefox.desktop"}
+	one := []string{"/home/u/a b.html"}
+	two := []string{"/home/u/a.txt", "/home/u/b.txt"}
+	tests := []struct {
+		exec  string
+		files []string
+		want  [][]string
+	}{
+		// Plain programs, with and without files.
+		{"firefox %u", nil, [][]string{{"firefox"}}},
+		{"firefox %u", one, [][]string{{"firefox", "/home/u/a b.html"}}},
+		{"gedit %U", two, [][]string{{"gedit", "/home/u/a.txt", "/home/u/b.txt"}}},
+		{"code --unity-launch %F", nil, [][]string{{"code", "--unity-launch"}}},
+		// A single-file code runs one instance per file.
+		{"evince %f", two, [][]string{{"evince", "/home/u/a.txt"}, {"evince", "/home/u/b.txt"}}},
+		// Icon, name and location.
+		{"gimp-2.10 %U %i", nil, [][]string{{"gimp-2.10", "--icon", "firefox"}}},
+		{"app --class=%c --desktop=%k", nil, [][]string{{"app", "--class=Firefox", "--desktop=/usr/share/applications/firefox.desktop"}}},
+		// Codes inside a longer argument.
+		{"app --open=%f", one, [][]string{{"app", "--open=/home/u/a b.html"}}},
+		{"app --open=%f", nil, [][]string{{"app", "--open="}}},
+		// Deprecated codes are dropped; %% is a percent sign.
+		{"xmms %d %D %n %N %v %m %F", nil, [][]string{{"xmms"}}},
+		{"printf 100%%", nil, [][]string{{"printf", "100%"}}},
+		// Quoting.
+		{`sh -c "echo \"hi there\" \$HOME"`, nil, [][]string{{"sh", "-c", `echo "hi there" $HOME`}}},
+		{`"/opt/My App/bin/app" --flag`, nil, [][]string{{"/opt/My App/bin/app", "--flag"}}},
+		{`flatpak run --branch=stable --arch=x86_64 --command=firefox --file-forwarding org.mozilla.firefox @@u %u @@`, one,
+			[][]string{{"flatpak", "run", "--branch=stable", "--arch=x86_64", "--command=firefox", "--file-forwarding", "org.mozilla.firefox", "@@u", "/home/u/a b.html", "@@"}}},
+		{"env BAMF_DESKTOP_FILE_HINT=/var/lib/snapd/desktop/applications/spotify_spotify.desktop /snap/bin/spotify %U", nil,
+			[][]string{{"env", "BAMF_DESKTOP_FILE_HINT=/var/lib/snapd/desktop/applications/spotify_spotify.desktop", "/snap/bin/spotify"}}},
+		// Wine writes doubled backslashes; the string escape leaves one
+		// level (\\ here) for the quoting rules to resolve.
+		{`env WINEPREFIX="/home/u/.wine" wine C:\\windows\\command\\start.exe /Unix "/home/u/.wine/dosdevices/c:/users/Public/Desktop/App.lnk"`, nil,
+			[][]string{{"env", "WINEPREFIX=/home/u/.wine", "wine", `C:\windows\command\start.exe`, "/Unix", "/home/u/.wine/dosdevices/c:/users/Public/Desktop/App.lnk"}}},
+		{"  steam   steam://rungameid/570  ", nil, [][]string{{"steam", "steam://rungameid/570"}}},
+		{`app ""`, nil, [][]string{{"app", ""}}},
+	}
+	for _, tt := range tests {
+		got, err := expandExec(tt.exec, tt.files, ctx)
+		if err != nil {
+			t.Errorf("expandExec(%q): %v", tt.exec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandExec(%q, %q)\n got %q\nwant %q", tt.exec, tt.files, got, tt.want)
+		}
+	}
+}
+
+func TestExpandExec_Invalid(t *testing.T) {
+	for _, exec := range []string{"", "   ", `app "unterminated`} {
+		if got, err := expandExec(exec, nil, execContext{}); err == nil {
+			t.Errorf("expandExec(%q) = %q, want an error", exec, got)
+		}
+	}
+}
+
+func TestExpandExec_FromDesktopFile(t *testing.T) {
+	// The string escapes of the file come first: \\\\ in the file is one
+	// literal backslash in the argument.
+	e, _ := parseDesktopEntry(strings.NewReader(`[Desktop Entry]
+Exec=wine "C:\\\\Program Files\\\\app.exe" %f
+`), nil)
+	got, err := expandExec(e.exec, []string{"/tmp/x"}, execContext{})
+	want := [][]string{{"wine", `C:\Program Files\app.exe`, "/tmp/x"}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, %v; want %q", got, err, want)
+	}
+}
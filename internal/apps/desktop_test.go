@@ -0,0 +1,145 @@
+//go:build !windows
+
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const firefoxEntry = `[Desktop Entry]
+Version=1.0
+Name=Firefox
+Name[de]=Firefox-Webbrowser
+GenericName=Web Browser
+GenericName[de]=Webbrowser
+GenericName[pt_BR]=Navegador Web
+Comment=Browse the World Wide Web
+Comment[de]=Im Internet surfen
+Keywords=Internet;WWW;Browser;Web;Explorer;
+Keywords[de]=Internet;WWW;Browser;Web;
+Exec=firefox %u
+Icon=firefox
+Terminal=false
+Type=Application
+MimeType=text/html;text/xml;application/xhtml+xml;x-scheme-handler/http;
+Categories=Network;WebBrowser;
+Actions=new-window;new-private-window;
+
+[Desktop Action new-window]
+Name=Open a New Window
+Name[de]=Ein neues Fenster öffnen
+Exec=firefox -new-window
+
+[Desktop Action new-private-window]
+Name=Open a New Private Window
+Exec=firefox -private-window
+
+[Desktop Action unlisted]
+Name=Not in Actions=
+Exec=firefox -unlisted
+`
+
+func TestParseDesktopEntry(t *testing.T) {
+	e, err := parseDesktopEntry(strings.NewReader(firefoxEntry), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.name != "Firefox" || e.genericName != "Web Browser" || e.comment != "Browse the World Wide Web" {
+		t.Errorf("name, generic name, comment = %q, %q, %q", e.name, e.genericName, e.comment)
+	}
+	if want := []string{"Internet", "WWW", "Browser", "Web", "Explorer"}; !slices.Equal(e.keywords, want) {
+		t.Errorf("keywords = %q, want %q", e.keywords, want)
+	}
+	if want := []string{"Network", "WebBrowser"}; !slices.Equal(e.categories, want) {
+		t.Errorf("categories = %q, want %q", e.categories, want)
+	}
+	if len(e.mimeTypes) != 4 || e.exec != "firefox %u" || e.terminal {
+		t.Errorf("mime types %q, exec %q, terminal %v", e.mimeTypes, e.exec, e.terminal)
+	}
+	want := []DesktopAction{
+		{ID: "new-window", Name: "Open a New Window", Exec: "firefox -new-window"},
+		{ID: "new-private-window", Name: "Open a New Private Window", Exec: "firefox -private-window"},
+	}
+	if !slices.Equal(e.actions, want) {
+		t.Errorf("actions = %+v, want %+v", e.actions, want)
+	}
+}
+
+func TestParseDesktopEntry_Localized(t *testing.T) {
+	tests := []struct {
+		locale      string
+		name        string
+		genericName string
+		action      string
+	}{
+		{"de_DE.UTF-8", "Firefox-Webbrowser", "Webbrowser", "Ein neues Fenster öffnen"},
+		{"de_AT.UTF-8@euro", "Firefox-Webbrowser", "Webbrowser", "Ein neues Fenster öffnen"},
+		{"pt_BR.UTF-8", "Firefox", "Navegador Web", "Open a New Window"},
+		{"pt_PT.UTF-8", "Firefox", "Web Browser", "Open a New Window"},
+		{"C.UTF-8", "Firefox", "Web Browser", "Open a New Window"},
+	}
+	for _, tt := range tests {
+		e, _ := parseDesktopEntry(strings.NewReader(firefoxEntry), expandLocale(tt.locale))
+		if e.name != tt.name || e.genericName != tt.genericName || e.actions[0].Name != tt.action {
+			t.Errorf("%s: got %q, %q, %q; want %q, %q, %q", tt.locale,
+				e.name, e.genericName, e.actions[0].Name, tt.name, tt.genericName, tt.action)
+		}
+	}
+	if got := expandLocale("sr_RS.UTF-8@latin"); !slices.Equal(got, []string{"sr_RS@latin", "sr_RS", "sr@latin", "sr"}) {
+		t.Errorf("expandLocale = %q", got)
+	}
+}
+
+func TestParseDesktopEntry_Escapes(t *testing.T) {
+	src := "[Desktop Entry]\n" +
+		`Name = Two\sSpaces\s\s` + "\n" +
+		`Comment=Line\nbreak, tab\t, backslash \\ and \q` + "\n" +
+		`Keywords=semi\;colon;back\\;trailing\s;;` + "\n" +
+		"Name=ignored duplicate\n"
+	e, _ := parseDesktopEntry(strings.NewReader(src), nil)
+	if e.name != "Two Spaces  " {
+		t.Errorf("name = %q", e.name)
+	}
+	if want := "Line\nbreak, tab\t, backslash \\ and \\q"; e.comment != want {
+		t.Errorf("comment = %q, want %q", e.comment, want)
+	}
+	if want := []string{"semi;colon", `back\`, "trailing"}; !slices.Equal(e.keywords, want) {
+		t.Errorf("keywords = %q, want %q", e.keywords, want)
+	}
+}
+
+func TestDesktopEntry_ShouldShow(t *testing.T) {
+	dir := t.TempDir()
+	tool := filepath.Join(dir, "tool")
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		entry    string
+		desktops []string
+		want     bool
+	}{
+		{"Type=Application", nil, true},
+		{"", nil, true},
+		{"Type=Link", nil, false},
+		{"NoDisplay=true", nil, false},
+		{"Hidden=true", nil, false},
+		{"OnlyShowIn=KDE;", []string{"GNOME"}, false},
+		{"OnlyShowIn=KDE;GNOME;", []string{"ubuntu", "GNOME"}, true},
+		{"NotShowIn=GNOME;", []string{"GNOME"}, false},
+		{"NotShowIn=GNOME;", []string{"KDE"}, true},
+		{"TryExec=" + tool, nil, true},
+		{"TryExec=" + filepath.Join(dir, "missing"), nil, false},
+		{"TryExec=blight-test-no-such-program", nil, false},
+	}
+	for _, tt := range tests {
+		e, _ := parseDesktopEntry(strings.NewReader("[Desktop Entry]\nName=X\n"+tt.entry+"\n"), nil)
+		if got := e.shouldShow(tt.desktops); got != tt.want {
+			t.Errorf("%q on %v: shouldShow = %v, want %v", tt.entry, tt.desktops, got, tt.want)
+		}
+	}
+}
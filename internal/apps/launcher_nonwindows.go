@@ -3,7 +3,9 @@
 package apps
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
@@ -22,7 +24,10 @@ func Launch(app AppEntry) error {
 	case strings.HasSuffix(lower, ".app"):
 		cmd = exec.Command("open", "-a", target)
 	case strings.HasSuffix(lower, ".desktop"):
-		cmd = exec.Command("xdg-open", target)
+		if err := launchDesktopFile(target, nil); err != nil {
+			return fmt.Errorf("failed to launch %s: %w", app.Name, err)
+		}
+		return nil
 	default:
 		if filepath.IsAbs(target) {
 			cmd = exec.Command(target)
@@ -37,3 +42,124 @@ func Launch(app AppEntry) error {
 	}
 	return nil
 }
+
+// OpenWith opens file with app: through its desktop entry's Exec line, with
+// "open -a" for a macOS bundle, or as the argument of an executable.
+func OpenWith(app AppEntry, file string) error {
+	target := app.Path
+	var err error
+	switch lower := strings.ToLower(target); {
+	case strings.HasSuffix(lower, ".desktop"):
+		err = launchDesktopFile(target, []string{file})
+	case strings.HasSuffix(lower, ".app"):
+		err = startDetached([]string{"open", "-a", target, file}, "")
+	default:
+		err = startDetached([]string{target, file}, "")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s with %s: %w", filepath.Base(file), app.Name, err)
+	}
+	return nil
+}
+
+// LaunchAction starts app through the desktop action with the given ID that
+// its desktop entry declares (see AppEntry.Actions).
+func LaunchAction(app AppEntry, actionID string) error {
+	entry, err := readDesktopEntry(app.Path)
+	if err != nil {
+		return fmt.Errorf("failed to launch %s: %w", app.Name, err)
+	}
+	for _, a := range entry.actions {
+		if a.ID == actionID {
+			if err := entry.run(a.Exec, app.Path, nil); err != nil {
+				return fmt.Errorf("failed to launch %s: %w", a.Name, err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has no action %q", app.Name, actionID)
+}
+
+// launchDesktopFile runs the Exec line of the desktop entry at path to open
+// files (none to just start the app), in its Path= folder and, for
+// Terminal=true entries, in a terminal emulator.
+func launchDesktopFile(path string, files []string) error {
+	entry, err := readDesktopEntry(path)
+	if err != nil {
+		return err
+	}
+	return entry.run(entry.exec, path, files)
+}
+
+// run starts the commands for the Exec line execLine of e, whose desktop file
+// is at path. Desktop actions pass their own Exec line.
+func (e desktopEntry) run(execLine, path string, files []string) error {
+	if execLine == "" {
+		return fmt.Errorf("%s has no Exec line", filepath.Base(path))
+	}
+	cmds, err := expandExec(execLine, files, execContext{name: e.name, icon: e.icon, location: path})
+	if err != nil {
+		return err
+	}
+	for _, argv := range cmds {
+		if e.terminal {
+			if argv, err = inTerminal(argv); err != nil {
+				return err
+			}
+		}
+		if err := startDetached(argv, e.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// startDetached starts argv in dir (the current folder if empty) in a
+// session of its own, so it outlives the launcher.
+func startDetached(argv []string, dir string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	if dir != "" {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			cmd.Dir = dir
+		}
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// terminals are the terminal emulators tried for Terminal=true entries, in
+// order, with the arguments that make each run a command.
+var terminals = []struct {
+	name string
+	args []string
+}{
+	{"x-terminal-emulator", []string{"-e"}},
+	{"gnome-terminal", []string{"--"}},
+	{"konsole", []string{"-e"}},
+	{"xfce4-terminal", []string{"-x"}},
+	{"kitty", nil},
+	{"alacritty", []string{"-e"}},
+	{"foot", nil},
+	{"wezterm", []string{"start", "--"}},
+	{"xterm", []string{"-e"}},
+}
+
+// inTerminal wraps argv to run in the terminal named by $TERMINAL or else
+// the first installed one of terminals.
+func inTerminal(argv []string) ([]string, error) {
+	if t := os.Getenv("TERMINAL"); t != "" {
+		if path, err := exec.LookPath(t); err == nil {
+			return append([]string{path, "-e"}, argv...), nil
+		}
+	}
+	for _, t := range terminals {
+		if path, err := exec.LookPath(t.name); err == nil {
+			return append(append([]string{path}, t.args...), argv...), nil
+		}
+	}
+	return nil, errors.New("no terminal emulator found")
+}
@@ -35,3 +35,32 @@ func Launch(app AppEntry) error {
 
 	return nil
 }
+
+// OpenWith opens file with app, passing it as the argument of the app's
+// executable or shortcut.
+func OpenWith(app AppEntry, file string) error {
+	target := app.Path
+	if app.IsLnk {
+		target = app.LnkPath
+	}
+	var cmd *exec.Cmd
+	if strings.HasSuffix(strings.ToLower(target), ".lnk") {
+		cmd = exec.Command("cmd", "/c", "start", "", target, file)
+	} else {
+		cmd = exec.Command(target, file)
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		HideWindow:    true,
+		CreationFlags: 0x00000008, // DETACHED_PROCESS
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open %s with %s: %w", file, app.Name, err)
+	}
+	return nil
+}
+
+// LaunchAction starts app through one of its desktop actions, which only
+// Linux desktop entries declare.
+func LaunchAction(app AppEntry, actionID string) error {
+	return fmt.Errorf("%s has no action %q", app.Name, actionID)
+}
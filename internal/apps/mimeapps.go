@@ -0,0 +1,179 @@
+package apps
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// MimeType returns the MIME type of the file at path, from its extension or
+// else from its first bytes, without parameters such as the charset.
+// Folders are "inode/directory".
+func MimeType(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return "inode/directory"
+	}
+	if t := mime.TypeByExtension(filepath.Ext(path)); t != "" {
+		if mt, _, err := mime.ParseMediaType(t); err == nil {
+			return mt
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer f.Close()
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	mt, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	return mt
+}
+
+// Handlers returns the applications among all that open files of mimeType:
+// the user's default first, then those associated with it in mimeapps.list,
+// then those declaring it in their desktop entry's MimeType=. Every text
+// type is also a kind of text/plain, so text editors are offered for
+// source code and the like.
+func Handlers(all []AppEntry, mimeType string) []AppEntry {
+	return handlers(all, mimeType, readMimeApps(mimeAppsFiles()))
+}
+
+// mimeAssociations is the content of one mimeapps.list file: desktop file
+// IDs by MIME type.
+type mimeAssociations struct {
+	defaults map[string][]string
+	added    map[string][]string
+	removed  map[string][]string
+}
+
+func handlers(all []AppEntry, mimeType string, lists []mimeAssociations) []AppEntry {
+	byID := make(map[string]AppEntry, len(all))
+	for _, app := range all {
+		if app.DesktopID != "" {
+			byID[app.DesktopID] = app
+		}
+	}
+	types := []string{mimeType}
+	if strings.HasPrefix(mimeType, "text/") && mimeType != "text/plain" {
+		types = append(types, "text/plain")
+	}
+
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if _, ok := byID[id]; ok && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	def := ""
+	for _, t := range types {
+		// Each file's removals apply to the files after it and to
+		// MimeType= keys, which count least.
+		removed := make(map[string]bool)
+		for _, l := range lists {
+			for _, id := range l.defaults[t] {
+				if _, ok := byID[id]; ok && def == "" && !removed[id] {
+					def = id
+				}
+			}
+			for _, id := range l.added[t] {
+				if !removed[id] {
+					add(id)
+				}
+			}
+			for _, id := range l.removed[t] {
+				removed[id] = true
+			}
+		}
+		for _, app := range all {
+			if app.DesktopID != "" && !removed[app.DesktopID] && slices.Contains(app.MimeTypes, t) {
+				add(app.DesktopID)
+			}
+		}
+	}
+
+	var out []AppEntry
+	if def != "" {
+		out = append(out, byID[def])
+	}
+	for _, id := range ids {
+		if id != def {
+			out = append(out, byID[id])
+		}
+	}
+	return out
+}
+
+// mimeAppsFiles returns the mimeapps.list files that may exist, most
+// important first, per the MIME Applications Associations spec: in the
+// config folders ($XDG_CONFIG_HOME, then $XDG_CONFIG_DIRS), then in the
+// applications folders of the data directories, each desktop-specific list
+// ("gnome-mimeapps.list") before the general one.
+func mimeAppsFiles() []string {
+	home, _ := os.UserHomeDir()
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if !filepath.IsAbs(configHome) {
+		configHome = filepath.Join(home, ".config")
+	}
+	dirs := []string{configHome}
+	configDirs := os.Getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" {
+		configDirs = "/etc/xdg"
+	}
+	dirs = append(dirs, filepath.SplitList(configDirs)...)
+	for _, d := range dataDirs() {
+		dirs = append(dirs, filepath.Join(d, "applications"))
+	}
+
+	var names []string
+	for _, d := range currentDesktops() {
+		names = append(names, strings.ToLower(d)+"-mimeapps.list")
+	}
+	names = append(names, "mimeapps.list")
+
+	var files []string
+	for _, d := range dirs {
+		if !filepath.IsAbs(d) {
+			continue
+		}
+		for _, n := range names {
+			files = append(files, filepath.Join(d, n))
+		}
+	}
+	return files
+}
+
+// readMimeApps reads the mimeapps.list files among paths that exist.
+func readMimeApps(paths []string) []mimeAssociations {
+	var out []mimeAssociations
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			continue
+		}
+		groups, err := parseGroups(f)
+		f.Close()
+		if err != nil {
+			continue
+		}
+		out = append(out, mimeAssociations{
+			defaults: associationLists(groups["Default Applications"]),
+			added:    associationLists(groups["Added Associations"]),
+			removed:  associationLists(groups["Removed Associations"]),
+		})
+	}
+	return out
+}
+
+func associationLists(group map[string]string) map[string][]string {
+	out := make(map[string][]string, len(group))
+	for mimeType, ids := range group {
+		out[mimeType] = splitList(ids)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+//go:build !windows
+
+package apps
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func handlerIDs(apps []AppEntry) []string {
+	var ids []string
+	for _, a := range apps {
+		ids = append(ids, a.DesktopID)
+	}
+	return ids
+}
+
+func TestHandlers(t *testing.T) {
+	all := []AppEntry{
+		{Name: "Text Editor", DesktopID: "org.gnome.TextEditor.desktop", MimeTypes: []string{"text/plain"}},
+		{Name: "Firefox", DesktopID: "firefox.desktop", MimeTypes: []string{"text/html", "application/pdf"}},
+		{Name: "Evince", DesktopID: "org.gnome.Evince.desktop", MimeTypes: []string{"application/pdf"}},
+		{Name: "Okular", DesktopID: "okularApplication_pdf.desktop"},
+		{Name: "Notepad", Path: "/usr/bin/notepad", MimeTypes: []string{"text/plain"}},
+	}
+	lists := []mimeAssociations{
+		{
+			defaults: map[string][]string{"application/pdf": {"missing.desktop", "org.gnome.Evince.desktop"}},
+			added:    map[string][]string{"application/pdf": {"okularApplication_pdf.desktop"}},
+			removed:  map[string][]string{"text/html": {"firefox.desktop"}},
+		},
+		{
+			defaults: map[string][]string{"application/pdf": {"firefox.desktop"}},
+			added:    map[string][]string{"text/html": {"firefox.desktop"}},
+		},
+	}
+	tests := []struct {
+		mimeType string
+		want     []string
+	}{
+		// The first installed default wins over later files' defaults, and
+		// added associations come before MimeType= keys.
+		{"application/pdf", []string{"org.gnome.Evince.desktop", "okularApplication_pdf.desktop", "firefox.desktop"}},
+		// A removal hides the app from later files and its own MimeType=,
+		// but text/html is still text/plain.
+		{"text/html", []string{"org.gnome.TextEditor.desktop"}},
+		{"text/x-go", []string{"org.gnome.TextEditor.desktop"}},
+		{"image/png", nil},
+	}
+	for _, tt := range tests {
+		if got := handlerIDs(handlers(all, tt.mimeType, lists)); !slices.Equal(got, tt.want) {
+			t.Errorf("handlers(%q) = %q, want %q", tt.mimeType, got, tt.want)
+		}
+	}
+}
+
+func TestReadMimeApps(t *testing.T) {
+	dir := t.TempDir()
+	list := filepath.Join(dir, "mimeapps.list")
+	src := `[Default Applications]
+application/pdf=org.gnome.Evince.desktop;
+
+[Added Associations]
+text/plain=org.gnome.TextEditor.desktop;code.desktop;
+
+# A comment
+[Removed Associations]
+text/plain=vim.desktop
+`
+	if err := os.WriteFile(list, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := readMimeApps([]string{filepath.Join(dir, "gnome-mimeapps.list"), list})
+	if len(got) != 1 {
+		t.Fatalf("read %d lists, want 1", len(got))
+	}
+	if want := []string{"org.gnome.Evince.desktop"}; !slices.Equal(got[0].defaults["application/pdf"], want) {
+		t.Errorf("defaults = %q, want %q", got[0].defaults, want)
+	}
+	if want := []string{"org.gnome.TextEditor.desktop", "code.desktop"}; !slices.Equal(got[0].added["text/plain"], want) {
+		t.Errorf("added = %q, want %q", got[0].added, want)
+	}
+	if want := []string{"vim.desktop"}; !slices.Equal(got[0].removed["text/plain"], want) {
+		t.Errorf("removed = %q, want %q", got[0].removed, want)
+	}
+}
+
+func TestMimeAppsFiles(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", "/home/ana/.config")
+	t.Setenv("XDG_CONFIG_DIRS", "/etc/xdg")
+	t.Setenv("XDG_DATA_HOME", "/home/ana/.local/share")
+	t.Setenv("XDG_DATA_DIRS", "/usr/share")
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+	got := mimeAppsFiles()
+	want := []string{
+		"/home/ana/.config/ubuntu-mimeapps.list",
+		"/home/ana/.config/gnome-mimeapps.list",
+		"/home/ana/.config/mimeapps.list",
+		"/etc/xdg/ubuntu-mimeapps.list",
+		"/etc/xdg/gnome-mimeapps.list",
+		"/etc/xdg/mimeapps.list",
+		"/home/ana/.local/share/applications/ubuntu-mimeapps.list",
+	}
+	if len(got) < len(want) || !slices.Equal(got[:len(want)], want) {
+		t.Errorf("mimeAppsFiles() = %q, want it to start with %q", got, want)
+	}
+}
+
+func TestMimeType(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{dir, "inode/directory"},
+		{write("report.pdf", "%PDF-1.7"), "application/pdf"},
+		{write("index.html", "<p>hi</p>"), "text/html"},
+		{write("README", "Plain words."), "text/plain"},
+		{write("blob", "\x00\x01\x02\x03"), "application/octet-stream"},
+		{filepath.Join(dir, "missing"), "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		if got := MimeType(tt.path); got != tt.want {
+			t.Errorf("MimeType(%q) = %q, want %q", filepath.Base(tt.path), got, tt.want)
+		}
+	}
+}
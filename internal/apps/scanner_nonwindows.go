@@ -3,7 +3,6 @@
 package apps
 
 import (
-	"bufio"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,6 +16,15 @@ type AppEntry struct {
 	Path    string
 	LnkPath string
 	IsLnk   bool
+
+	// Details from the app's desktop entry, on Linux; localized like Name.
+	GenericName string // the kind of app, e.g. "Web Browser"
+	Comment     string // a tooltip-style description
+	Keywords    []string
+	Categories  []string
+	Actions     []DesktopAction
+	MimeTypes   []string // the file types it opens
+	DesktopID   string   // the desktop file ID, e.g. "org.gnome.Nautilus.desktop"
 }
 
 type Scanner struct {
@@ -72,44 +80,27 @@ func (s *Scanner) Snapshot() ([]AppEntry, []string) {
 }
 
 func scanDesktopApps() []AppEntry {
-	var dirs []string
-	home, _ := os.UserHomeDir()
-
 	switch runtime.GOOS {
-	case "darwin":
-		dirs = []string{"/Applications", filepath.Join(home, "Applications")}
 	case "linux":
-		dirs = []string{"/usr/share/applications", filepath.Join(home, ".local", "share", "applications")}
-	}
-
-	var results []AppEntry
-	for _, root := range dirs {
-		entries, err := os.ReadDir(root)
-		if err != nil {
-			continue
-		}
-		for _, entry := range entries {
-			name := entry.Name()
-			lower := strings.ToLower(name)
-			path := filepath.Join(root, name)
-
-			if runtime.GOOS == "darwin" && strings.HasSuffix(lower, ".app") {
-				results = append(results, AppEntry{Name: strings.TrimSuffix(name, ".app"), Path: path})
+		return scanDesktopEntries(applicationDirs(), currentDesktops())
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		var results []AppEntry
+		for _, root := range []string{"/Applications", filepath.Join(home, "Applications")} {
+			entries, err := os.ReadDir(root)
+			if err != nil {
+				continue
 			}
-			if runtime.GOOS == "linux" && strings.HasSuffix(lower, ".desktop") {
-				entry := readDesktopEntry(path)
-				if !entry.shouldShow {
-					continue
-				}
-				appName := entry.name
-				if appName == "" {
-					appName = strings.TrimSuffix(name, ".desktop")
+			for _, entry := range entries {
+				name := entry.Name()
+				if strings.HasSuffix(strings.ToLower(name), ".app") {
+					results = append(results, AppEntry{Name: strings.TrimSuffix(name, ".app"), Path: filepath.Join(root, name)})
 				}
-				results = append(results, AppEntry{Name: appName, Path: path})
 			}
 		}
+		return results
 	}
-	return results
+	return nil
 }
 
 func scanPathAppsNonWindows() []AppEntry {
@@ -153,6 +144,8 @@ func isExecutable(path string) bool {
 	return err == nil
 }
 
+// deduplicate drops the apps named like an earlier one, ignoring case, such
+// as executables on PATH that a desktop entry already launches.
 func deduplicate(apps []AppEntry) []AppEntry {
 	seen := make(map[string]bool)
 	var result []AppEntry
@@ -166,44 +159,3 @@ func deduplicate(apps []AppEntry) []AppEntry {
 	}
 	return result
 }
-
-type desktopEntry struct {
-	name       string
-	shouldShow bool
-}
-
-// readDesktopEntry reads a .desktop file and returns the app name and whether
-// it should be displayed in a launcher. It skips entries where:
-//   - Type != Application (e.g. directories, links)
-//   - NoDisplay=true (background agents, autostart helpers, etc.)
-//
-// Locale-specific Name[xx]= variants are ignored.
-func readDesktopEntry(path string) desktopEntry {
-	f, err := os.Open(path)
-	if err != nil {
-		return desktopEntry{}
-	}
-	defer f.Close()
-
-	var name string
-	entryType := "Application" // default if Type= is absent
-	noDisplay := false
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		switch {
-		case strings.HasPrefix(line, "Name="):
-			name = strings.TrimSpace(strings.TrimPrefix(line, "Name="))
-		case strings.HasPrefix(line, "Type="):
-			entryType = strings.TrimSpace(strings.TrimPrefix(line, "Type="))
-		case strings.EqualFold(line, "NoDisplay=true"):
-			noDisplay = true
-		}
-	}
-
-	return desktopEntry{
-		name:       name,
-		shouldShow: entryType == "Application" && !noDisplay,
-	}
-}
@@ -14,6 +14,15 @@ type AppEntry struct {
 	Path    string
 	LnkPath string
 	IsLnk   bool
+
+	// Details from the app's desktop entry, on Linux; localized like Name.
+	GenericName string // the kind of app, e.g. "Web Browser"
+	Comment     string // a tooltip-style description
+	Keywords    []string
+	Categories  []string
+	Actions     []DesktopAction
+	MimeTypes   []string // the file types it opens
+	DesktopID   string   // the desktop file ID, e.g. "org.gnome.Nautilus.desktop"
 }
 
 type Scanner struct {
@@ -0,0 +1,524 @@
+package files
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"blight/internal/debug"
+)
+
+const (
+	// maxContentSize is the largest file whose contents are indexed.
+	maxContentSize = 1 << 20
+	// maxContentFiles bounds the memory the content index can use.
+	maxContentFiles = 50_000
+	// maxContentReads is how many candidate files a query re-reads to find
+	// the line that matches.
+	maxContentReads = 64
+	// maxTokenLen drops hashes, base64 and similar blobs that no one searches
+	// for.
+	maxTokenLen = 48
+	// maxQueryTokens keeps per-document hit counters in a byte.
+	maxQueryTokens = 8
+	snippetLen     = 160
+)
+
+// contentExts are the extensions of text files whose contents are indexed:
+// plain text and markup, source code, and configuration.
+var contentExts = map[string]bool{
+	".txt": true, ".md": true, ".markdown": true, ".rst": true, ".org": true, ".tex": true, ".csv": true,
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".ts": true, ".tsx": true, ".mjs": true, ".vue": true, ".svelte": true,
+	".c": true, ".h": true, ".cc": true, ".cpp": true, ".hpp": true, ".cs": true, ".java": true, ".kt": true, ".swift": true,
+	".rs": true, ".rb": true, ".php": true, ".lua": true, ".pl": true, ".r": true, ".dart": true, ".scala": true, ".zig": true,
+	".sh": true, ".bash": true, ".zsh": true, ".fish": true, ".ps1": true, ".bat": true, ".cmd": true, ".sql": true,
+	".html": true, ".htm": true, ".css": true, ".scss": true, ".less": true, ".xml": true, ".svg": true,
+	".json": true, ".yaml": true, ".yml": true, ".toml": true, ".ini": true, ".cfg": true, ".conf": true, ".properties": true,
+	".gitignore": true, ".editorconfig": true,
+}
+
+// contentNames are extensionless files that are conventionally text.
+var contentNames = map[string]bool{
+	"readme": true, "license": true, "changelog": true, "todo": true, "notes": true,
+	"makefile": true, "dockerfile": true, "justfile": true, "procfile": true,
+}
+
+// secretExts are the extensions of keys, certificates and keystores.
+var secretExts = map[string]bool{
+	".env": true, ".pem": true, ".key": true, ".p12": true, ".pfx": true, ".jks": true, ".keystore": true, ".kdbx": true,
+}
+
+// isSecretFile reports whether name looks like it holds credentials, such as
+// .env files and SSH keys. Content search shows matching lines as subtitles,
+// so these are never indexed even when their extension is.
+func isSecretFile(name string) bool {
+	name = strings.ToLower(name)
+	if secretExts[filepath.Ext(name)] {
+		return true
+	}
+	for _, prefix := range []string{".env", "id_", "credentials", "secrets"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isContentFile reports whether e's contents are indexed for content search.
+func isContentFile(e FileEntry) bool {
+	if e.IsDir || e.Size > maxContentSize || isSecretFile(e.Name) {
+		return false
+	}
+	if e.Ext != "" {
+		return contentExts[e.Ext]
+	}
+	return contentNames[strings.ToLower(e.Name)]
+}
+
+// ContentMatch is a file whose contents match a content query, with the line
+// that matches best.
+type ContentMatch struct {
+	Path string
+	Line int // 1-based
+	Text string
+}
+
+// ContentStatus reports on the index behind SearchContent.
+type ContentStatus struct {
+	Enabled bool `json:"enabled"`
+	// Ready is false while the indexed files are still being read.
+	Ready bool `json:"ready"`
+	Files int  `json:"files"`
+}
+
+type contentDoc struct {
+	path string // "" once removed
+	mod  int64
+}
+
+// contentIndex is an inverted index from the words in text files to the files
+// that contain them. Only words are kept; a query re-reads its few candidates
+// to find the matching line.
+type contentIndex struct {
+	mu       sync.RWMutex
+	docs     []contentDoc
+	byPath   map[string]int32
+	postings map[string][]int32
+	terms    []string // sorted keys of postings, for prefix lookups
+	pending  []string // keys added since terms was last merged, unsorted
+	dead     int
+	ready    bool
+}
+
+func newContentIndex() *contentIndex {
+	return &contentIndex{
+		byPath:   make(map[string]int32),
+		postings: make(map[string][]int32),
+	}
+}
+
+// add indexes the words of path, replacing whatever was indexed for it.
+// words must not contain duplicates.
+func (c *contentIndex) add(path string, mod time.Time, words []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.removeLocked(path)
+	if len(c.docs)-c.dead >= maxContentFiles {
+		return
+	}
+	id := int32(len(c.docs))
+	c.docs = append(c.docs, contentDoc{path: path, mod: mod.Unix()})
+	c.byPath[path] = id
+	for _, w := range words {
+		list, ok := c.postings[w]
+		if !ok {
+			c.pending = append(c.pending, w)
+		}
+		c.postings[w] = append(list, id)
+	}
+	// Merging costs a pass over terms, so let pending grow with it.
+	if len(c.pending) > max(4096, len(c.terms)/8) {
+		c.mergeTermsLocked()
+	}
+}
+
+func (c *contentIndex) mergeTermsLocked() {
+	if len(c.pending) == 0 {
+		return
+	}
+	sort.Strings(c.pending)
+	merged := make([]string, 0, len(c.terms)+len(c.pending))
+	i, j := 0, 0
+	for i < len(c.terms) && j < len(c.pending) {
+		if c.terms[i] < c.pending[j] {
+			merged = append(merged, c.terms[i])
+			i++
+		} else {
+			merged = append(merged, c.pending[j])
+			j++
+		}
+	}
+	merged = append(merged, c.terms[i:]...)
+	merged = append(merged, c.pending[j:]...)
+	c.terms = merged
+	c.pending = c.pending[:0]
+}
+
+func (c *contentIndex) removeLocked(path string) bool {
+	id, ok := c.byPath[path]
+	if !ok {
+		return false
+	}
+	delete(c.byPath, path)
+	c.docs[id].path = ""
+	c.dead++
+	return true
+}
+
+// removeTree drops path and, if it was a folder, every file beneath it.
+func (c *contentIndex) removeTree(path string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.removeLocked(path) {
+		prefix := path + string(filepath.Separator)
+		for p := range c.byPath {
+			if strings.HasPrefix(p, prefix) {
+				c.removeLocked(p)
+			}
+		}
+	}
+	if c.dead > 1024 && c.dead > len(c.docs)/2 {
+		c.compactLocked()
+	}
+}
+
+// compactLocked renumbers the live documents and drops removed ones from
+// every posting list.
+func (c *contentIndex) compactLocked() {
+	remap := make([]int32, len(c.docs))
+	docs := make([]contentDoc, 0, len(c.docs)-c.dead)
+	for i, d := range c.docs {
+		if d.path == "" {
+			remap[i] = -1
+			continue
+		}
+		remap[i] = int32(len(docs))
+		c.byPath[d.path] = remap[i]
+		docs = append(docs, d)
+	}
+	for w, list := range c.postings {
+		out := list[:0]
+		for _, id := range list {
+			if r := remap[id]; r >= 0 {
+				out = append(out, r)
+			}
+		}
+		if len(out) == 0 {
+			delete(c.postings, w)
+		} else {
+			c.postings[w] = out
+		}
+	}
+	gone := func(w string) bool {
+		_, ok := c.postings[w]
+		return !ok
+	}
+	c.terms = slices.DeleteFunc(c.terms, gone)
+	c.pending = slices.DeleteFunc(c.pending, gone)
+	c.docs = docs
+	c.dead = 0
+}
+
+// finish marks the initial fill complete.
+func (c *contentIndex) finish() {
+	c.mu.Lock()
+	c.mergeTermsLocked()
+	c.ready = true
+	c.mu.Unlock()
+}
+
+// candidates returns the live documents containing every query word, the last
+// one as a word prefix, most recently modified first.
+func (c *contentIndex) candidates(words []string) []contentDoc {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// hits[id] counts the query words seen so far in document id; a word only
+	// counts when every earlier word was seen, so the total is an intersection.
+	hits := make([]uint8, len(c.docs))
+	mark := func(g int, list []int32) {
+		for _, id := range list {
+			if int(hits[id]) == g {
+				hits[id] = uint8(g + 1)
+			}
+		}
+	}
+	last := len(words) - 1
+	for g, w := range words[:last] {
+		mark(g, c.postings[w])
+	}
+	prefix := words[last]
+	for i := sort.SearchStrings(c.terms, prefix); i < len(c.terms) && strings.HasPrefix(c.terms[i], prefix); i++ {
+		mark(last, c.postings[c.terms[i]])
+	}
+	for _, w := range c.pending {
+		if strings.HasPrefix(w, prefix) {
+			mark(last, c.postings[w])
+		}
+	}
+
+	var out []contentDoc
+	for id, h := range hits {
+		if int(h) == len(words) && c.docs[id].path != "" {
+			out = append(out, c.docs[id])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].mod > out[j].mod })
+	return out
+}
+
+func (c *contentIndex) status() (files int, ready bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.docs) - c.dead, c.ready
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// contentWords calls fn with each lowercase word of text: a run of letters,
+// digits and underscores between two and maxTokenLen bytes long.
+func contentWords(text []byte, fn func(w string)) {
+	start := -1
+	flush := func(end int) {
+		if start >= 0 && end-start <= maxTokenLen && utf8.RuneCount(text[start:end]) >= 2 {
+			fn(strings.ToLower(string(text[start:end])))
+		}
+		start = -1
+	}
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRune(text[i:])
+		if isWordRune(r) {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			flush(i)
+		}
+		i += size
+	}
+	flush(len(text))
+}
+
+// uniqueWords returns the distinct words of text in order of first appearance.
+func uniqueWords(text []byte, limit int) []string {
+	seen := make(map[string]bool)
+	var words []string
+	contentWords(text, func(w string) {
+		if !seen[w] && (limit == 0 || len(words) < limit) {
+			seen[w] = true
+			words = append(words, w)
+		}
+	})
+	return words
+}
+
+// readContentWords reads path and returns its distinct words. ok is false for
+// unreadable files and files that look binary.
+func readContentWords(path string) (words []string, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) > maxContentSize {
+		return nil, false
+	}
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		return nil, false
+	}
+	return uniqueWords(data, 0), true
+}
+
+// bestLine returns the earliest line of data that contains the most query
+// words, and how many it contains.
+func bestLine(data []byte, words []string) (line int, text string, matched int) {
+	needles := make([][]byte, len(words))
+	for i, w := range words {
+		needles[i] = []byte(w)
+	}
+	for n := 1; len(data) > 0; n++ {
+		ln := data
+		if i := bytes.IndexByte(data, '\n'); i >= 0 {
+			ln, data = data[:i], data[i+1:]
+		} else {
+			data = nil
+		}
+		lower := bytes.ToLower(ln)
+		m := 0
+		for _, nd := range needles {
+			if bytes.Contains(lower, nd) {
+				m++
+			}
+		}
+		if m > matched {
+			line, text, matched = n, string(ln), m
+			if m == len(words) {
+				break
+			}
+		}
+	}
+	return line, snippet(text), matched
+}
+
+// snippet tidies a matched line for display as a subtitle.
+func snippet(line string) string {
+	line = strings.TrimSpace(strings.ReplaceAll(line, "\t", " "))
+	if utf8.RuneCountInString(line) <= snippetLen {
+		return line
+	}
+	return string([]rune(line)[:snippetLen]) + "…"
+}
+
+// SetContentSearch turns the content index on or off. Turning it on reads the
+// indexed text files in the background as soon as the file index is ready.
+func (idx *FileIndex) SetContentSearch(enabled bool) {
+	idx.mu.Lock()
+	changed := idx.contentEnabled != enabled
+	idx.contentEnabled = enabled
+	idx.mu.Unlock()
+	if changed && (!enabled || idx.Status().State == "ready") {
+		idx.rebuildContent()
+	}
+}
+
+// ContentStatus reports whether content search is on and how much of it is
+// indexed.
+func (idx *FileIndex) ContentStatus() ContentStatus {
+	idx.mu.RLock()
+	enabled, c := idx.contentEnabled, idx.content
+	idx.mu.RUnlock()
+	st := ContentStatus{Enabled: enabled}
+	if c != nil {
+		st.Files, st.Ready = c.status()
+	}
+	return st
+}
+
+// rebuildContent discards the content index and, if content search is on,
+// starts filling a new one from the files currently indexed. Searches see the
+// new index while it fills.
+func (idx *FileIndex) rebuildContent() {
+	idx.mu.Lock()
+	if idx.contentCancel != nil {
+		idx.contentCancel()
+		idx.contentCancel = nil
+	}
+	idx.content = nil
+	if !idx.contentEnabled {
+		idx.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newContentIndex()
+	idx.content = c
+	idx.contentCancel = cancel
+	idx.mu.Unlock()
+	go idx.fillContent(ctx, c)
+}
+
+func (idx *FileIndex) fillContent(ctx context.Context, c *contentIndex) {
+	start := time.Now()
+	n := 0
+	for _, e := range idx.Files() {
+		if ctx.Err() != nil {
+			return
+		}
+		if n >= maxContentFiles {
+			break
+		}
+		if !isContentFile(e) {
+			continue
+		}
+		if words, ok := readContentWords(e.Path); ok {
+			c.add(e.Path, e.ModTime, words)
+			n++
+		}
+	}
+	c.finish()
+	debug.Get().Info("content index ready", map[string]interface{}{"files": n, "elapsed": time.Since(start).Round(time.Millisecond).String()})
+}
+
+// updateContent applies a batch of watcher changes to the content index: the
+// changed paths are dropped and the text files among added are read again.
+func (idx *FileIndex) updateContent(paths []string, added []FileEntry) {
+	idx.mu.RLock()
+	c := idx.content
+	idx.mu.RUnlock()
+	if c == nil {
+		return
+	}
+	for _, p := range paths {
+		c.removeTree(p)
+	}
+	for _, e := range added {
+		if !isContentFile(e) {
+			continue
+		}
+		if words, ok := readContentWords(e.Path); ok {
+			c.add(e.Path, e.ModTime, words)
+		}
+	}
+}
+
+// SearchContent finds indexed text files that contain every word of query,
+// the last word as a prefix so results keep up with typing. Each match carries
+// the line that matches best; files where no single line has every word rank
+// after those where one does. It returns nil when content search is off or
+// ctx is cancelled.
+func (idx *FileIndex) SearchContent(ctx context.Context, query string, limit int) []ContentMatch {
+	idx.mu.RLock()
+	c := idx.content
+	idx.mu.RUnlock()
+	if c == nil {
+		return nil
+	}
+	words := uniqueWords([]byte(query), maxQueryTokens)
+	if len(words) == 0 {
+		return nil
+	}
+
+	var full, partial []ContentMatch
+	for i, d := range c.candidates(words) {
+		if i >= maxContentReads || len(full) >= limit || ctx.Err() != nil {
+			break
+		}
+		data, err := os.ReadFile(d.path)
+		if err != nil {
+			continue
+		}
+		line, text, matched := bestLine(data, words)
+		if matched == 0 {
+			continue // changed since it was indexed
+		}
+		m := ContentMatch{Path: d.path, Line: line, Text: text}
+		if matched == len(words) {
+			full = append(full, m)
+		} else {
+			partial = append(partial, m)
+		}
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	matches := append(full, partial...)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
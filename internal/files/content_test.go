@@ -0,0 +1,178 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestContentWords(t *testing.T) {
+	var got []string
+	contentWords([]byte("func handleRequest(w, r) // TODO: Fix déjà_vu x 42"), func(w string) {
+		got = append(got, w)
+	})
+	want := []string{"func", "handlerequest", "todo", "fix", "déjà_vu", "42"}
+	if !slices.Equal(got, want) {
+		t.Errorf("contentWords = %q, want %q", got, want)
+	}
+}
+
+func TestIsContentFile(t *testing.T) {
+	cases := []struct {
+		e    FileEntry
+		want bool
+	}{
+		{FileEntry{Name: "main.go", Ext: ".go", Size: 100}, true},
+		{FileEntry{Name: "Makefile"}, true},
+		{FileEntry{Name: "photo.jpg", Ext: ".jpg", Size: 100}, false},
+		{FileEntry{Name: "huge.txt", Ext: ".txt", Size: maxContentSize + 1}, false},
+		{FileEntry{Name: "docs", IsDir: true}, false},
+		{FileEntry{Name: ".env", Ext: ".env", Size: 100}, false},
+		{FileEntry{Name: ".env.local", Ext: ".local", Size: 100}, false},
+		{FileEntry{Name: "prod.env", Ext: ".env", Size: 100}, false},
+		{FileEntry{Name: "server.pem", Ext: ".pem", Size: 100}, false},
+		{FileEntry{Name: "id_ed25519"}, false},
+		{FileEntry{Name: "id_rsa.pub", Ext: ".pub", Size: 100}, false},
+		{FileEntry{Name: "credentials.json", Ext: ".json", Size: 100}, false},
+		{FileEntry{Name: "secrets.yaml", Ext: ".yaml", Size: 100}, false},
+	}
+	for _, c := range cases {
+		if got := isContentFile(c.e); got != c.want {
+			t.Errorf("isContentFile(%s) = %v, want %v", c.e.Name, got, c.want)
+		}
+	}
+}
+
+// contentIndexFor indexes the files of root and fills the content index
+// synchronously.
+func contentIndexFor(t *testing.T, root string) *FileIndex {
+	t.Helper()
+	idx := NewFileIndex([]Root{{Path: root}}, nil)
+	idx.cachePath = ""
+	if !idx.manualIndex(context.Background()) {
+		t.Fatal("scan did not complete")
+	}
+	idx.contentEnabled = true
+	idx.content = newContentIndex()
+	idx.fillContent(context.Background(), idx.content)
+	return idx
+}
+
+func TestSearchContent(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"notes/meeting.md": "# Standup\n\nAction items:\n- ship the quarterly report\n",
+		"src/server.go":    "package main\n\nfunc handleRequest() {\n\t// quarterly numbers live elsewhere\n}\n",
+		"src/blob.txt":     "quarterly\x00report",
+		"photo.jpg":        "quarterly report",
+	})
+	idx := contentIndexFor(t, root)
+
+	got := idx.SearchContent(context.Background(), "quarterly rep", 10)
+	if len(got) != 1 {
+		t.Fatalf("expected one match, got %+v", got)
+	}
+	want := ContentMatch{Path: filepath.Join(root, "notes", "meeting.md"), Line: 4, Text: "- ship the quarterly report"}
+	if got[0] != want {
+		t.Errorf("match = %+v, want %+v", got[0], want)
+	}
+
+	got = idx.SearchContent(context.Background(), "handlereq", 10)
+	if len(got) != 1 || got[0].Line != 3 || got[0].Text != "func handleRequest() {" {
+		t.Errorf("prefix match = %+v", got)
+	}
+
+	if got := idx.SearchContent(context.Background(), "standup elsewhere", 10); len(got) != 0 {
+		t.Errorf("words in different files should not match, got %+v", got)
+	}
+}
+
+func TestSearchContent_PrefersFilesWithAMatchingLine(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"a.txt": "budget\n\nreview\n",
+		"b.txt": "the budget review is friday\n",
+	})
+	idx := contentIndexFor(t, root)
+	got := idx.SearchContent(context.Background(), "budget review", 10)
+	if len(got) != 2 || filepath.Base(got[0].Path) != "b.txt" {
+		t.Errorf("expected b.txt first, got %+v", got)
+	}
+}
+
+func TestSearchContent_Disabled(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	if got := idx.SearchContent(context.Background(), "anything", 10); got != nil {
+		t.Errorf("expected no results with content search off, got %+v", got)
+	}
+	if st := idx.ContentStatus(); st.Enabled || st.Ready {
+		t.Errorf("status = %+v, want disabled", st)
+	}
+}
+
+func TestApplyChanges_UpdatesContent(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "todo.txt")
+	makeTree(t, root, map[string]string{"todo.txt": "buy milk\n"})
+	idx := contentIndexFor(t, root)
+
+	if err := os.WriteFile(path, []byte("call the plumber\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	idx.applyChanges([]string{path}, newFakeWatcher(0))
+	if got := idx.SearchContent(context.Background(), "milk", 10); len(got) != 0 {
+		t.Errorf("expected the old contents to be gone, got %+v", got)
+	}
+	if got := idx.SearchContent(context.Background(), "plumber", 10); len(got) != 1 {
+		t.Errorf("expected the new contents to be searchable, got %+v", got)
+	}
+
+	dir := filepath.Join(root, "drafts")
+	makeTree(t, dir, map[string]string{"letter.md": "dear plumber\n"})
+	idx.applyChanges([]string{dir}, newFakeWatcher(0))
+	if got := idx.SearchContent(context.Background(), "dear", 10); len(got) != 1 {
+		t.Errorf("expected files in a new folder to be indexed, got %+v", got)
+	}
+	os.RemoveAll(dir)
+	idx.applyChanges([]string{dir}, newFakeWatcher(0))
+	if got := idx.SearchContent(context.Background(), "dear", 10); len(got) != 0 {
+		t.Errorf("expected files under a removed folder to be dropped, got %+v", got)
+	}
+}
+
+func TestContentIndex_Compact(t *testing.T) {
+	c := newContentIndex()
+	for i := 0; i < 3000; i++ {
+		words := []string{"common"}
+		if i%3 != 0 {
+			words = append(words, "doomed")
+		}
+		c.add(fmt.Sprintf("/r/f%04d.txt", i), time.Time{}, words)
+	}
+	c.finish()
+	for i := 0; i < 3000; i++ {
+		if i%3 != 0 {
+			c.removeTree(fmt.Sprintf("/r/f%04d.txt", i))
+		}
+	}
+	if len(c.docs) == 3000 {
+		t.Error("expected removals to trigger compaction")
+	}
+	if got := c.candidates([]string{"common"}); len(got) != 1000 {
+		t.Errorf("candidates = %d, want the 1000 live documents", len(got))
+	}
+	if got := c.candidates([]string{"doomed"}); len(got) != 0 {
+		t.Errorf("expected words of removed documents to be gone, got %d", len(got))
+	}
+
+	c.mu.Lock()
+	c.compactLocked()
+	c.mu.Unlock()
+	if len(c.docs) != 1000 || slices.Contains(c.terms, "doomed") {
+		t.Errorf("after compacting: %d documents, terms %q", len(c.docs), c.terms)
+	}
+}
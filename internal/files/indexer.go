@@ -2,27 +2,32 @@ package files
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"blight/internal/debug"
 	"blight/internal/search"
 )
 
 const maxIndexFiles = 500_000
 
 type FileEntry struct {
-	Name  string
-	Path  string
-	Dir   string
-	Ext   string
-	Size  int64
-	IsDir bool
+	Name    string
+	Path    string
+	Dir     string
+	Ext     string
+	Size    int64
+	ModTime time.Time
+	IsDir   bool
 }
 
 type IndexStatus struct {
@@ -30,6 +35,9 @@ type IndexStatus struct {
 	Message string `json:"message"`
 	Count   int    `json:"count"`
 	Total   int    `json:"total"`
+	// Roots is filled in by callers that want a per-root breakdown; status
+	// change events leave it empty.
+	Roots []RootStatus `json:"roots,omitempty"`
 }
 
 type FileIndex struct {
@@ -38,18 +46,31 @@ type FileIndex struct {
 	names       []string
 	dirs        []FileEntry
 	dirNames    []string
+	fileGrams   *trigramIndex // trigram index over names
+	dirGrams    *trigramIndex // trigram index over dirNames
 	status      atomic.Value
 	lastIndexed atomic.Value // stores time.Time
 	cancelFn    atomic.Value // stores context.CancelFunc
 	onStatus    func(IndexStatus)
-	customDirs  []string // user-configured extra scan dirs (from config.IndexDirs)
+	roots       []Root // user-configured scan roots (from config.IndexRoots)
+	rootResults map[string]rootResult
+	cachePath   string // where the index is persisted; see Load
+	rules       *ruleMatcher
+
+	// content is the full-text index behind SearchContent, nil unless
+	// contentEnabled. contentCancel stops the fill in progress.
+	contentEnabled bool
+	content        *contentIndex
+	contentCancel  context.CancelFunc
 }
 
-func NewFileIndex(customDirs []string, onStatus func(IndexStatus)) *FileIndex {
+func NewFileIndex(roots []Root, onStatus func(IndexStatus)) *FileIndex {
 	idx := &FileIndex{
-		onStatus:   onStatus,
-		customDirs: customDirs,
+		onStatus:  onStatus,
+		roots:     normalizeRoots(roots),
+		cachePath: defaultCachePath(),
 	}
+	idx.rules, _ = compileRules(DefaultRules())
 	idx.status.Store(IndexStatus{State: "idle", Message: "Not indexed"})
 	return idx
 }
@@ -58,6 +79,12 @@ func (idx *FileIndex) stopCurrent() {
 	if fn, ok := idx.cancelFn.Load().(context.CancelFunc); ok && fn != nil {
 		fn()
 	}
+	idx.mu.Lock()
+	if idx.contentCancel != nil {
+		idx.contentCancel()
+		idx.contentCancel = nil
+	}
+	idx.mu.Unlock()
 }
 
 func (idx *FileIndex) Start() {
@@ -79,10 +106,26 @@ func (idx *FileIndex) CancelIndex() {
 	idx.setStatus(IndexStatus{State: "idle", Message: "Indexing cancelled"})
 }
 
-func (idx *FileIndex) UpdateDirs(dirs []string) {
+// SetRoots replaces the folders the next scan covers.
+func (idx *FileIndex) SetRoots(roots []Root) {
+	roots = normalizeRoots(roots)
+	idx.mu.Lock()
+	idx.roots = roots
+	idx.mu.Unlock()
+}
+
+// SetRules replaces the include/exclude rules used by the next scan. It
+// returns an error, leaving the current rules in place, if a pattern does not
+// compile.
+func (idx *FileIndex) SetRules(r Rules) error {
+	m, err := compileRules(r)
+	if err != nil {
+		return err
+	}
 	idx.mu.Lock()
-	idx.customDirs = dirs
+	idx.rules = m
 	idx.mu.Unlock()
+	return nil
 }
 
 func (idx *FileIndex) IsStale(maxAge time.Duration) bool {
@@ -90,6 +133,13 @@ func (idx *FileIndex) IsStale(maxAge time.Duration) bool {
 	return !ok || t.IsZero() || time.Since(t) > maxAge
 }
 
+// LastIndexed returns when the current index was built, or the zero time if
+// there is none.
+func (idx *FileIndex) LastIndexed() time.Time {
+	t, _ := idx.lastIndexed.Load().(time.Time)
+	return t
+}
+
 func (idx *FileIndex) ClearIndex() {
 	idx.stopCurrent()
 	idx.mu.Lock()
@@ -97,7 +147,12 @@ func (idx *FileIndex) ClearIndex() {
 	idx.names = nil
 	idx.dirs = nil
 	idx.dirNames = nil
+	idx.fileGrams = nil
+	idx.dirGrams = nil
+	idx.content = nil
 	idx.mu.Unlock()
+	idx.lastIndexed.Store(time.Time{})
+	idx.removeCache()
 	idx.setStatus(IndexStatus{State: "idle", Message: "Index cleared"})
 }
 
@@ -106,19 +161,34 @@ func (idx *FileIndex) Status() IndexStatus {
 }
 
 func (idx *FileIndex) Files() []FileEntry {
-	idx.mu.RLock()
-	defer idx.mu.RUnlock()
-	result := make([]FileEntry, len(idx.files))
-	copy(result, idx.files)
-	return result
+	allFiles, _ := idx.liveEntries()
+	return allFiles
 }
 
 func (idx *FileIndex) Names() []string {
+	allFiles := idx.Files()
+	result := make([]string, len(allFiles))
+	for i, f := range allFiles {
+		result[i] = f.Name
+	}
+	return result
+}
+
+// liveEntries copies the files and folders in the index, leaving out any
+// removed by the watcher since the last rebuild.
+func (idx *FileIndex) liveEntries() (allFiles, allDirs []FileEntry) {
 	idx.mu.RLock()
 	defer idx.mu.RUnlock()
-	result := make([]string, len(idx.names))
-	copy(result, idx.names)
-	return result
+	live := func(entries []FileEntry, grams *trigramIndex) []FileEntry {
+		out := make([]FileEntry, 0, len(entries))
+		for i, e := range entries {
+			if grams == nil || !grams.isRemoved(i) {
+				out = append(out, e)
+			}
+		}
+		return out
+	}
+	return live(idx.files, idx.fileGrams), live(idx.dirs, idx.dirGrams)
 }
 
 func (idx *FileIndex) setStatus(s IndexStatus) {
@@ -130,49 +200,34 @@ func (idx *FileIndex) setStatus(s IndexStatus) {
 
 func (idx *FileIndex) buildIndex(ctx context.Context) {
 	idx.setStatus(IndexStatus{State: "indexing", Message: "Scanning files..."})
-	idx.manualIndex(ctx)
-}
-
-func (idx *FileIndex) manualIndex(ctx context.Context) {
-	home, _ := os.UserHomeDir()
-	scanDirs := []string{
-		filepath.Join(home, "Desktop"),
-		filepath.Join(home, "Documents"),
-		filepath.Join(home, "Downloads"),
-		filepath.Join(home, "Pictures"),
-		filepath.Join(home, "Videos"),
-		filepath.Join(home, "Music"),
+	if idx.manualIndex(ctx) {
+		idx.watch(ctx)
 	}
+}
 
-	if projects := filepath.Join(home, "Projects"); dirExists(projects) {
-		scanDirs = append(scanDirs, projects)
-	}
-	if code := filepath.Join(home, "code"); dirExists(code) {
-		scanDirs = append(scanDirs, code)
-	}
+// scanRoots returns a copy of the configured roots.
+func (idx *FileIndex) scanRoots() []Root {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return append([]Root(nil), idx.roots...)
+}
 
-	// Add user-configured extra directories
-	seen := make(map[string]bool)
-	for _, d := range scanDirs {
-		seen[strings.ToLower(d)] = true
-	}
+// manualIndex walks every scan root and swaps in the result. It reports
+// whether the scan completed.
+func (idx *FileIndex) manualIndex(ctx context.Context) bool {
+	roots := idx.scanRoots()
 	idx.mu.RLock()
-	customDirs := append([]string(nil), idx.customDirs...)
+	rules := idx.rules
 	idx.mu.RUnlock()
-	for _, d := range customDirs {
-		if d != "" && !seen[strings.ToLower(d)] && dirExists(d) {
-			scanDirs = append(scanDirs, d)
-			seen[strings.ToLower(d)] = true
-		}
-	}
+	results := make(map[string]rootResult, len(roots))
 
 	var allFiles []FileEntry
 	var allDirs []FileEntry
 	count := 0
 	total := 0
 
-	for _, dir := range scanDirs {
-		total += estimateCount(dir)
+	for _, root := range roots {
+		total += estimateCount(root.Path)
 	}
 	if total == 0 {
 		total = 1
@@ -181,35 +236,35 @@ func (idx *FileIndex) manualIndex(ctx context.Context) {
 	start := time.Now()
 	lastUpdate := time.Now()
 
-	for _, dir := range scanDirs {
+	for _, root := range roots {
 		if ctx.Err() != nil {
 			idx.setStatus(IndexStatus{State: "idle", Message: "Indexing cancelled"})
-			return
+			return false
 		}
 
-		dirName := filepath.Base(dir)
-		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		dirName := filepath.Base(root.Path)
+		w := rules.walkerAt(root, root.Path)
+		err := w.walk(root.Path, func(path string, d fs.DirEntry) error {
 			if ctx.Err() != nil {
 				return filepath.SkipAll
 			}
-			if err != nil {
-				return nil
-			}
 
 			name := d.Name()
+			if !root.wants(d.IsDir()) {
+				return nil
+			}
 
 			if d.IsDir() {
-				if shouldSkipDir(name) {
-					return filepath.SkipDir
+				entry := FileEntry{
+					Name:  name,
+					Path:  path,
+					Dir:   filepath.Dir(path),
+					IsDir: true,
 				}
-				if path != dir {
-					allDirs = append(allDirs, FileEntry{
-						Name:  name,
-						Path:  path,
-						Dir:   filepath.Dir(path),
-						IsDir: true,
-					})
+				if info, err := d.Info(); err == nil {
+					entry.ModTime = info.ModTime()
 				}
+				allDirs = append(allDirs, entry)
 				return nil
 			}
 
@@ -231,27 +286,69 @@ func (idx *FileIndex) manualIndex(ctx context.Context) {
 
 			info, err := d.Info()
 			size := int64(0)
+			var modTime time.Time
 			if err == nil {
 				size = info.Size()
+				modTime = info.ModTime()
 			}
 
 			allFiles = append(allFiles, FileEntry{
-				Name: name,
-				Path: path,
-				Dir:  filepath.Dir(path),
-				Ext:  strings.ToLower(filepath.Ext(name)),
-				Size: size,
+				Name:    name,
+				Path:    path,
+				Dir:     filepath.Dir(path),
+				Ext:     strings.ToLower(filepath.Ext(name)),
+				Size:    size,
+				ModTime: modTime,
 			})
 
 			return nil
 		})
+		res := rootResult{unreadable: w.unreadable}
+		if err != nil {
+			res.err = rootError(err)
+		}
+		results[root.Path] = res
 	}
 
 	if ctx.Err() != nil {
 		idx.setStatus(IndexStatus{State: "idle", Message: "Indexing cancelled"})
-		return
+		return false
+	}
+
+	idx.setEntries(allFiles, allDirs)
+	idx.mu.Lock()
+	idx.rootResults = results
+	idx.mu.Unlock()
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	msg := fmt.Sprintf("%d files, %d folders indexed in %s", count, len(allDirs), elapsed)
+	idx.lastIndexed.Store(time.Now())
+	if err := idx.save(); err != nil {
+		debug.Get().Error("failed to save file index cache", map[string]interface{}{"path": idx.cachePath, "error": err.Error()})
+	}
+	idx.setStatus(IndexStatus{
+		State:   "ready",
+		Message: msg,
+		Count:   count,
+		Total:   count,
+	})
+	return true
+}
+
+// rootError describes why a root could not be scanned.
+func rootError(err error) string {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "Folder not found"
+	case errors.Is(err, fs.ErrPermission):
+		return "Permission denied"
 	}
+	return err.Error()
+}
 
+// setEntries swaps in a freshly built file and folder list together with the
+// name slices and trigram indexes derived from them.
+func (idx *FileIndex) setEntries(allFiles, allDirs []FileEntry) {
 	names := make([]string, len(allFiles))
 	for i, f := range allFiles {
 		names[i] = f.Name
@@ -262,94 +359,294 @@ func (idx *FileIndex) manualIndex(ctx context.Context) {
 		dirNames[i] = d.Name
 	}
 
+	fileGrams := newEntryIndex(allFiles)
+	dirGrams := newEntryIndex(allDirs)
+
 	idx.mu.Lock()
 	idx.files = allFiles
 	idx.names = names
 	idx.dirs = allDirs
 	idx.dirNames = dirNames
+	idx.fileGrams = fileGrams
+	idx.dirGrams = dirGrams
 	idx.mu.Unlock()
-
-	elapsed := time.Since(start).Round(time.Millisecond)
-	msg := fmt.Sprintf("%d files, %d folders indexed in %s", count, len(allDirs), elapsed)
-	idx.lastIndexed.Store(time.Now())
-	idx.setStatus(IndexStatus{
-		State:   "ready",
-		Message: msg,
-		Count:   count,
-		Total:   count,
-	})
 }
 
-// SearchFiles performs fuzzy matching against the local index filename.
+// SearchFiles performs fuzzy matching against the local index filename, or
+// against the whole path for queries such as "proj/main" or "docs report".
+// Two-rune queries only match names that contain them, so "rp" does not find
+// report.pdf (see searchEntries).
 // usageScores is an optional map of file path → usage score; pass nil for no boosting.
-func (idx *FileIndex) SearchFiles(query string, usageScores map[string]int) []FileEntry {
-	if query == "" {
+// It returns nil if ctx is cancelled before scoring completes.
+func (idx *FileIndex) SearchFiles(ctx context.Context, query string, usageScores map[string]int) []FileEntry {
+	return idx.SearchFilesFiltered(ctx, query, Filter{}, usageScores)
+}
+
+// SearchFilesFiltered is SearchFiles restricted to files that pass f (see
+// ParseQuery). With an empty query it lists the files passing f, most
+// recently modified first.
+func (idx *FileIndex) SearchFilesFiltered(ctx context.Context, query string, f Filter, usageScores map[string]int) []FileEntry {
+	if query == "" && f.IsZero() {
 		return nil
 	}
 
+	// Hold the read lock throughout: the watcher appends to and tombstones
+	// entries in place.
 	idx.mu.RLock()
-	names := idx.names
-	allFiles := idx.files
-	idx.mu.RUnlock()
+	defer idx.mu.RUnlock()
+	return searchEntries(ctx, query, f, idx.files, idx.names, idx.fileGrams, usageScores, 15)
+}
 
-	scores := make([]int, len(allFiles))
-	for i, f := range allFiles {
-		scores[i] = usageScores[f.Path]
+// SearchDirs performs fuzzy matching against the local index directory names,
+// or against whole paths as SearchFiles does.
+// usageScores is an optional map of dir path → usage score; pass nil for no boosting.
+// It returns nil if ctx is cancelled before scoring completes.
+func (idx *FileIndex) SearchDirs(ctx context.Context, query string, usageScores map[string]int) []FileEntry {
+	return idx.SearchDirsFiltered(ctx, query, Filter{}, usageScores)
+}
+
+// SearchDirsFiltered is SearchDirs restricted to folders that pass f.
+func (idx *FileIndex) SearchDirsFiltered(ctx context.Context, query string, f Filter, usageScores map[string]int) []FileEntry {
+	if (query == "" && f.IsZero()) || !f.MatchesFolders() {
+		return nil
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return searchEntries(ctx, query, f, idx.dirs, idx.dirNames, idx.dirGrams, usageScores, 8)
+}
+
+// searchEntries returns the best limit entries for query that pass f. An
+// empty query returns the newest entries passing f, and one that names
+// folders or holds several terms is matched against whole paths by
+// searchPaths. Otherwise the trigram index narrows the candidates to names
+// containing every query term; only when that yields fewer than limit
+// matches are the remaining names scanned for fuzzy (subsequence and
+// acronym) matches, prefiltered by character mask. Two-rune queries skip
+// that fallback: scattered two-letter subsequences match most of the index,
+// and scoring them all takes over 100 ms per keystroke at maxIndexFiles.
+func searchEntries(ctx context.Context, query string, f Filter, entries []FileEntry, names []string, grams *trigramIndex, usageScores map[string]int, limit int) []FileEntry {
+	query = search.Fold(strings.TrimSpace(query))
+	if query == "" {
+		return newestEntries(ctx, entries, grams, limit, f.Match)
+	}
+	if search.PathQuery(query) {
+		return searchPaths(ctx, query, f, entries, grams, usageScores, limit)
 	}
-	matches := search.Fuzzy(strings.ToLower(query), names, scores)
 
-	var results []FileEntry
-	for _, m := range matches {
-		if len(results) >= 15 {
-			break
+	var cands []int32
+	indexed := false
+	if grams != nil {
+		cands, indexed = grams.candidates(query)
+	}
+	if !indexed {
+		scores := make([]int, len(entries))
+		for i, e := range entries {
+			scores[i] = usageScores[e.Path]
+		}
+		matches := search.FuzzyContext(ctx, query, names, scores)
+		if ctx.Err() != nil {
+			return nil
 		}
-		results = append(results, allFiles[m.Index])
+		var results []FileEntry
+		for _, m := range matches {
+			if len(results) >= limit {
+				break
+			}
+			if grams != nil && grams.isRemoved(m.Index) || !f.Match(entries[m.Index]) {
+				continue
+			}
+			results = append(results, entries[m.Index])
+		}
+		return results
 	}
 
+	// Only touch the entry when a filter or usage needs it: at this index
+	// size the cache misses cost more than the matching.
+	filtered := !f.IsZero()
+	top := topMatches{limit: limit}
+	score := func(pos int) {
+		if grams.isRemoved(pos) || filtered && !f.Match(entries[pos]) {
+			return
+		}
+		if s := search.MatchScore(query, grams.folded[pos]); s > 0 {
+			if len(usageScores) > 0 {
+				s += usageScores[entries[pos].Path] * 100
+			}
+			top.offer(search.Match{Score: s, Index: pos})
+		}
+	}
+
+	for i, p := range cands {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
+		score(int(p))
+	}
+
+	if len(top.matches) < limit && utf8.RuneCountInString(query) >= 3 {
+		queryMask := charMask(query)
+		c := 0
+		for i := range grams.folded {
+			if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+				return nil
+			}
+			if c < len(cands) && int(cands[c]) == i {
+				c++
+				continue
+			}
+			if grams.mayFuzzyMatch(i, queryMask) {
+				score(i)
+			}
+		}
+	}
+
+	results := make([]FileEntry, len(top.matches))
+	for i, m := range top.matches {
+		results[i] = entries[m.Index]
+	}
 	return results
 }
 
-// SearchDirs performs fuzzy matching against the local index directory names.
-// usageScores is an optional map of dir path → usage score; pass nil for no boosting.
-func (idx *FileIndex) SearchDirs(query string, usageScores map[string]int) []FileEntry {
-	if query == "" {
+// searchPaths is searchEntries for queries that name folders or hold several
+// terms (see search.PathQuery), scored against each entry's full path. Every
+// term has to occur in the path, so the candidates are the entries whose
+// folded path contains each term of two runes or more; only when none does
+// are the names that might fuzzy-match a term scanned instead.
+func searchPaths(ctx context.Context, query string, f Filter, entries []FileEntry, grams *trigramIndex, usageScores map[string]int, limit int) []FileEntry {
+	terms, _ := search.PathTerms(query)
+	if len(terms) == 0 {
 		return nil
 	}
+	indexed := grams != nil && grams.folders != nil
+
+	// Entries in the same folder share its score, so each folder is only
+	// matched once.
+	scorer := search.NewPathScorer(query)
+	var folderScores []search.FolderScore
+	if indexed {
+		folderScores = make([]search.FolderScore, len(grams.members))
+	}
+	filtered := !f.IsZero()
+	top := topMatches{limit: limit}
+	score := func(pos int) {
+		if grams != nil && grams.isRemoved(pos) || filtered && !f.Match(entries[pos]) {
+			return
+		}
+		var s int
+		if indexed {
+			id := grams.folderOf[pos]
+			if folderScores[id] == nil {
+				folderScores[id] = scorer.Folder(grams.folders.folded[id])
+			}
+			s = scorer.Score(folderScores[id], grams.folded[pos])
+		} else {
+			s = search.PathScore(query, search.Fold(entries[pos].Path))
+		}
+		if s > 0 {
+			if len(usageScores) > 0 {
+				s += usageScores[entries[pos].Path] * 100
+			}
+			top.offer(search.Match{Score: s, Index: pos})
+		}
+	}
+	results := func() []FileEntry {
+		out := make([]FileEntry, len(top.matches))
+		for i, m := range top.matches {
+			out[i] = entries[m.Index]
+		}
+		return out
+	}
 
-	idx.mu.RLock()
-	names := idx.dirNames
-	allDirs := idx.dirs
-	idx.mu.RUnlock()
-
-	scores := make([]int, len(allDirs))
-	for i, d := range allDirs {
-		scores[i] = usageScores[d.Path]
+	// As in candidates, single-rune terms are too common to narrow anything.
+	var long []string
+	var masks []uint64
+	for _, term := range terms {
+		n := utf8.RuneCountInString(term)
+		if n < 2 || !indexed {
+			continue
+		}
+		long = append(long, term)
+		if n >= 3 {
+			masks = append(masks, charMask(term))
+		}
+	}
+	if len(long) == 0 {
+		for i := range entries {
+			if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+				return nil
+			}
+			score(i)
+		}
+		return results()
 	}
-	matches := search.Fuzzy(strings.ToLower(query), names, scores)
 
-	var results []FileEntry
-	for _, m := range matches {
-		if len(results) >= 8 {
-			break
+	cands := grams.pathCandidates(long)
+	for i, p := range cands {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
+		score(int(p))
+	}
+	if len(cands) == 0 && len(masks) > 0 {
+		// A term may still match the name as a subsequence or acronym.
+		for i := range grams.folded {
+			if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+				return nil
+			}
+			for _, m := range masks {
+				if grams.mayFuzzyMatch(i, m) {
+					score(i)
+					break
+				}
+			}
 		}
-		results = append(results, allDirs[m.Index])
 	}
+	return results()
+}
 
+// ctxCheckInterval is how many names searchEntries scores between checks of
+// its context.
+const ctxCheckInterval = 4096
+
+// newestEntries returns the limit most recently modified entries that keep
+// accepts.
+func newestEntries(ctx context.Context, entries []FileEntry, grams *trigramIndex, limit int, keep func(FileEntry) bool) []FileEntry {
+	top := topMatches{limit: limit}
+	for i, e := range entries {
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
+		if grams != nil && grams.isRemoved(i) || !keep(e) {
+			continue
+		}
+		top.offer(search.Match{Score: int(e.ModTime.Unix()), Index: i})
+	}
+	results := make([]FileEntry, len(top.matches))
+	for i, m := range top.matches {
+		results[i] = entries[m.Index]
+	}
 	return results
 }
 
-func shouldSkipDir(name string) bool {
-	if strings.HasPrefix(name, ".") {
-		return true
+// topMatches keeps the limit highest-scoring matches in descending order.
+// Earlier offers win ties, so results are stable for a given index.
+type topMatches struct {
+	limit   int
+	matches []search.Match
+}
+
+func (t *topMatches) offer(m search.Match) {
+	n := len(t.matches)
+	if n == t.limit && m.Score <= t.matches[n-1].Score {
+		return
 	}
-	skip := map[string]bool{
-		"node_modules": true, "__pycache__": true, "vendor": true,
-		"$RECYCLE.BIN": true, "System Volume Information": true,
-		"AppData": true, "cache": true, "Cache": true,
-		"dist": true, "build": true, "target": true,
-		"venv": true, ".venv": true, "env": true,
+	i := sort.Search(n, func(i int) bool { return t.matches[i].Score < m.Score })
+	if n < t.limit {
+		t.matches = append(t.matches, search.Match{})
 	}
-	return skip[name]
+	copy(t.matches[i+1:], t.matches[i:])
+	t.matches[i] = m
 }
 
 func estimateCount(dir string) int {
@@ -0,0 +1,349 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The on-disk index is a small header followed by a varint-encoded body and a
+// CRC-32 of everything before it:
+//
+//	magic "BLIX" | version uvarint | body | crc32 (big endian)
+//
+//	body: lastIndexed unix-nanos varint
+//	      roots:     count uvarint, then each root
+//	      rules:     include strings | exclude strings | ignoreFiles uvarint (0/1)
+//	      dir table: count uvarint, then each parent directory as a string
+//	      files:     count uvarint, then each entry
+//	      dirs:      count uvarint, then each entry
+//	root:  path string | maxDepth uvarint | followSymlinks, includeHidden uvarint (0/1) | only string
+//	entry: parent index uvarint | name string | size uvarint | mtime unix-seconds varint
+//	strings: count uvarint, then each string
+//	string: byte length uvarint | bytes
+//
+// Parent directories are shared by many entries, so storing each once keeps
+// the file a fraction of the size of the in-memory index. Path and Ext are
+// derived from the parent and name on load. The roots and rules record what
+// the index was built from, so a cache that no longer matches the settings is
+// not restored.
+const (
+	cacheMagic   = "BLIX"
+	cacheVersion = 3
+)
+
+// errCacheCorrupt is returned when the cache file fails its checksum or does
+// not decode; callers rebuild the index from scratch.
+var errCacheCorrupt = errors.New("file index cache is corrupt")
+
+// errCacheScope is returned when the cache was built from other roots or
+// rules than the index has now.
+var errCacheScope = errors.New("file index cache was built for other folders or rules")
+
+// cacheHeader is what the cache records besides the entries.
+type cacheHeader struct {
+	lastIndexed time.Time
+	roots       []Root
+	rules       Rules
+}
+
+// defaultCachePath is where the index is persisted between launches.
+func defaultCachePath() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".blight", "fileindex.bin")
+}
+
+// Load restores the index saved by the last successful scan. It leaves the
+// index untouched and returns an error if the cache is missing, was written by
+// another format version, is corrupt or was built from other roots or rules;
+// the caller should then reindex.
+func (idx *FileIndex) Load() error {
+	if idx.cachePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(idx.cachePath)
+	if err != nil {
+		return err
+	}
+	allFiles, allDirs, h, err := decodeIndex(data)
+	if err != nil {
+		if errors.Is(err, errCacheCorrupt) {
+			os.Remove(idx.cachePath)
+		}
+		return err
+	}
+	if cur := idx.cacheHeader(); !h.sameScope(cur) {
+		return errCacheScope
+	}
+
+	idx.setEntries(allFiles, allDirs)
+	idx.lastIndexed.Store(h.lastIndexed)
+	idx.setStatus(IndexStatus{
+		State:   "ready",
+		Message: fmt.Sprintf("%d files, %d folders loaded from cache", len(allFiles), len(allDirs)),
+		Count:   len(allFiles),
+		Total:   len(allFiles),
+	})
+	return nil
+}
+
+// save writes the current index to the cache file, replacing it atomically so
+// a crash mid-write never leaves a truncated cache behind.
+func (idx *FileIndex) save() error {
+	if idx.cachePath == "" {
+		return nil
+	}
+	allFiles, allDirs := idx.liveEntries()
+	data := encodeIndex(allFiles, allDirs, idx.cacheHeader())
+
+	if err := os.MkdirAll(filepath.Dir(idx.cachePath), 0755); err != nil {
+		return err
+	}
+	tmp := idx.cachePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, idx.cachePath)
+}
+
+// cacheHeader returns the header a cache of the current index carries.
+func (idx *FileIndex) cacheHeader() cacheHeader {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return cacheHeader{lastIndexed: idx.LastIndexed(), roots: idx.roots, rules: idx.rules.source}
+}
+
+// sameScope reports whether h and o were built from the same roots and rules.
+func (h cacheHeader) sameScope(o cacheHeader) bool {
+	return slices.Equal(h.roots, o.roots) &&
+		slices.Equal(h.rules.Include, o.rules.Include) &&
+		slices.Equal(h.rules.Exclude, o.rules.Exclude) &&
+		h.rules.IgnoreFiles == o.rules.IgnoreFiles
+}
+
+// removeCache deletes the cache file so a cleared index stays cleared.
+func (idx *FileIndex) removeCache() {
+	if idx.cachePath != "" {
+		os.Remove(idx.cachePath)
+	}
+}
+
+func encodeIndex(allFiles, allDirs []FileEntry, h cacheHeader) []byte {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	var scratch [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) { w.Write(scratch[:binary.PutUvarint(scratch[:], v)]) }
+	putVarint := func(v int64) { w.Write(scratch[:binary.PutVarint(scratch[:], v)]) }
+	putString := func(s string) {
+		putUvarint(uint64(len(s)))
+		w.WriteString(s)
+	}
+	putStrings := func(list []string) {
+		putUvarint(uint64(len(list)))
+		for _, s := range list {
+			putString(s)
+		}
+	}
+	putBool := func(b bool) {
+		if b {
+			putUvarint(1)
+		} else {
+			putUvarint(0)
+		}
+	}
+
+	w.WriteString(cacheMagic)
+	putUvarint(cacheVersion)
+	putVarint(h.lastIndexed.UnixNano())
+	putUvarint(uint64(len(h.roots)))
+	for _, r := range h.roots {
+		putString(r.Path)
+		putUvarint(uint64(max(r.MaxDepth, 0)))
+		putBool(r.FollowSymlinks)
+		putBool(r.IncludeHidden)
+		putString(r.Only)
+	}
+	putStrings(h.rules.Include)
+	putStrings(h.rules.Exclude)
+	putBool(h.rules.IgnoreFiles)
+
+	parents := make(map[string]int)
+	var parentList []string
+	for _, list := range [][]FileEntry{allFiles, allDirs} {
+		for _, e := range list {
+			if _, ok := parents[e.Dir]; !ok {
+				parents[e.Dir] = len(parentList)
+				parentList = append(parentList, e.Dir)
+			}
+		}
+	}
+	putUvarint(uint64(len(parentList)))
+	for _, p := range parentList {
+		putString(p)
+	}
+
+	for _, list := range [][]FileEntry{allFiles, allDirs} {
+		putUvarint(uint64(len(list)))
+		for _, e := range list {
+			putUvarint(uint64(parents[e.Dir]))
+			putString(e.Name)
+			putUvarint(uint64(max(e.Size, 0)))
+			var mtime int64
+			if !e.ModTime.IsZero() {
+				mtime = e.ModTime.Unix()
+			}
+			putVarint(mtime)
+		}
+	}
+
+	w.Flush()
+	return binary.BigEndian.AppendUint32(buf.Bytes(), crc32.ChecksumIEEE(buf.Bytes()))
+}
+
+func decodeIndex(data []byte) (allFiles, allDirs []FileEntry, h cacheHeader, err error) {
+	if len(data) < len(cacheMagic)+4 || string(data[:len(cacheMagic)]) != cacheMagic {
+		return nil, nil, cacheHeader{}, errCacheCorrupt
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, nil, cacheHeader{}, errCacheCorrupt
+	}
+
+	r := bytes.NewReader(body[len(cacheMagic):])
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, nil, cacheHeader{}, errCacheCorrupt
+	}
+	if version != cacheVersion {
+		return nil, nil, cacheHeader{}, fmt.Errorf("file index cache version %d, want %d", version, cacheVersion)
+	}
+
+	d := cacheDecoder{r: r}
+	h.lastIndexed = time.Unix(0, d.varint())
+	// Every root takes at least five bytes.
+	h.roots = make([]Root, d.count(r.Len()/5))
+	for i := range h.roots {
+		h.roots[i] = Root{
+			Path:           d.string(),
+			MaxDepth:       int(d.uvarint()),
+			FollowSymlinks: d.uvarint() != 0,
+			IncludeHidden:  d.uvarint() != 0,
+			Only:           d.string(),
+		}
+	}
+	h.rules = Rules{Include: d.strings(), Exclude: d.strings(), IgnoreFiles: d.uvarint() != 0}
+
+	parents := make([]string, d.count(r.Len()))
+	for i := range parents {
+		parents[i] = d.string()
+	}
+	allFiles = d.entries(parents, false)
+	allDirs = d.entries(parents, true)
+	if d.err != nil || r.Len() != 0 {
+		return nil, nil, cacheHeader{}, errCacheCorrupt
+	}
+	return allFiles, allDirs, h, nil
+}
+
+// cacheDecoder reads the body of a cache file, remembering the first error so
+// the caller checks once at the end.
+type cacheDecoder struct {
+	r   *bytes.Reader
+	err error
+}
+
+func (d *cacheDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadUvarint(d.r)
+	d.err = err
+	return v
+}
+
+func (d *cacheDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, err := binary.ReadVarint(d.r)
+	d.err = err
+	return v
+}
+
+// count reads a length prefix, rejecting any larger than limit so a corrupt
+// length cannot trigger a huge allocation.
+func (d *cacheDecoder) count(limit int) int {
+	n := d.uvarint()
+	if n > uint64(limit) {
+		d.err = errCacheCorrupt
+		return 0
+	}
+	return int(n)
+}
+
+func (d *cacheDecoder) string() string {
+	n := d.count(d.r.Len())
+	if d.err != nil {
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = err
+	}
+	return string(b)
+}
+
+func (d *cacheDecoder) strings() []string {
+	n := d.count(d.r.Len())
+	if d.err != nil || n == 0 {
+		return nil
+	}
+	list := make([]string, n)
+	for i := range list {
+		list[i] = d.string()
+	}
+	return list
+}
+
+func (d *cacheDecoder) entries(parents []string, isDir bool) []FileEntry {
+	// Every entry takes at least four bytes, which bounds a sane count.
+	list := make([]FileEntry, d.count(d.r.Len()/4))
+	for i := range list {
+		parent := d.uvarint()
+		name := d.string()
+		size := d.uvarint()
+		mtime := d.varint()
+		if d.err != nil {
+			return nil
+		}
+		if parent >= uint64(len(parents)) {
+			d.err = errCacheCorrupt
+			return nil
+		}
+		dir := parents[parent]
+		e := FileEntry{
+			Name:  name,
+			Path:  filepath.Join(dir, name),
+			Dir:   dir,
+			Size:  int64(size),
+			IsDir: isDir,
+		}
+		if !isDir {
+			e.Ext = strings.ToLower(filepath.Ext(name))
+		}
+		if mtime != 0 {
+			e.ModTime = time.Unix(mtime, 0)
+		}
+		list[i] = e
+	}
+	return list
+}
@@ -0,0 +1,159 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testEntries() (allFiles, allDirs []FileEntry) {
+	mtime := time.Unix(1700000000, 0)
+	allFiles = []FileEntry{
+		{Name: "Report.PDF", Path: filepath.Join("/home/u/Documents", "Report.PDF"), Dir: "/home/u/Documents", Ext: ".pdf", Size: 1234, ModTime: mtime},
+		{Name: "notes", Path: filepath.Join("/home/u/Documents", "notes"), Dir: "/home/u/Documents", Size: 0},
+		{Name: "résumé.txt", Path: filepath.Join("/home/u/Desktop", "résumé.txt"), Dir: "/home/u/Desktop", Ext: ".txt", Size: 99, ModTime: mtime},
+	}
+	allDirs = []FileEntry{
+		{Name: "Projects", Path: filepath.Join("/home/u", "Projects"), Dir: "/home/u", IsDir: true, ModTime: mtime},
+	}
+	return allFiles, allDirs
+}
+
+func TestIndexCache_RoundTrip(t *testing.T) {
+	allFiles, allDirs := testEntries()
+	h := cacheHeader{
+		lastIndexed: time.Unix(0, 1700000123456789),
+		roots:       []Root{{Path: "/home/u"}, {Path: "/mnt/data", MaxDepth: 3, FollowSymlinks: true, IncludeHidden: true, Only: RootFilesOnly}},
+		rules:       Rules{Include: []string{"*.md"}, Exclude: []string{"node_modules/", "*.log"}, IgnoreFiles: true},
+	}
+
+	gotFiles, gotDirs, gotHeader, err := decodeIndex(encodeIndex(allFiles, allDirs, h))
+	if err != nil {
+		t.Fatalf("decodeIndex: %v", err)
+	}
+	if !reflect.DeepEqual(gotFiles, allFiles) {
+		t.Errorf("files = %+v, want %+v", gotFiles, allFiles)
+	}
+	if !reflect.DeepEqual(gotDirs, allDirs) {
+		t.Errorf("dirs = %+v, want %+v", gotDirs, allDirs)
+	}
+	if !gotHeader.lastIndexed.Equal(h.lastIndexed) {
+		t.Errorf("lastIndexed = %v, want %v", gotHeader.lastIndexed, h.lastIndexed)
+	}
+	if !reflect.DeepEqual(gotHeader.roots, h.roots) {
+		t.Errorf("roots = %+v, want %+v", gotHeader.roots, h.roots)
+	}
+	if !reflect.DeepEqual(gotHeader.rules, h.rules) {
+		t.Errorf("rules = %+v, want %+v", gotHeader.rules, h.rules)
+	}
+}
+
+func TestIndexCache_DetectsCorruption(t *testing.T) {
+	allFiles, allDirs := testEntries()
+	data := encodeIndex(allFiles, allDirs, cacheHeader{lastIndexed: time.Now()})
+
+	flipped := append([]byte(nil), data...)
+	flipped[len(flipped)/2] ^= 0x40
+	if _, _, _, err := decodeIndex(flipped); err != errCacheCorrupt {
+		t.Errorf("flipped byte: err = %v, want errCacheCorrupt", err)
+	}
+	if _, _, _, err := decodeIndex(data[:len(data)-7]); err != errCacheCorrupt {
+		t.Errorf("truncated: err = %v, want errCacheCorrupt", err)
+	}
+	if _, _, _, err := decodeIndex([]byte("not an index")); err != errCacheCorrupt {
+		t.Errorf("garbage: err = %v, want errCacheCorrupt", err)
+	}
+}
+
+func TestFileIndex_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fileindex.bin")
+	allFiles, allDirs := testEntries()
+
+	src := NewFileIndex(nil, nil)
+	src.cachePath = path
+	src.setEntries(allFiles, allDirs)
+	src.lastIndexed.Store(time.Now())
+	if err := src.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	dst := NewFileIndex(nil, nil)
+	dst.cachePath = path
+	if err := dst.Load(); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if dst.IsStale(time.Hour) {
+		t.Error("a freshly saved index should not be stale after loading")
+	}
+	if got := dst.Status().State; got != "ready" {
+		t.Errorf("status = %q, want ready", got)
+	}
+	if len(dst.Files()) != len(allFiles) {
+		t.Errorf("loaded %d files, want %d", len(dst.Files()), len(allFiles))
+	}
+}
+
+func TestFileIndex_LoadRejectsCacheForOtherRoots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fileindex.bin")
+	allFiles, allDirs := testEntries()
+
+	src := NewFileIndex([]Root{{Path: "/home/u/Documents"}}, nil)
+	src.cachePath = path
+	src.setEntries(allFiles, allDirs)
+	src.lastIndexed.Store(time.Now())
+	if err := src.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	dst := NewFileIndex([]Root{{Path: "/home/u/Desktop"}}, nil)
+	dst.cachePath = path
+	if err := dst.Load(); err != errCacheScope {
+		t.Fatalf("Load: err = %v, want errCacheScope", err)
+	}
+	if !dst.IsStale(time.Hour) || len(dst.Files()) != 0 {
+		t.Error("a cache for other roots should leave the index empty and stale")
+	}
+}
+
+func TestFileIndex_LoadRejectsCacheForOtherRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fileindex.bin")
+	allFiles, allDirs := testEntries()
+
+	src := NewFileIndex(nil, nil)
+	src.cachePath = path
+	src.setEntries(allFiles, allDirs)
+	src.lastIndexed.Store(time.Now())
+	if err := src.save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	dst := NewFileIndex(nil, nil)
+	dst.cachePath = path
+	if err := dst.SetRules(Rules{Exclude: []string{"Documents/"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Load(); err != errCacheScope {
+		t.Fatalf("Load: err = %v, want errCacheScope", err)
+	}
+}
+
+func TestFileIndex_LoadCorruptCacheRemovesIt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fileindex.bin")
+	if err := os.WriteFile(path, []byte("BLIX garbage garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewFileIndex(nil, nil)
+	idx.cachePath = path
+	if err := idx.Load(); err == nil {
+		t.Fatal("expected an error for a corrupt cache")
+	}
+	if !idx.IsStale(time.Hour) {
+		t.Error("a failed load should leave the index stale so it is rebuilt")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("expected the corrupt cache to be removed")
+	}
+}
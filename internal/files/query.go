@@ -0,0 +1,321 @@
+package files
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Filter narrows file search results by attributes other than the name. The
+// zero Filter matches everything.
+type Filter struct {
+	exts    []string // lowercase with leading dot; any may match
+	sized   bool
+	minSize int64
+	maxSize int64     // inclusive; negative means unbounded
+	after   time.Time // ModTime at or after; zero means unbounded
+	before  time.Time // ModTime before; zero means unbounded
+	dirs    []string  // absolute folders, or folder names to find anywhere in the path
+}
+
+// IsZero reports whether f matches everything.
+func (f Filter) IsZero() bool {
+	return len(f.exts) == 0 && !f.sized && f.after.IsZero() && f.before.IsZero() && len(f.dirs) == 0
+}
+
+// MatchesFolders reports whether any folder can pass f. Extension and size
+// filters only make sense for files.
+func (f Filter) MatchesFolders() bool {
+	return len(f.exts) == 0 && !f.sized
+}
+
+// Match reports whether e passes every part of f.
+func (f Filter) Match(e FileEntry) bool {
+	if len(f.exts) > 0 {
+		ok := false
+		for _, x := range f.exts {
+			if e.Ext == x {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if f.sized && (e.IsDir || e.Size < f.minSize || (f.maxSize >= 0 && e.Size > f.maxSize)) {
+		return false
+	}
+	if !f.after.IsZero() && e.ModTime.Before(f.after) {
+		return false
+	}
+	if !f.before.IsZero() && !e.ModTime.Before(f.before) {
+		return false
+	}
+	if len(f.dirs) > 0 {
+		ok := false
+		for _, d := range f.dirs {
+			if underDir(e.Path, d) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// underDir reports whether path lies beneath dir. An absolute dir must be an
+// ancestor of path; a bare folder name may be any folder along it.
+func underDir(path, dir string) bool {
+	if runtime.GOOS == "windows" {
+		path, dir = strings.ToLower(path), strings.ToLower(dir)
+	}
+	sep := string(filepath.Separator)
+	if filepath.IsAbs(dir) {
+		return strings.HasPrefix(path, strings.TrimSuffix(dir, sep)+sep)
+	}
+	return strings.Contains(filepath.Dir(path)+sep, sep+dir+sep)
+}
+
+// ParseQuery splits filter terms out of a file query and returns the rest as
+// the text to match against names. now anchors relative dates.
+//
+//	ext:pdf  ext:jpg,png        extension, any of a list
+//	size:>1mb  size:<=200kb     size bound (b, kb, mb, gb, tb; 1024-based)
+//	size:1mb..10mb  size:0      size range, or an exact size
+//	modified:<7d  modified:>1y  changed within, or longer ago than, a duration
+//	                            (min, h, d, w, mo, y; days if no unit)
+//	modified:2024-05  modified:>=2024-01-01  modified:today
+//	                            a calendar day, month or year, or bounded by one
+//	in:~/Documents  in:src      beneath a folder, or any folder of that name
+//
+// Values may be double-quoted to include spaces. A filter with no value yet
+// ("ext:", "size:>") is ignored so a query can be typed out; any other value
+// that does not parse is an error. Unknown keys are left in the text.
+func ParseQuery(query string, now time.Time) (text string, f Filter, err error) {
+	var words []string
+	for _, tok := range splitQuery(query) {
+		key, value, ok := strings.Cut(tok, ":")
+		if !ok {
+			words = append(words, strings.ReplaceAll(tok, `"`, ""))
+			continue
+		}
+		value = strings.Trim(value, `"`)
+		switch strings.ToLower(key) {
+		case "ext":
+			for _, x := range strings.Split(value, ",") {
+				if x = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(x), ".")); x != "" {
+					f.exts = append(f.exts, "."+x)
+				}
+			}
+		case "size":
+			if err = f.parseSize(value); err != nil {
+				return "", Filter{}, err
+			}
+		case "modified":
+			if err = f.parseModified(value, now); err != nil {
+				return "", Filter{}, err
+			}
+		case "in":
+			if value != "" {
+				dir := filepath.FromSlash(expandHome(value))
+				if filepath.IsAbs(dir) {
+					dir = filepath.Clean(dir)
+				} else {
+					dir = strings.Trim(dir, string(filepath.Separator))
+				}
+				f.dirs = append(f.dirs, dir)
+			}
+		default:
+			words = append(words, strings.ReplaceAll(tok, `"`, ""))
+		}
+	}
+	return strings.Join(words, " "), f, nil
+}
+
+// splitQuery splits on whitespace outside double quotes.
+func splitQuery(q string) []string {
+	var toks []string
+	var b strings.Builder
+	quoted := false
+	for _, r := range q {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			b.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if b.Len() > 0 {
+				toks = append(toks, b.String())
+				b.Reset()
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() > 0 {
+		toks = append(toks, b.String())
+	}
+	return toks
+}
+
+// cutOp splits a leading comparison operator off value.
+func cutOp(value string) (op, rest string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if r, ok := strings.CutPrefix(value, op); ok {
+			return op, strings.TrimSpace(r)
+		}
+	}
+	return "", value
+}
+
+func (f *Filter) parseSize(value string) error {
+	op, v := cutOp(value)
+	if v == "" {
+		return nil
+	}
+	if lo, hi, ok := strings.Cut(v, ".."); ok && op == "" {
+		from, err := parseBytes(lo)
+		if err != nil {
+			return err
+		}
+		to, err := parseBytes(hi)
+		if err != nil {
+			return err
+		}
+		f.sized, f.minSize, f.maxSize = true, from, to
+		return nil
+	}
+	n, err := parseBytes(v)
+	if err != nil {
+		return err
+	}
+	f.sized, f.minSize, f.maxSize = true, 0, -1
+	switch op {
+	case ">":
+		f.minSize = n + 1
+	case ">=":
+		f.minSize = n
+	case "<":
+		f.maxSize = n - 1
+	case "<=":
+		f.maxSize = n
+	default:
+		f.minSize, f.maxSize = n, n
+	}
+	return nil
+}
+
+var byteUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30,
+	"t": 1 << 40, "tb": 1 << 40,
+}
+
+// parseBytes parses a size such as "200", "1.5mb" or "4k".
+func parseBytes(s string) (int64, error) {
+	num, unit := splitNumber(strings.ToLower(strings.TrimSpace(s)))
+	mult, ok := byteUnits[unit]
+	n, err := strconv.ParseFloat(num, 64)
+	if !ok || err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * mult), nil
+}
+
+// splitNumber splits s into a leading decimal number and the rest.
+func splitNumber(s string) (num, rest string) {
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+func (f *Filter) parseModified(value string, now time.Time) error {
+	op, v := cutOp(strings.ToLower(value))
+	if v == "" {
+		return nil
+	}
+	if start, end, ok := parseDay(v, now); ok {
+		switch op {
+		case ">":
+			f.after = end
+		case ">=":
+			f.after = start
+		case "<":
+			f.before = start
+		case "<=":
+			f.before = end
+		default:
+			f.after, f.before = start, end
+		}
+		return nil
+	}
+	d, err := parseAge(v)
+	if err != nil {
+		return err
+	}
+	// An age bound: "<7d" is newer than seven days ago, ">7d" older.
+	if op == ">" || op == ">=" {
+		f.before = now.Add(-d)
+	} else {
+		f.after = now.Add(-d)
+	}
+	return nil
+}
+
+var ageUnits = map[string]time.Duration{
+	"":    24 * time.Hour,
+	"min": time.Minute,
+	"h":   time.Hour,
+	"d":   24 * time.Hour,
+	"w":   7 * 24 * time.Hour,
+	"mo":  30 * 24 * time.Hour,
+	"y":   365 * 24 * time.Hour,
+}
+
+// parseAge parses a duration such as "7d", "3w", "90min" or "7" (days).
+func parseAge(s string) (time.Duration, error) {
+	num, unit := splitNumber(s)
+	mult, ok := ageUnits[unit]
+	n, err := strconv.Atoi(num)
+	if !ok || err != nil {
+		return 0, fmt.Errorf("invalid date or age %q", s)
+	}
+	return time.Duration(n) * mult, nil
+}
+
+// parseDay parses "today", "yesterday" or a calendar year, month or day in
+// local time, returning the half-open interval it covers.
+func parseDay(s string, now time.Time) (start, end time.Time, ok bool) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch s {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	}
+	for _, layout := range []struct {
+		format     string
+		y, m, days int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if t, err := time.ParseInLocation(layout.format, s, now.Location()); err == nil {
+			return t, t.AddDate(layout.y, layout.m, layout.days), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
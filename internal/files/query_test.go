@@ -0,0 +1,152 @@
+package files
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.Local)
+	home, _ := os.UserHomeDir()
+	day := 24 * time.Hour
+	cases := []struct {
+		query string
+		text  string
+		match []FileEntry
+		miss  []FileEntry
+	}{
+		{
+			query: "report ext:pdf",
+			text:  "report",
+			match: []FileEntry{{Ext: ".pdf"}},
+			miss:  []FileEntry{{Ext: ".docx"}},
+		},
+		{
+			query: "ext:.JPG,png",
+			match: []FileEntry{{Ext: ".jpg"}, {Ext: ".png"}},
+			miss:  []FileEntry{{Ext: ".gif"}},
+		},
+		{
+			query: "size:>1mb",
+			match: []FileEntry{{Size: 1<<20 + 1}},
+			miss:  []FileEntry{{Size: 1 << 20}, {Size: 5 << 20, IsDir: true}},
+		},
+		{
+			query: "size:1.5k..2kb",
+			match: []FileEntry{{Size: 1536}, {Size: 2048}},
+			miss:  []FileEntry{{Size: 1535}, {Size: 2049}},
+		},
+		{
+			query: "size:0",
+			match: []FileEntry{{Size: 0}},
+			miss:  []FileEntry{{Size: 1}},
+		},
+		{
+			query: "modified:<7d notes",
+			text:  "notes",
+			match: []FileEntry{{ModTime: now.Add(-6 * day)}},
+			miss:  []FileEntry{{ModTime: now.Add(-8 * day)}},
+		},
+		{
+			query: "modified:>1y",
+			match: []FileEntry{{ModTime: now.AddDate(-2, 0, 0)}},
+			miss:  []FileEntry{{ModTime: now.AddDate(0, -6, 0)}},
+		},
+		{
+			query: "modified:2024-05",
+			match: []FileEntry{{ModTime: time.Date(2024, 5, 31, 23, 0, 0, 0, time.Local)}},
+			miss:  []FileEntry{{ModTime: time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)}},
+		},
+		{
+			query: "modified:>=2024-06-10",
+			match: []FileEntry{{ModTime: time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)}},
+			miss:  []FileEntry{{ModTime: time.Date(2024, 6, 9, 23, 59, 0, 0, time.Local)}},
+		},
+		{
+			query: "modified:today",
+			match: []FileEntry{{ModTime: now.Add(-time.Hour)}},
+			miss:  []FileEntry{{ModTime: now.Add(-13 * time.Hour)}},
+		},
+		{
+			query: "in:~/Documents",
+			match: []FileEntry{{Path: filepath.Join(home, "Documents", "a", "b.txt")}},
+			miss:  []FileEntry{{Path: filepath.Join(home, "Documents2", "b.txt")}, {Path: filepath.Join(home, "Documents")}},
+		},
+		{
+			query: `in:"my projects" budget`,
+			text:  "budget",
+			match: []FileEntry{{Path: filepath.Join("/x", "my projects", "y", "budget.xlsx")}},
+			miss:  []FileEntry{{Path: filepath.Join("/x", "projects", "budget.xlsx")}},
+		},
+		{
+			query: "ext: size:> todo: list",
+			text:  "todo: list",
+			match: []FileEntry{{Ext: ".txt", Size: 10}},
+		},
+	}
+	for _, c := range cases {
+		text, f, err := ParseQuery(c.query, now)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", c.query, err)
+			continue
+		}
+		if text != c.text {
+			t.Errorf("ParseQuery(%q) text = %q, want %q", c.query, text, c.text)
+		}
+		for _, e := range c.match {
+			if !f.Match(e) {
+				t.Errorf("%q should match %+v", c.query, e)
+			}
+		}
+		for _, e := range c.miss {
+			if f.Match(e) {
+				t.Errorf("%q should not match %+v", c.query, e)
+			}
+		}
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	for _, q := range []string{"size:>huge", "size:1mb..lots", "modified:<soon", "modified:7x"} {
+		if _, _, err := ParseQuery(q, time.Now()); err == nil {
+			t.Errorf("ParseQuery(%q): expected an error", q)
+		}
+	}
+}
+
+func TestSearchFilesFiltered(t *testing.T) {
+	now := time.Now()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries([]FileEntry{
+		{Name: "report.pdf", Path: "/d/report.pdf", Dir: "/d", Ext: ".pdf", Size: 2 << 20, ModTime: now.Add(-48 * time.Hour)},
+		{Name: "report.docx", Path: "/d/report.docx", Dir: "/d", Ext: ".docx", Size: 2 << 20, ModTime: now},
+		{Name: "old report.pdf", Path: "/d/old report.pdf", Dir: "/d", Ext: ".pdf", Size: 2 << 20, ModTime: now.AddDate(-1, 0, 0)},
+		{Name: "scan.pdf", Path: "/d/scan.pdf", Dir: "/d", Ext: ".pdf", Size: 100, ModTime: now.Add(-time.Hour)},
+	}, []FileEntry{
+		{Name: "reports", Path: "/d/reports", Dir: "/d", IsDir: true, ModTime: now},
+	})
+
+	text, f, _ := ParseQuery("report ext:pdf modified:<7d", now)
+	got := idx.SearchFilesFiltered(context.Background(), text, f, nil)
+	if len(got) != 1 || got[0].Name != "report.pdf" {
+		t.Errorf("filtered search = %v, want only report.pdf", got)
+	}
+
+	// Filters alone list the newest matching files.
+	_, f, _ = ParseQuery("ext:pdf", now)
+	got = idx.SearchFilesFiltered(context.Background(), "", f, nil)
+	if len(got) != 3 || got[0].Name != "scan.pdf" || got[2].Name != "old report.pdf" {
+		t.Errorf("filter-only search = %v, want pdfs newest first", got)
+	}
+
+	if got := idx.SearchDirsFiltered(context.Background(), "report", f, nil); got != nil {
+		t.Errorf("extension filters should exclude folders, got %v", got)
+	}
+	_, f, _ = ParseQuery("modified:today", now)
+	if got := idx.SearchDirsFiltered(context.Background(), "report", f, nil); len(got) != 1 {
+		t.Errorf("date filters should apply to folders, got %v", got)
+	}
+}
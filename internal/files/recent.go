@@ -0,0 +1,80 @@
+package files
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+)
+
+// RecentOptions narrows RecentFiles.
+type RecentOptions struct {
+	// Roots limits the list to files beneath these folders; empty means
+	// everything indexed.
+	Roots []string
+	// Ignore holds patterns, in the Rules.Exclude syntax, for indexed files
+	// that should still never be listed (partial downloads, lock files).
+	Ignore []string
+}
+
+// RecentFiles returns the limit most recently modified indexed files that pass
+// opts and f and whose names contain text, ignoring case.
+func (idx *FileIndex) RecentFiles(ctx context.Context, text string, f Filter, opts RecentOptions, limit int) ([]FileEntry, error) {
+	ignore, err := compilePatterns(opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+	roots := make([]string, 0, len(opts.Roots))
+	for _, r := range opts.Roots {
+		if r = strings.TrimSpace(r); r != "" {
+			roots = append(roots, filepath.Clean(expandHome(r)))
+		}
+	}
+	text = strings.ToLower(strings.TrimSpace(text))
+
+	// A pattern such as "cache/" hides everything beneath a folder, so each
+	// folder's verdict is remembered for its siblings.
+	ignoredDirs := make(map[string]bool)
+	var dirIgnored func(dir string) bool
+	dirIgnored = func(dir string) bool {
+		if v, ok := ignoredDirs[dir]; ok {
+			return v
+		}
+		parent := filepath.Dir(dir)
+		v := matchAny(ignore, filepath.ToSlash(dir), filepath.Base(dir), true) || (parent != dir && dirIgnored(parent))
+		ignoredDirs[dir] = v
+		return v
+	}
+
+	keep := func(e FileEntry) bool {
+		if !f.Match(e) {
+			return false
+		}
+		if text != "" && !strings.Contains(strings.ToLower(e.Name), text) {
+			return false
+		}
+		if len(roots) > 0 {
+			under := false
+			for _, r := range roots {
+				if underDir(e.Path, r) {
+					under = true
+					break
+				}
+			}
+			if !under {
+				return false
+			}
+		}
+		if len(ignore) > 0 && (matchAny(ignore, filepath.ToSlash(e.Path), e.Name, false) || dirIgnored(e.Dir)) {
+			return false
+		}
+		return true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	results := newestEntries(ctx, idx.files, idx.fileGrams, limit, keep)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return results, nil
+}
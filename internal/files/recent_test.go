@@ -0,0 +1,52 @@
+package files
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRecentFiles(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries([]FileEntry{
+		{Name: "old.txt", Path: "/h/docs/old.txt", Dir: "/h/docs", Ext: ".txt", ModTime: now.Add(-72 * time.Hour)},
+		{Name: "report.pdf", Path: "/h/docs/report.pdf", Dir: "/h/docs", Ext: ".pdf", ModTime: now.Add(-time.Hour)},
+		{Name: "movie.mp4.part", Path: "/h/dl/movie.mp4.part", Dir: "/h/dl", Ext: ".part", ModTime: now},
+		{Name: "setup.exe", Path: "/h/dl/setup.exe", Dir: "/h/dl", Ext: ".exe", ModTime: now.Add(-2 * time.Hour)},
+		{Name: "state.json", Path: "/h/cache/app/state.json", Dir: "/h/cache/app", Ext: ".json", ModTime: now.Add(-time.Minute)},
+	}, nil)
+
+	names := func(entries []FileEntry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+	opts := RecentOptions{Ignore: []string{"*.part", "cache/"}}
+
+	got, err := idx.RecentFiles(context.Background(), "", Filter{}, opts, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"report.pdf", "setup.exe", "old.txt"}; !slices.Equal(names(got), want) {
+		t.Errorf("recent = %v, want %v", names(got), want)
+	}
+
+	opts.Roots = []string{"/h/docs"}
+	got, _ = idx.RecentFiles(context.Background(), "", Filter{}, opts, 1)
+	if want := []string{"report.pdf"}; !slices.Equal(names(got), want) {
+		t.Errorf("recent under /h/docs = %v, want %v", names(got), want)
+	}
+
+	got, _ = idx.RecentFiles(context.Background(), "OLD", Filter{}, opts, 10)
+	if want := []string{"old.txt"}; !slices.Equal(names(got), want) {
+		t.Errorf("recent matching text = %v, want %v", names(got), want)
+	}
+
+	if _, err := idx.RecentFiles(context.Background(), "", Filter{}, RecentOptions{Ignore: []string{"re:("}}, 10); err == nil {
+		t.Error("expected an error for an invalid ignore pattern")
+	}
+}
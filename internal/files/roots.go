@@ -0,0 +1,144 @@
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Root is a folder the file index scans, with options that apply to
+// everything beneath it.
+type Root struct {
+	Path string `json:"path"`
+	// MaxDepth limits how many folder levels below Path are indexed. Direct
+	// children are at depth 1; 0 means unlimited.
+	MaxDepth int `json:"maxDepth,omitempty"`
+	// FollowSymlinks descends into symlinked folders. Links are otherwise
+	// indexed as files, like any other entry.
+	FollowSymlinks bool `json:"followSymlinks,omitempty"`
+	// IncludeHidden indexes dot-folders such as .config and what they hold,
+	// which are skipped by default. Dot-files (.bashrc) are always indexed.
+	IncludeHidden bool `json:"includeHidden,omitempty"`
+	// Only restricts the root to RootFilesOnly or RootFoldersOnly; empty
+	// indexes both.
+	Only string `json:"only,omitempty"`
+}
+
+const (
+	RootFilesOnly   = "files"
+	RootFoldersOnly = "folders"
+)
+
+// wants reports whether entries of the given kind are indexed under r.
+// Folders are still walked when r only indexes files, and vice versa.
+func (r Root) wants(isDir bool) bool {
+	if isDir {
+		return r.Only != RootFilesOnly
+	}
+	return r.Only != RootFoldersOnly
+}
+
+// RootStatus reports what the index holds for one root and what went wrong
+// the last time it was scanned.
+type RootStatus struct {
+	Path    string `json:"path"`
+	Files   int    `json:"files"`
+	Folders int    `json:"folders"`
+	// Unreadable counts subfolders that could not be listed, usually for lack
+	// of permission.
+	Unreadable int    `json:"unreadable,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// DefaultRoots returns the standard home folders that exist on this machine,
+// plus ~/Projects and ~/code if present.
+func DefaultRoots() []Root {
+	home, _ := os.UserHomeDir()
+	var roots []Root
+	for _, name := range []string{"Desktop", "Documents", "Downloads", "Pictures", "Videos", "Music", "Projects", "code"} {
+		if p := filepath.Join(home, name); dirExists(p) {
+			roots = append(roots, Root{Path: p})
+		}
+	}
+	return roots
+}
+
+// normalizeRoots expands "~", cleans paths and drops empty and duplicate
+// roots, keeping the first occurrence.
+func normalizeRoots(roots []Root) []Root {
+	seen := make(map[string]bool)
+	out := make([]Root, 0, len(roots))
+	for _, r := range roots {
+		if strings.TrimSpace(r.Path) == "" {
+			continue
+		}
+		r.Path = filepath.Clean(expandHome(r.Path))
+		key := r.Path
+		if runtime.GOOS == "windows" {
+			key = strings.ToLower(key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, r)
+	}
+	return out
+}
+
+// rootOf returns the deepest of roots that contains path, or a root at path's
+// parent when none does.
+func rootOf(roots []Root, path string) Root {
+	best := Root{}
+	for _, r := range roots {
+		if (path == r.Path || strings.HasPrefix(path, r.Path+string(filepath.Separator))) && len(r.Path) > len(best.Path) {
+			best = r
+		}
+	}
+	if best.Path == "" {
+		return Root{Path: filepath.Dir(path)}
+	}
+	return best
+}
+
+// rootResult is what the last scan learned about a root.
+type rootResult struct {
+	unreadable int
+	err        string
+}
+
+// RootStatuses reports per-root counts from the live index together with the
+// errors recorded by the last scan.
+func (idx *FileIndex) RootStatuses() []RootStatus {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	statuses := make([]RootStatus, len(idx.roots))
+	pos := make(map[string]int, len(idx.roots))
+	for i, r := range idx.roots {
+		statuses[i] = RootStatus{Path: r.Path}
+		pos[r.Path] = i
+		if res, ok := idx.rootResults[r.Path]; ok {
+			statuses[i].Unreadable = res.unreadable
+			statuses[i].Error = res.err
+		}
+	}
+	count := func(entries []FileEntry, grams *trigramIndex, isDir bool) {
+		for i, e := range entries {
+			if grams != nil && grams.isRemoved(i) {
+				continue
+			}
+			if n, ok := pos[rootOf(idx.roots, e.Path).Path]; ok {
+				if isDir {
+					statuses[n].Folders++
+				} else {
+					statuses[n].Files++
+				}
+			}
+		}
+	}
+	count(idx.files, idx.fileGrams, false)
+	count(idx.dirs, idx.dirGrams, true)
+	return statuses
+}
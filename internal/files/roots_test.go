@@ -0,0 +1,158 @@
+package files
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeRoots(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	got := normalizeRoots([]Root{
+		{Path: "~/notes/"},
+		{Path: "  "},
+		{Path: filepath.Join(home, "notes"), MaxDepth: 3},
+		{Path: "/data/./photos", IncludeHidden: true},
+	})
+	want := []Root{
+		{Path: filepath.Join(home, "notes")},
+		{Path: filepath.Clean("/data/photos"), IncludeHidden: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("normalizeRoots = %+v, want %+v", got, want)
+	}
+}
+
+func TestRoot_Options(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"a.txt":          "",
+		".profile":       "",
+		"sub/b.txt":      "",
+		".cache/c.txt":   "",
+		"sub/deep/d.txt": "",
+	})
+
+	cases := []struct {
+		name string
+		root Root
+		want []string
+	}{
+		{"defaults", Root{Path: root}, []string{".profile", "a.txt", "sub", "sub/b.txt", "sub/deep", "sub/deep/d.txt"}},
+		{"hidden", Root{Path: root, IncludeHidden: true, MaxDepth: 1}, []string{".cache", ".profile", "a.txt", "sub"}},
+	}
+	for _, c := range cases {
+		if got := walkPaths(t, Rules{}, c.root); !slices.Equal(got, c.want) {
+			t.Errorf("%s: walk = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestManualIndex_OnlyFilesOrFolders(t *testing.T) {
+	files, folders := t.TempDir(), t.TempDir()
+	makeTree(t, files, map[string]string{"sub/a.txt": ""})
+	makeTree(t, folders, map[string]string{"sub/b.txt": ""})
+
+	idx := NewFileIndex([]Root{
+		{Path: files, Only: RootFilesOnly},
+		{Path: folders, Only: RootFoldersOnly},
+	}, nil)
+	idx.cachePath = ""
+	if !idx.manualIndex(context.Background()) {
+		t.Fatal("scan did not complete")
+	}
+
+	statuses := idx.RootStatuses()
+	if statuses[0].Files != 1 || statuses[0].Folders != 0 {
+		t.Errorf("files-only root = %+v, want 1 file and no folders", statuses[0])
+	}
+	if statuses[1].Files != 0 || statuses[1].Folders != 1 {
+		t.Errorf("folders-only root = %+v, want 1 folder and no files", statuses[1])
+	}
+}
+
+func TestRootStatuses_ReportsErrors(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{"ok/a.txt": "", "locked/b.txt": ""})
+	missing := filepath.Join(root, "gone")
+	locked := filepath.Join(root, "locked")
+	if err := os.Chmod(locked, 0); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(locked, 0755)
+
+	idx := NewFileIndex([]Root{{Path: root}, {Path: missing}}, nil)
+	idx.cachePath = ""
+	idx.manualIndex(context.Background())
+
+	statuses := idx.RootStatuses()
+	if statuses[0].Folders != 2 || statuses[0].Error != "" {
+		t.Errorf("root status = %+v, want 2 folders and no error", statuses[0])
+	}
+	if _, err := os.ReadDir(locked); err != nil {
+		if statuses[0].Files != 1 || statuses[0].Unreadable != 1 {
+			t.Errorf("expected the locked folder to be counted as unreadable, got %+v", statuses[0])
+		}
+	}
+	if statuses[1].Error != "Folder not found" {
+		t.Errorf("missing root error = %q", statuses[1].Error)
+	}
+}
+
+func TestWalk_FollowSymlinksBreaksCycles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need elevated privileges on Windows")
+	}
+	root := t.TempDir()
+	other := t.TempDir()
+	makeTree(t, root, map[string]string{"a/x.txt": ""})
+	makeTree(t, other, map[string]string{"y.txt": ""})
+	if err := os.Symlink(root, filepath.Join(root, "a", "loop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(other, filepath.Join(root, "linked")); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := walkPaths(t, Rules{}, Root{Path: root}), []string{"a", "a/loop", "a/x.txt", "linked"}; !slices.Equal(got, want) {
+		t.Errorf("without following: walk = %v, want %v", got, want)
+	}
+	got := walkPaths(t, Rules{}, Root{Path: root, FollowSymlinks: true})
+	want := []string{"a", "a/x.txt", "linked", "linked/y.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("following: walk = %v, want %v", got, want)
+	}
+}
+
+func TestWalk_IndexesSymlinksAsFilesByDefault(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need elevated privileges on Windows")
+	}
+	root := t.TempDir()
+	dotfiles := t.TempDir()
+	makeTree(t, dotfiles, map[string]string{"bashrc": "export EDITOR=vi", "nvim/init.lua": ""})
+	if err := os.Symlink(filepath.Join(dotfiles, "bashrc"), filepath.Join(root, ".bashrc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dotfiles, "nvim"), filepath.Join(root, "nvim")); err != nil {
+		t.Fatal(err)
+	}
+
+	idx := NewFileIndex([]Root{{Path: root}}, nil)
+	idx.cachePath = ""
+	if !idx.manualIndex(context.Background()) {
+		t.Fatal("scan did not complete")
+	}
+	var got []string
+	for _, e := range idx.files {
+		got = append(got, e.Name)
+	}
+	slices.Sort(got)
+	// The linked folder is listed as a file but not descended into.
+	if want := []string{".bashrc", "nvim"}; !slices.Equal(got, want) {
+		t.Errorf("indexed files = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,497 @@
+package files
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Rules decides which paths the indexer visits. Patterns are globs unless
+// prefixed with "re:", in which case the rest is a regular expression matched
+// against the full slash-separated path. A glob containing a slash is matched
+// against the full path ("~/" expands to the home directory); otherwise it is
+// matched against the entry name. A trailing slash restricts a pattern to
+// folders, and "**" matches across path separators.
+//
+// A path is skipped when it matches an Exclude pattern or an ignore file and
+// no Include pattern. Skipping a folder skips everything beneath it. Hidden
+// entries and depth limits are per-root options; see Root.
+type Rules struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+	// IgnoreFiles honours .gitignore and .ignore files found during the walk.
+	IgnoreFiles bool `json:"ignoreFiles,omitempty"`
+}
+
+// DefaultRules skips the usual dependency, build and cache trees.
+func DefaultRules() Rules {
+	return Rules{
+		Exclude: []string{
+			"node_modules/", "__pycache__/", "vendor/",
+			"$RECYCLE.BIN/", "System Volume Information/",
+			"AppData/", "cache/", "Cache/",
+			"dist/", "build/", "target/",
+			"venv/", "env/",
+		},
+	}
+}
+
+// ignoreFileNames are read in order, so .ignore overrides .gitignore.
+var ignoreFileNames = []string{".gitignore", ".ignore"}
+
+// pattern is one compiled rule.
+type pattern struct {
+	re      *regexp.Regexp
+	full    bool // match against the full path rather than the name
+	dirOnly bool
+	negate  bool // ignore-file "!" patterns re-include what earlier lines excluded
+}
+
+func (p pattern) matches(slashPath, name string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.full {
+		return p.re.MatchString(slashPath)
+	}
+	return p.re.MatchString(name)
+}
+
+// ruleMatcher is the compiled form of Rules.
+type ruleMatcher struct {
+	source      Rules // what it was compiled from, recorded in the cache
+	include     []pattern
+	exclude     []pattern
+	ignoreFiles bool
+}
+
+func compileRules(r Rules) (*ruleMatcher, error) {
+	include, err := compilePatterns(r.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns(r.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &ruleMatcher{source: r, include: include, exclude: exclude, ignoreFiles: r.IgnoreFiles}, nil
+}
+
+func compilePatterns(patterns []string) ([]pattern, error) {
+	var out []pattern
+	for _, s := range patterns {
+		p, err := compileRule(s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// CheckPatterns returns an error for the first pattern, in the Rules.Exclude
+// syntax, that does not compile.
+func CheckPatterns(patterns []string) error {
+	_, err := compilePatterns(patterns)
+	return err
+}
+
+// compileRule compiles a single Include or Exclude pattern.
+func compileRule(s string) (pattern, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return pattern{}, fmt.Errorf("empty pattern")
+	}
+	if expr, ok := strings.CutPrefix(s, "re:"); ok {
+		re, err := regexp.Compile(caseFlag() + expr)
+		if err != nil {
+			return pattern{}, fmt.Errorf("invalid regular expression %q: %w", expr, err)
+		}
+		return pattern{re: re, full: true}, nil
+	}
+
+	var p pattern
+	if strings.HasSuffix(s, "/") && len(s) > 1 {
+		p.dirOnly = true
+		s = strings.TrimSuffix(s, "/")
+	}
+	if strings.Contains(s, "/") {
+		p.full = true
+		s = filepath.ToSlash(expandHome(s))
+		if !strings.HasPrefix(s, "/") && !isDriveAbs(s) {
+			s = "**/" + s
+		}
+	}
+	re, err := regexp.Compile(caseFlag() + "^" + globToRegexp(s) + "$")
+	if err != nil {
+		return pattern{}, fmt.Errorf("invalid pattern %q: %w", s, err)
+	}
+	p.re = re
+	return p, nil
+}
+
+// parseIgnoreFile compiles the patterns of a .gitignore-style file whose
+// directory is base. Anchored patterns (a leading or inner slash) are matched
+// relative to base; the rest match names at any depth.
+func parseIgnoreFile(path, base string) []pattern {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	prefix := filepath.ToSlash(base)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	var out []pattern
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p pattern
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			p.negate = true
+			line = rest
+		}
+		line = strings.TrimPrefix(line, `\`)
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		expr := globToRegexp(strings.TrimPrefix(line, "/"))
+		if strings.Contains(line, "/") {
+			p.full = true
+			expr = regexp.QuoteMeta(prefix) + expr
+		}
+		re, err := regexp.Compile(caseFlag() + "^" + expr + "$")
+		if err != nil {
+			continue
+		}
+		p.re = re
+		out = append(out, p)
+	}
+	return out
+}
+
+// globToRegexp translates a glob into an unanchored regular expression. "*"
+// and "?" stay within one path segment, "**" spans segments and "[...]" is a
+// character class.
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					// "**/" matches zero or more leading segments.
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				b.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				b.WriteString(regexp.QuoteMeta(string(glob[i])))
+			}
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// caseFlag makes patterns case-insensitive where the filesystem usually is.
+func caseFlag() string {
+	if runtime.GOOS == "windows" {
+		return "(?i)"
+	}
+	return ""
+}
+
+func isDriveAbs(slashPath string) bool {
+	return len(slashPath) >= 3 && slashPath[1] == ':' && slashPath[2] == '/'
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") || strings.HasPrefix(p, `~\`) {
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, p[1:])
+	}
+	return p
+}
+
+func matchAny(patterns []pattern, slashPath, name string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.matches(slashPath, name, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// ruleWalker applies a ruleMatcher and a root's options during a depth-first
+// walk, tracking the ignore files of the folders it is currently inside.
+type ruleWalker struct {
+	m     *ruleMatcher
+	root  Root
+	stack []ignoreFrame
+
+	visited    map[string]bool // real paths of followed symlink targets, to break cycles
+	unreadable int             // subfolders whose listing failed
+}
+
+type ignoreFrame struct {
+	dir      string
+	patterns []pattern
+}
+
+// walkerAt returns a walker for root positioned in dir, a folder at or
+// beneath root.Path, with the ignore files of every folder from the root down
+// to dir loaded.
+func (m *ruleMatcher) walkerAt(root Root, dir string) *ruleWalker {
+	w := &ruleWalker{m: m, root: root}
+	w.enter(root.Path)
+	rel, err := filepath.Rel(root.Path, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return w
+	}
+	cur := root.Path
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		cur = filepath.Join(cur, part)
+		w.enter(cur)
+	}
+	return w
+}
+
+// walk visits everything beneath start, the folder the walker was last
+// positioned in, calling fn for each file and folder the rules keep. fn may
+// return filepath.SkipAll to stop early. Folders are reported whether or not
+// the root indexes them (see Root.wants) since their contents may still be.
+// walk returns an error only if start itself cannot be listed.
+func (w *ruleWalker) walk(start string, fn func(path string, d fs.DirEntry) error) error {
+	if w.root.FollowSymlinks && w.visited == nil {
+		w.visited = make(map[string]bool)
+		if real, err := filepath.EvalSymlinks(start); err == nil {
+			w.visited[real] = true
+		}
+	}
+	if err := w.walkDir(start, fn); err != nil && err != filepath.SkipAll {
+		return err
+	}
+	return nil
+}
+
+func (w *ruleWalker) walkDir(dir string, fn func(path string, d fs.DirEntry) error) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range entries {
+		path := filepath.Join(dir, d.Name())
+		// Links are reported as files unless the root follows them, so
+		// linked folders are only descended into when asked.
+		if d.Type()&fs.ModeSymlink != 0 && w.root.FollowSymlinks {
+			target, ok := w.followLink(path)
+			if !ok {
+				continue
+			}
+			d = target
+		}
+
+		excluded, noDescend := w.skip(path, d.Name(), d.IsDir())
+		if excluded {
+			continue
+		}
+		if err := fn(path, d); err != nil {
+			return err
+		}
+		if !d.IsDir() || noDescend {
+			continue
+		}
+		w.enter(path)
+		if err := w.walkDir(path, fn); err == filepath.SkipAll {
+			return err
+		} else if err != nil {
+			w.unreadable++
+		}
+	}
+	return nil
+}
+
+// followLink resolves a symlink for a walk that follows them, refusing links
+// to folders already walked so a link to an ancestor cannot loop forever.
+func (w *ruleWalker) followLink(path string) (fs.DirEntry, bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if info.IsDir() {
+		real, err := filepath.EvalSymlinks(path)
+		if err != nil || w.visited[real] {
+			return nil, false
+		}
+		w.visited[real] = true
+	}
+	return fs.FileInfoToDirEntry(info), true
+}
+
+// enter records dir as the folder now being walked, loading its ignore files.
+// Call it for the root and for every folder the walk descends into.
+func (w *ruleWalker) enter(dir string) {
+	w.popTo(dir)
+	if !w.m.ignoreFiles {
+		return
+	}
+	var patterns []pattern
+	for _, name := range ignoreFileNames {
+		patterns = append(patterns, parseIgnoreFile(filepath.Join(dir, name), dir)...)
+	}
+	if len(patterns) > 0 {
+		w.stack = append(w.stack, ignoreFrame{dir: dir, patterns: patterns})
+	}
+}
+
+// popTo drops ignore files of folders the walk has left behind.
+func (w *ruleWalker) popTo(path string) {
+	for len(w.stack) > 0 {
+		top := w.stack[len(w.stack)-1].dir
+		if path == top || strings.HasPrefix(path, top+string(filepath.Separator)) {
+			return
+		}
+		w.stack = w.stack[:len(w.stack)-1]
+	}
+}
+
+// skip reports whether path (a child of the folder last entered) should be
+// left out of the index, and for folders whether to stop descending.
+func (w *ruleWalker) skip(path, name string, isDir bool) (excluded, noDescend bool) {
+	w.popTo(filepath.Dir(path))
+	if limit := w.root.MaxDepth; limit > 0 {
+		depth := pathDepth(w.root.Path, path)
+		if depth > limit {
+			return true, true
+		}
+		noDescend = isDir && depth == limit
+	}
+
+	slashPath := filepath.ToSlash(path)
+	if matchAny(w.m.include, slashPath, name, isDir) {
+		return false, noDescend
+	}
+	if !w.root.IncludeHidden && isDir && strings.HasPrefix(name, ".") {
+		return true, true
+	}
+	if matchAny(w.m.exclude, slashPath, name, isDir) {
+		return true, true
+	}
+	ignored := false
+	for _, f := range w.stack {
+		for _, p := range f.patterns {
+			if p.matches(slashPath, name, isDir) {
+				ignored = !p.negate
+			}
+		}
+	}
+	if ignored {
+		return true, true
+	}
+	return false, noDescend
+}
+
+// pathDepth counts the folder levels between root and path.
+func pathDepth(root, path string) int {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// RulePreview summarises what an exclude pattern would remove from the index.
+type RulePreview struct {
+	Files   int      `json:"files"`
+	Folders int      `json:"folders"`
+	Samples []string `json:"samples"` // the topmost excluded paths, at most previewSamples
+}
+
+const previewSamples = 50
+
+// PreviewExclude reports which indexed paths rule would exclude, counting
+// everything beneath a matching folder. Paths the current rules already skip
+// are not in the index and so are not reported.
+func (idx *FileIndex) PreviewExclude(rule string) (RulePreview, error) {
+	p, err := compileRule(rule)
+	if err != nil {
+		return RulePreview{}, err
+	}
+	allFiles, allDirs := idx.liveEntries()
+
+	matched := make(map[string]bool)
+	for _, d := range allDirs {
+		if p.matches(filepath.ToSlash(d.Path), d.Name, true) {
+			matched[d.Path] = true
+		}
+	}
+	// underMatch reports whether an ancestor folder of path matched, so path
+	// goes with it.
+	underMatch := func(path string) bool {
+		for dir := filepath.Dir(path); ; dir = filepath.Dir(dir) {
+			if matched[dir] {
+				return true
+			}
+			if parent := filepath.Dir(dir); parent == dir {
+				return false
+			}
+		}
+	}
+
+	preview := RulePreview{Samples: []string{}}
+	for _, list := range [][]FileEntry{allDirs, allFiles} {
+		for _, e := range list {
+			inherited := underMatch(e.Path)
+			if !inherited && !p.matches(filepath.ToSlash(e.Path), e.Name, e.IsDir) {
+				continue
+			}
+			if e.IsDir {
+				preview.Folders++
+			} else {
+				preview.Files++
+			}
+			if !inherited && len(preview.Samples) < previewSamples {
+				preview.Samples = append(preview.Samples, e.Path)
+			}
+		}
+	}
+	return preview, nil
+}
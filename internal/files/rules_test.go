@@ -0,0 +1,211 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCompileRule(t *testing.T) {
+	cases := []struct {
+		rule  string
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"*.tmp", "/home/u/a/scratch.tmp", false, true},
+		{"*.tmp", "/home/u/a/scratch.tmp.txt", false, false},
+		{"build/", "/home/u/proj/build", true, true},
+		{"build/", "/home/u/proj/build", false, false},
+		{".*/", "/home/u/.cache", true, true},
+		{"report-??.pdf", "/docs/report-01.pdf", false, true},
+		{"[ab]*.log", "/var/b-side.log", false, true},
+		{"[!ab]*.log", "/var/b-side.log", false, false},
+		{"proj/out", "/home/u/proj/out", true, true},
+		{"proj/out", "/home/u/other/out", true, false},
+		{"/home/u/**/logs", "/home/u/a/b/logs", true, true},
+		{"/home/u/**/logs", "/home/u/logs", true, true},
+		{"/home/u/*/logs", "/home/u/a/b/logs", true, false},
+		{`re:/\d{4}-\d{2}/`, "/photos/2024-07/img.jpg", false, true},
+		{`re:/\d{4}-\d{2}/`, "/photos/summer/img.jpg", false, false},
+	}
+	for _, c := range cases {
+		p, err := compileRule(c.rule)
+		if err != nil {
+			t.Fatalf("compileRule(%q): %v", c.rule, err)
+		}
+		if got := p.matches(c.path, filepath.Base(c.path), c.isDir); got != c.want {
+			t.Errorf("%q matches %q (dir=%v) = %v, want %v", c.rule, c.path, c.isDir, got, c.want)
+		}
+	}
+}
+
+func TestCompileRule_Invalid(t *testing.T) {
+	for _, rule := range []string{"", "   ", "re:(unclosed"} {
+		if _, err := compileRule(rule); err == nil {
+			t.Errorf("compileRule(%q): expected an error", rule)
+		}
+	}
+}
+
+// walkPaths returns the paths under root, relative to it, that the rules keep.
+func walkPaths(t *testing.T, r Rules, root Root) []string {
+	t.Helper()
+	m, err := compileRules(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	m.walkerAt(root, root.Path).walk(root.Path, func(path string, d fs.DirEntry) error {
+		rel, _ := filepath.Rel(root.Path, path)
+		got = append(got, filepath.ToSlash(rel))
+		return nil
+	})
+	return got
+}
+
+func makeTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRules_DefaultsMatchPreviousSkipList(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"app/main.go":             "",
+		"app/node_modules/x.js":   "",
+		"app/.git/config":         "",
+		"app/build/out.bin":       "",
+		"app/.env":                "",
+		"app/src/build.go":        "",
+		"app/src/vendor/v.go":     "",
+		"app/src/__pycache__/a.c": "",
+	})
+	got := walkPaths(t, DefaultRules(), Root{Path: root})
+	// Hidden folders are skipped, hidden files such as .env are not.
+	want := []string{"app", "app/.env", "app/main.go", "app/src", "app/src/build.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("walk = %v, want %v", got, want)
+	}
+}
+
+func TestRules_IncludeOverridesExclude(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"work/build/notes.md": "",
+		"other/build/x.o":     "",
+	})
+	r := DefaultRules()
+	r.Include = []string{filepath.ToSlash(filepath.Join(root, "work", "build"))}
+	got := walkPaths(t, r, Root{Path: root})
+	want := []string{"other", "work", "work/build", "work/build/notes.md"}
+	if !slices.Equal(got, want) {
+		t.Errorf("walk = %v, want %v", got, want)
+	}
+}
+
+func TestRules_IgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"repo/.gitignore":         "# generated\n*.log\n/out/\ndocs/*.pdf\n!keep.log\n",
+		"repo/a.log":              "",
+		"repo/keep.log":           "",
+		"repo/out/bin":            "",
+		"repo/src/out/gen.go":     "",
+		"repo/docs/spec.pdf":      "",
+		"repo/docs/spec.md":       "",
+		"repo/sub/.ignore":        "*.md\n",
+		"repo/sub/readme.md":      "",
+		"repo/sub/deep/trace.log": "",
+		"sibling/readme.md":       "",
+		"sibling/untouched.log":   "",
+	})
+
+	r := Rules{IgnoreFiles: true}
+	got := walkPaths(t, r, Root{Path: root})
+	want := []string{
+		"repo", "repo/.gitignore", "repo/docs", "repo/docs/spec.md", "repo/keep.log",
+		"repo/src", "repo/src/out", "repo/src/out/gen.go",
+		"repo/sub", "repo/sub/.ignore", "repo/sub/deep",
+		"sibling", "sibling/readme.md", "sibling/untouched.log",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("walk = %v\nwant   %v", got, want)
+	}
+
+	if all := walkPaths(t, Rules{}, Root{Path: root}); len(all) <= len(got) {
+		t.Error("ignore files should only apply when IgnoreFiles is set")
+	}
+}
+
+func TestRules_MaxDepth(t *testing.T) {
+	root := t.TempDir()
+	makeTree(t, root, map[string]string{
+		"top.txt":      "",
+		"a/mid.txt":    "",
+		"a/b/deep.txt": "",
+		"a/b/c/x.txt":  "",
+	})
+	got := walkPaths(t, Rules{}, Root{Path: root, MaxDepth: 2})
+	want := []string{"a", "a/b", "a/mid.txt", "top.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("walk = %v, want %v", got, want)
+	}
+}
+
+func TestApplyChanges_RespectsRules(t *testing.T) {
+	root := t.TempDir()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(nil, nil)
+	idx.roots = []Root{{Path: root}}
+	if err := idx.SetRules(Rules{Exclude: []string{"*.tmp", "cache/"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	makeTree(t, root, map[string]string{"a.tmp": "", "a.txt": "", "cache/c.txt": ""})
+	idx.applyChanges([]string{
+		filepath.Join(root, "a.tmp"), filepath.Join(root, "a.txt"), filepath.Join(root, "cache"),
+	}, newFakeWatcher(0))
+
+	files := idx.Files()
+	if len(files) != 1 || files[0].Name != "a.txt" {
+		t.Errorf("expected only a.txt to be indexed, got %v", files)
+	}
+}
+
+func TestPreviewExclude(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries([]FileEntry{
+		{Name: "a.go", Path: "/p/gen/a.go", Dir: "/p/gen"},
+		{Name: "b.go", Path: "/p/gen/sub/b.go", Dir: "/p/gen/sub"},
+		{Name: "main.go", Path: "/p/main.go", Dir: "/p"},
+	}, []FileEntry{
+		{Name: "gen", Path: "/p/gen", Dir: "/p", IsDir: true},
+		{Name: "sub", Path: "/p/gen/sub", Dir: "/p/gen", IsDir: true},
+	})
+
+	got, err := idx.PreviewExclude("gen/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Files != 2 || got.Folders != 2 {
+		t.Errorf("counts = %d files, %d folders; want 2, 2", got.Files, got.Folders)
+	}
+	if !slices.Equal(got.Samples, []string{"/p/gen"}) {
+		t.Errorf("samples = %v, want only the topmost match", got.Samples)
+	}
+
+	if _, err := idx.PreviewExclude("re:("); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,351 @@
+package files
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"blight/internal/search"
+)
+
+// trigram packs three lowercase runes into one map key, first rune in the
+// high bits so every trigram sharing a two-rune prefix sorts contiguously.
+type trigram uint64
+
+const (
+	runeBits = 21
+	runeMask = 1<<runeBits - 1
+	// endRune terminates every indexed name so a bigram at the end of a name
+	// still appears as the prefix of some trigram.
+	endRune = 0
+)
+
+func makeTrigram(a, b, c rune) trigram {
+	return trigram(uint64(a&runeMask)<<(2*runeBits) | uint64(b&runeMask)<<runeBits | uint64(c&runeMask))
+}
+
+// trigramIndex is an inverted index from name trigrams to the positions of the
+// names that contain them. It narrows the candidate set before fuzzy scoring
+// so a keystroke does not have to score every indexed name.
+type trigramIndex struct {
+	postings map[trigram][]int32
+	keys     []trigram // sorted, for two-rune prefix lookups
+	unsorted bool      // keys gained entries since the last sortKeys
+	folded   []string  // names folded with search.Fold, scored without re-folding
+	masks    []uint64  // per-name character-class bitmask for fuzzy prefiltering
+	removed  []uint64  // bitset of positions deleted since the index was built
+	dead     int       // number of set bits in removed
+	size     int
+
+	// Indexes built by newEntryIndex also locate entries by their path:
+	// folders indexes the distinct parent folders of the entries, folded
+	// and ending in a separator, folderOf holds each entry's folder and
+	// members the positions in each folder.
+	folders   *trigramIndex
+	folderIDs map[string]int32
+	folderOf  []int32
+	members   [][]int32
+}
+
+func newTrigramIndex(names []string) *trigramIndex {
+	t := &trigramIndex{
+		postings: make(map[trigram][]int32, len(names)/4),
+		folded:   make([]string, 0, len(names)),
+		masks:    make([]uint64, 0, len(names)),
+	}
+	for _, name := range names {
+		t.add(name)
+	}
+	t.sortKeys()
+	return t
+}
+
+// newEntryIndex indexes the names of entries like newTrigramIndex, and also
+// their paths, for pathCandidates.
+func newEntryIndex(entries []FileEntry) *trigramIndex {
+	t := &trigramIndex{
+		postings:  make(map[trigram][]int32, len(entries)/4),
+		folded:    make([]string, 0, len(entries)),
+		masks:     make([]uint64, 0, len(entries)),
+		folderOf:  make([]int32, 0, len(entries)),
+		folders:   newTrigramIndex(nil),
+		folderIDs: make(map[string]int32),
+	}
+	for _, e := range entries {
+		t.addEntry(e)
+	}
+	t.sortKeys()
+	t.packFolded()
+	t.folders.packFolded()
+	return t
+}
+
+// packFolded moves the folded names into one backing string. Candidates are
+// scored in position order, so a query then reads names sequentially instead
+// of chasing an allocation per name across the heap.
+func (t *trigramIndex) packFolded() {
+	var b strings.Builder
+	for _, name := range t.folded {
+		b.WriteString(name)
+	}
+	packed := b.String()
+	off := 0
+	for i, name := range t.folded {
+		t.folded[i] = packed[off : off+len(name)]
+		off += len(name)
+	}
+}
+
+// addEntry indexes e's name and path at the next position. Like add, it
+// needs a sortKeys after a batch.
+func (t *trigramIndex) addEntry(e FileEntry) {
+	pos := int32(t.size)
+	t.add(e.Name)
+	path := search.Fold(e.Path)
+
+	folder := path[:strings.LastIndexAny(path, `/\`)+1]
+	id, ok := t.folderIDs[folder]
+	if !ok {
+		id = int32(len(t.members))
+		t.folderIDs[folder] = id
+		t.members = append(t.members, nil)
+		t.folders.add(folder)
+	}
+	t.folderOf = append(t.folderOf, id)
+	t.members[id] = append(t.members[id], pos)
+}
+
+// add indexes name at the next position. Positions only grow, so every
+// posting list stays sorted without re-sorting. Call sortKeys after a batch of
+// adds so two-rune lookups see any new trigrams.
+func (t *trigramIndex) add(name string) {
+	pos := int32(t.size)
+	t.size++
+	folded := search.Fold(name)
+	t.folded = append(t.folded, folded)
+	t.masks = append(t.masks, charMask(folded))
+	if len(t.removed)*64 < t.size {
+		t.removed = append(t.removed, 0)
+	}
+
+	runes := append([]rune(folded), endRune)
+	for i := 0; i+2 < len(runes); i++ {
+		g := makeTrigram(runes[i], runes[i+1], runes[i+2])
+		list := t.postings[g]
+		if n := len(list); n > 0 && list[n-1] == pos {
+			continue // trigram repeats within this name
+		}
+		if len(list) == 0 {
+			t.keys = append(t.keys, g)
+			t.unsorted = true
+		}
+		t.postings[g] = append(list, pos)
+	}
+}
+
+// remove marks pos as deleted. Its postings stay in place; searches skip it
+// until the owner rebuilds the index.
+func (t *trigramIndex) remove(pos int) {
+	if !t.isRemoved(pos) {
+		t.removed[pos/64] |= 1 << (uint(pos) % 64)
+		t.dead++
+	}
+}
+
+func (t *trigramIndex) isRemoved(pos int) bool {
+	return t.removed[pos/64]&(1<<(uint(pos)%64)) != 0
+}
+
+func (t *trigramIndex) sortKeys() {
+	if t.unsorted {
+		slices.Sort(t.keys)
+		t.unsorted = false
+	}
+	if t.folders != nil {
+		t.folders.sortKeys()
+	}
+}
+
+// positionsOf returns the live positions whose name equals name, ignoring
+// case and diacritics.
+func (t *trigramIndex) positionsOf(name string) []int32 {
+	folded := search.Fold(name)
+	var cands []int32
+	if utf8.RuneCountInString(folded) >= 2 {
+		cands = t.termCandidates(folded)
+	} else {
+		for i := range t.folded {
+			cands = append(cands, int32(i))
+		}
+	}
+	var out []int32
+	for _, p := range cands {
+		if t.folded[p] == folded && !t.isRemoved(int(p)) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// candidates returns the positions of names that contain every term of query
+// as a substring, ignoring case and diacritics. ok is false when the query has no term
+// long enough to use the index, in which case the caller must scan.
+func (t *trigramIndex) candidates(query string) (positions []int32, ok bool) {
+	var all []uint64
+	for _, term := range strings.Fields(search.Fold(query)) {
+		if utf8.RuneCountInString(term) < 2 {
+			continue
+		}
+		set := t.termSet(term)
+		if set == nil {
+			return nil, true
+		}
+		all = andSet(all, set)
+	}
+	if all == nil {
+		return nil, false
+	}
+	return setPositions(all), true
+}
+
+// pathCandidates returns the positions whose folded path may contain every
+// one of terms, each at least two runes: for each term, the names that do
+// and every entry of the folders that do. The index must come from
+// newEntryIndex.
+func (t *trigramIndex) pathCandidates(terms []string) []int32 {
+	var all []uint64
+	for _, term := range terms {
+		set := t.termSet(term)
+		if set == nil {
+			set = t.newSet()
+		}
+		for _, f := range t.folders.termCandidates(term) {
+			addToSet(set, t.members[f])
+		}
+		all = andSet(all, set)
+	}
+	return setPositions(all)
+}
+
+// termCandidates returns the positions of names containing term, which has
+// at least two runes.
+func (t *trigramIndex) termCandidates(term string) []int32 {
+	return setPositions(t.termSet(term))
+}
+
+// termSet returns a bitset of the names containing term, which has at least
+// two runes, or nil when none can. A two-rune term unions the postings of
+// every trigram it prefixes; a longer one intersects the postings of its
+// trigrams, starting from the shortest list.
+func (t *trigramIndex) termSet(term string) []uint64 {
+	runes := []rune(term)
+	var lists [][]int32
+	if len(runes) == 2 {
+		lo := makeTrigram(runes[0], runes[1], 0)
+		hi := makeTrigram(runes[0], runes[1], runeMask)
+		start, _ := slices.BinarySearch(t.keys, lo)
+		for i := start; i < len(t.keys) && t.keys[i] <= hi; i++ {
+			lists = append(lists, t.postings[t.keys[i]])
+		}
+		if len(lists) == 0 {
+			return nil
+		}
+		set := t.newSet()
+		for _, l := range lists {
+			addToSet(set, l)
+		}
+		return set
+	}
+	for i := 0; i+2 < len(runes); i++ {
+		list, found := t.postings[makeTrigram(runes[i], runes[i+1], runes[i+2])]
+		if !found {
+			return nil
+		}
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+	set := t.newSet()
+	addToSet(set, lists[0])
+	if len(lists) == 1 {
+		return set
+	}
+	scratch := t.newSet()
+	for _, l := range lists[1:] {
+		clear(scratch)
+		addToSet(scratch, l)
+		andSet(set, scratch)
+	}
+	return set
+}
+
+// newSet returns an empty bitset with room for every position.
+func (t *trigramIndex) newSet() []uint64 {
+	return make([]uint64, (t.size+63)/64)
+}
+
+// addToSet sets the bits of positions in set.
+func addToSet(set []uint64, positions []int32) {
+	for _, p := range positions {
+		set[p/64] |= 1 << (uint(p) % 64)
+	}
+}
+
+// andSet intersects set into all and returns all, or returns set when all is
+// nil so the first term's set starts the intersection.
+func andSet(all, set []uint64) []uint64 {
+	if all == nil {
+		return set
+	}
+	for i := range all {
+		all[i] &= set[i]
+	}
+	return all
+}
+
+// setPositions lists the positions set in a bitset, in order.
+func setPositions(set []uint64) []int32 {
+	n := 0
+	for _, word := range set {
+		n += bits.OnesCount64(word)
+	}
+	if n == 0 {
+		return nil
+	}
+	out := make([]int32, 0, n)
+	for w, word := range set {
+		for word != 0 {
+			bit := word & -word
+			out = append(out, int32(w*64+bits.TrailingZeros64(bit)))
+			word &^= bit
+		}
+	}
+	return out
+}
+
+// mayFuzzyMatch reports whether the name at pos contains every character class
+// of query, a cheap necessary condition for a subsequence match.
+func (t *trigramIndex) mayFuzzyMatch(pos int, queryMask uint64) bool {
+	return t.masks[pos]&queryMask == queryMask
+}
+
+// charMask maps letters and digits of a folded s to bits 0-35 and hashes
+// every other rune into bits 36-63, so names in other scripts still filter
+// on most of their characters rather than sharing a single bit.
+func charMask(s string) uint64 {
+	var m uint64
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			m |= 1 << uint(r-'a')
+		case r >= '0' && r <= '9':
+			m |= 1 << uint(26+r-'0')
+		case r == ' ' || r == '-' || r == '_' || r == '.':
+			// Separators are optional in fuzzy matches.
+		default:
+			m |= 1 << uint(36+r%28)
+		}
+	}
+	return m
+}
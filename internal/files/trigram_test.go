@@ -0,0 +1,259 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"blight/internal/search"
+)
+
+func TestTrigramIndex_SubstringCandidates(t *testing.T) {
+	names := []string{"Quarterly Report.pdf", "report-draft.docx", "main.go", "Reporter.app"}
+	cands, ok := newTrigramIndex(names).candidates("report")
+	if !ok {
+		t.Fatal("expected the index to be usable for a 6-rune query")
+	}
+	want := []int32{0, 1, 3}
+	if !slices.Equal(cands, want) {
+		t.Errorf("candidates = %v, want %v", cands, want)
+	}
+}
+
+func TestTrigramIndex_BigramMatchesEndOfName(t *testing.T) {
+	names := []string{"archive.gz", "gzip.txt", "notes.md"}
+	cands, ok := newTrigramIndex(names).candidates("gz")
+	if !ok {
+		t.Fatal("expected the index to be usable for a 2-rune query")
+	}
+	want := []int32{0, 1}
+	if !slices.Equal(cands, want) {
+		t.Errorf("candidates = %v, want %v", cands, want)
+	}
+}
+
+func TestTrigramIndex_MultiTermIntersects(t *testing.T) {
+	names := []string{"docs report.pdf", "report.pdf", "docs.zip"}
+	cands, _ := newTrigramIndex(names).candidates("report docs")
+	if !slices.Equal(cands, []int32{0}) {
+		t.Errorf("candidates = %v, want [0]", cands)
+	}
+}
+
+func TestTrigramIndex_PathCandidatesIntersectTerms(t *testing.T) {
+	var entries []FileEntry
+	for _, p := range []string{
+		"/h/Invoices/2023/march.pdf", // both terms in folders
+		"/h/Invoices/april.pdf",      // no 2023
+		"/h/tax/invoice-2023.pdf",    // both in the name
+		"/h/2023/notes.txt",          // no invoice
+	} {
+		entries = append(entries, FileEntry{Name: filepath.Base(p), Path: p, Dir: filepath.Dir(p)})
+	}
+	idx := newEntryIndex(entries)
+	if got := idx.pathCandidates([]string{"invoice", "2023"}); !slices.Equal(got, []int32{0, 2}) {
+		t.Errorf("pathCandidates = %v, want [0 2]", got)
+	}
+	idx.addEntry(FileEntry{Name: "may.pdf", Path: "/h/Invoices/2023/may.pdf", Dir: "/h/Invoices/2023"})
+	idx.sortKeys()
+	if got := idx.pathCandidates([]string{"invoice", "2023"}); !slices.Equal(got, []int32{0, 2, 4}) {
+		t.Errorf("after addEntry, pathCandidates = %v, want [0 2 4]", got)
+	}
+}
+
+func TestTrigramIndex_ShortQueryNotIndexed(t *testing.T) {
+	if _, ok := newTrigramIndex([]string{"a.txt"}).candidates("a"); ok {
+		t.Error("single-rune queries should fall back to a scan")
+	}
+}
+
+func TestTrigramIndex_NonASCII(t *testing.T) {
+	names := []string{"Résumé.pdf", "resume.txt", "履歴書.docx"}
+	idx := newTrigramIndex(names)
+	for _, q := range []string{"résumé", "resume", "RESUMÉ"} {
+		if cands, _ := idx.candidates(q); !slices.Equal(cands, []int32{0, 1}) {
+			t.Errorf("candidates(%q) = %v, want both spellings", q, cands)
+		}
+	}
+	if cands, _ := idx.candidates("履歴"); !slices.Equal(cands, []int32{2}) {
+		t.Errorf("candidates(履歴) = %v, want [2]", cands)
+	}
+}
+
+func TestCharMask_NonASCII(t *testing.T) {
+	if charMask("履歴書") == charMask("写真") {
+		t.Error("expected different scripts' runes to spread over distinct bits")
+	}
+	if m := charMask(search.Fold("Café")); m != charMask("cafe") {
+		t.Errorf("folded mask = %b, want the mask of cafe", m)
+	}
+}
+
+func TestSearchFiles_FallsBackToFuzzyMatches(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries([]FileEntry{
+		{Name: "visual_studio_code.desktop", Path: "/a/visual_studio_code.desktop"},
+		{Name: "notes.txt", Path: "/a/notes.txt"},
+	}, nil)
+	got := idx.SearchFiles(context.Background(), "vsc", nil)
+	if len(got) != 1 || got[0].Name != "visual_studio_code.desktop" {
+		t.Errorf("expected acronym match via fuzzy fallback, got %v", got)
+	}
+}
+
+func TestSearchFiles_MatchesLinearScan(t *testing.T) {
+	files := syntheticCorpus(20_000)
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(files, nil)
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	for _, q := range []string{"report", "invoice 2023", "png", "budget_final"} {
+		got := idx.SearchFiles(context.Background(), q, nil)
+		want := searchEntries(context.Background(), q, Filter{}, files, names, nil, nil, 15)
+		if len(got) != len(want) {
+			t.Errorf("%q: indexed search returned %d results, linear scan %d", q, len(got), len(want))
+		}
+	}
+}
+
+func TestSearchFiles_TwoRuneQueriesMatchSubstrings(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries([]FileEntry{
+		{Name: "report.pdf", Path: "/h/report.pdf"},
+		{Name: "rp-notes.txt", Path: "/h/rp-notes.txt"},
+	}, nil)
+	names := func(q string) []string {
+		var out []string
+		for _, e := range idx.SearchFiles(context.Background(), q, nil) {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+	// Two runes skip the fuzzy fallback; three runes reach it.
+	if got := names("rp"); !slices.Equal(got, []string{"rp-notes.txt"}) {
+		t.Errorf("rp = %q, want only the name containing it", got)
+	}
+	if got := names("rpt"); !slices.Contains(got, "report.pdf") {
+		t.Errorf("rpt = %q, want the fuzzy match report.pdf", got)
+	}
+}
+
+func TestSearchFiles_CancelledContext(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(syntheticCorpus(10_000), nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if got := idx.SearchFiles(ctx, "report", nil); got != nil {
+		t.Errorf("expected nil for a cancelled query, got %d results", len(got))
+	}
+}
+
+var corpusWords = []string{
+	"report", "invoice", "budget", "final", "draft", "notes", "meeting", "photo",
+	"screenshot", "backup", "config", "readme", "main", "index", "test", "design",
+	"résumé", "project", "blight", "summary", "data", "export", "archive", "old",
+}
+
+var corpusExts = []string{".pdf", ".docx", ".txt", ".md", ".go", ".png", ".jpg", ".zip", ".json", ".csv"}
+
+// syntheticCorpus builds n deterministic file entries with realistic names.
+func syntheticCorpus(n int) []FileEntry {
+	r := rand.New(rand.NewSource(1))
+	files := make([]FileEntry, n)
+	for i := range files {
+		parts := make([]string, 1+r.Intn(3))
+		for j := range parts {
+			parts[j] = corpusWords[r.Intn(len(corpusWords))]
+		}
+		seps := []string{"_", "-", " ", ""}
+		name := strings.Join(parts, seps[r.Intn(len(seps))])
+		if r.Intn(2) == 0 {
+			name += fmt.Sprintf("%d", 2000+r.Intn(30))
+		}
+		name += corpusExts[r.Intn(len(corpusExts))]
+		dir := filepath.Join("/home/user", corpusWords[r.Intn(len(corpusWords))], fmt.Sprintf("d%d", r.Intn(500)))
+		files[i] = FileEntry{Name: name, Path: filepath.Join(dir, name), Dir: dir, Ext: filepath.Ext(name)}
+	}
+	return files
+}
+
+func benchmarkIndex(b *testing.B) *FileIndex {
+	b.Helper()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(syntheticCorpus(maxIndexFiles), nil)
+	// Collect the corpus garbage now so it does not land in a timed query.
+	runtime.GC()
+	return idx
+}
+
+func BenchmarkSearchFiles_500k(b *testing.B) {
+	idx := benchmarkIndex(b)
+	for _, q := range []string{"report", "invoice 2023", "budget_final.pdf", "rp", "blight summary", "notes d42 2011", "export/draft"} {
+		b.Run(q, func(b *testing.B) {
+			var worst time.Duration
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				idx.SearchFiles(context.Background(), q, nil)
+				worst = max(worst, time.Since(start))
+			}
+			b.ReportMetric(float64(worst.Microseconds())/1000, "worst-ms")
+		})
+	}
+}
+
+func BenchmarkSearchFiles_500kLinearScan(b *testing.B) {
+	files := syntheticCorpus(maxIndexFiles)
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchEntries(context.Background(), "report", Filter{}, files, names, nil, nil, 15)
+	}
+}
+
+func BenchmarkTrigramIndexBuild_500k(b *testing.B) {
+	files := syntheticCorpus(maxIndexFiles)
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Name
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newTrigramIndex(names)
+	}
+}
+
+func TestSearchFiles_PathQuery(t *testing.T) {
+	idx := NewFileIndex(nil, nil)
+	var entries []FileEntry
+	for _, dir := range []string{"/h/proj/blight", "/h/proj/other", "/h/scratch", "/h/proj/blight/cmd"} {
+		entries = append(entries, FileEntry{Name: "main.go", Path: dir + "/main.go", Dir: dir})
+	}
+	entries = append(entries, FileEntry{Name: "notes.txt", Path: "/h/proj/blight/main/notes.txt", Dir: "/h/proj/blight/main"})
+	idx.setEntries(entries, nil)
+
+	got := idx.SearchFiles(context.Background(), "blight/main", nil)
+	var paths []string
+	for _, e := range got {
+		paths = append(paths, e.Path)
+	}
+	want := []string{"/h/proj/blight/main.go", "/h/proj/blight/cmd/main.go"}
+	if !slices.Equal(paths, want) {
+		t.Errorf("blight/main = %v, want %v", paths, want)
+	}
+
+	got = idx.SearchFiles(context.Background(), "scratch main", nil)
+	if len(got) == 0 || got[0].Path != "/h/scratch/main.go" {
+		t.Errorf("expected the file in scratch first, got %v", got)
+	}
+}
@@ -0,0 +1,423 @@
+package files
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"blight/internal/debug"
+)
+
+// Change is a filesystem event reported by a Watcher. Renames arrive as a
+// Remove of the old path and a Create of the new one.
+type Change struct {
+	Op   ChangeOp
+	Path string
+}
+
+type ChangeOp int
+
+const (
+	ChangeCreate ChangeOp = iota
+	ChangeRemove
+	// ChangeWrite means a file was written in place and closed.
+	ChangeWrite
+	// ChangeOverflow means events were dropped and the index can no longer be
+	// trusted; Path is empty.
+	ChangeOverflow
+)
+
+// Watcher reports changes to the direct children of watched directories.
+// Implementations are platform specific; see newPlatformWatcher.
+type Watcher interface {
+	// Add starts watching dir. It returns ErrWatchLimit once the platform
+	// refuses further watches.
+	Add(dir string) error
+	Changes() <-chan Change
+	Close() error
+}
+
+// ErrWatchLimit is returned by Watcher.Add when the OS watch quota is spent.
+var ErrWatchLimit = errors.New("filesystem watch limit reached")
+
+// errWatchUnsupported is returned by newPlatformWatcher on platforms without a
+// Watcher implementation.
+var errWatchUnsupported = errors.New("filesystem watching not supported on this platform")
+
+// newWatcher is swapped out by tests.
+var newWatcher = newPlatformWatcher
+
+var (
+	// Events are applied once the filesystem has been quiet for coalesceQuiet,
+	// or coalesceMax after the first pending event during a sustained storm.
+	coalesceQuiet = 300 * time.Millisecond
+	coalesceMax   = 2 * time.Second
+	// rescanInterval is how often the index is rebuilt when live watching is
+	// unavailable.
+	rescanInterval = 30 * time.Minute
+	// cacheSaveInterval bounds how often live changes are written to disk.
+	cacheSaveInterval = time.Minute
+)
+
+// Watch keeps the current index up to date without a rescan, e.g. after it
+// was restored by Load. It replaces any scan or watch already running.
+func (idx *FileIndex) Watch() {
+	idx.stopCurrent()
+	ctx, cancel := context.WithCancel(context.Background())
+	idx.cancelFn.Store(cancel)
+	go idx.watch(ctx)
+}
+
+// watch applies filesystem changes under the scan roots to the index until
+// ctx is cancelled. If the platform has no watcher or the watch quota runs
+// out, it falls back to rebuilding the index every rescanInterval.
+func (idx *FileIndex) watch(ctx context.Context) {
+	log := debug.Get()
+	// The file list is final from here on, so the content index can be
+	// filled from it.
+	idx.rebuildContent()
+
+	w, err := newWatcher()
+	if err != nil {
+		log.Info("live file indexing unavailable, rescanning periodically", map[string]interface{}{"error": err.Error()})
+		idx.rescanPeriodically(ctx)
+		return
+	}
+	defer w.Close()
+
+	dirs := idx.watchDirs()
+
+	for _, d := range dirs {
+		if err := w.Add(d); errors.Is(err, ErrWatchLimit) {
+			log.Warn("file watch limit reached, rescanning periodically", map[string]interface{}{"watched": d, "dirs": len(dirs)})
+			w.Close()
+			idx.rescanPeriodically(ctx)
+			return
+		}
+	}
+	log.Debug("live file indexing started", map[string]interface{}{"dirs": len(dirs)})
+
+	pending := make(map[string]struct{})
+	var first time.Time
+	flush := time.NewTimer(time.Hour)
+	flush.Stop()
+	defer flush.Stop()
+	saveTick := time.NewTicker(cacheSaveInterval)
+	defer saveTick.Stop()
+	dirty := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case c, ok := <-w.Changes():
+			if !ok {
+				return
+			}
+			if c.Op == ChangeOverflow {
+				log.Warn("file watch queue overflowed, reindexing")
+				go idx.Reindex()
+				return
+			}
+			pending[c.Path] = struct{}{}
+			now := time.Now()
+			if first.IsZero() {
+				first = now
+			}
+			flush.Reset(min(coalesceQuiet, first.Add(coalesceMax).Sub(now)))
+
+		case <-flush.C:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			clear(pending)
+			first = time.Time{}
+			if err := idx.applyChanges(paths, w); errors.Is(err, ErrWatchLimit) {
+				log.Warn("file watch limit reached, rescanning periodically")
+				w.Close()
+				idx.rescanPeriodically(ctx)
+				return
+			}
+			dirty = true
+
+		case <-saveTick.C:
+			if dirty {
+				dirty = false
+				if err := idx.save(); err != nil {
+					log.Error("failed to save file index cache", map[string]interface{}{"path": idx.cachePath, "error": err.Error()})
+				}
+			}
+		}
+	}
+}
+
+// watchDirs lists every folder to watch: the roots, the indexed folders, and
+// for roots that only index files, the folders beneath them found by a fresh
+// walk.
+func (idx *FileIndex) watchDirs() []string {
+	roots := idx.scanRoots()
+	idx.mu.RLock()
+	rules := idx.rules
+	var dirs []string
+	for _, r := range roots {
+		dirs = append(dirs, r.Path)
+	}
+	for i, d := range idx.dirs {
+		if idx.dirGrams == nil || !idx.dirGrams.isRemoved(i) {
+			dirs = append(dirs, d.Path)
+		}
+	}
+	idx.mu.RUnlock()
+
+	for _, r := range roots {
+		if r.Only != RootFilesOnly {
+			continue
+		}
+		rules.walkerAt(r, r.Path).walk(r.Path, func(path string, d fs.DirEntry) error {
+			if d.IsDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+	}
+	return dirs
+}
+
+// rescanPeriodically rebuilds the index every rescanInterval until ctx is
+// cancelled.
+func (idx *FileIndex) rescanPeriodically(ctx context.Context) {
+	t := time.NewTicker(rescanInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			// Rescan under the same ctx so Reindex or CancelIndex stop it.
+			if idx.manualIndex(ctx) {
+				idx.rebuildContent()
+			}
+		}
+	}
+}
+
+// applyChanges re-reads each changed path: whatever the index held for it
+// (including everything beneath a directory) is dropped, and whatever now
+// exists on disk is added back, with new directories walked and watched. This
+// makes creates, deletes and both halves of a rename the same operation, so a
+// coalesced batch only needs the set of paths that changed. A file that is
+// still indexed at the same path, such as one saved in place, keeps its entry
+// and only has its size and modification time refreshed.
+func (idx *FileIndex) applyChanges(paths []string, w Watcher) error {
+	roots := idx.scanRoots()
+	idx.mu.RLock()
+	rules := idx.rules
+	idx.mu.RUnlock()
+
+	var added []FileEntry
+	var newDirs []string
+	for _, p := range paths {
+		root := rootOf(roots, p)
+		info, err := os.Lstat(p)
+		if err != nil {
+			continue
+		}
+		if info.Mode()&fs.ModeSymlink != 0 && root.FollowSymlinks {
+			if info, err = os.Stat(p); err != nil {
+				continue
+			}
+		}
+		name := filepath.Base(p)
+		walker := rules.walkerAt(root, filepath.Dir(p))
+		excluded, noDescend := walker.skip(p, name, info.IsDir())
+		if excluded {
+			continue
+		}
+		if !info.IsDir() {
+			if root.wants(false) {
+				added = append(added, fileEntry(p, name, info))
+			}
+			continue
+		}
+		if root.wants(true) {
+			added = append(added, dirEntry(p, name, info))
+		}
+		newDirs = append(newDirs, p)
+		if noDescend {
+			continue
+		}
+		walker.enter(p)
+		walker.walk(p, func(path string, d fs.DirEntry) error {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if d.IsDir() {
+				newDirs = append(newDirs, path)
+			}
+			if root.wants(d.IsDir()) {
+				if d.IsDir() {
+					added = append(added, dirEntry(path, d.Name(), info))
+				} else {
+					added = append(added, fileEntry(path, d.Name(), info))
+				}
+			}
+			return nil
+		})
+	}
+
+	var watchErr error
+	for _, d := range newDirs {
+		if err := w.Add(d); errors.Is(err, ErrWatchLimit) {
+			watchErr = err
+			break
+		}
+	}
+
+	idx.mu.Lock()
+	changed := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		changed[p] = true
+	}
+	// A new directory and a file created inside it can both be in the batch.
+	seen := make(map[string]bool, len(added))
+	for _, e := range added {
+		if !e.IsDir && changed[e.Path] && idx.refreshFileLocked(e) {
+			seen[e.Path] = true
+		}
+	}
+	for _, p := range paths {
+		if !seen[p] {
+			idx.removePathLocked(p)
+		}
+	}
+	for _, e := range added {
+		if !seen[e.Path] {
+			seen[e.Path] = true
+			idx.addEntryLocked(e)
+		}
+	}
+	if idx.fileGrams != nil {
+		idx.fileGrams.sortKeys()
+		idx.dirGrams.sortKeys()
+	}
+	compact := idx.needsCompactionLocked()
+	idx.mu.Unlock()
+	idx.updateContent(paths, added)
+
+	if compact {
+		allFiles, allDirs := idx.liveEntries()
+		idx.setEntries(allFiles, allDirs)
+	}
+	return watchErr
+}
+
+func fileEntry(path, name string, info fs.FileInfo) FileEntry {
+	return FileEntry{
+		Name:    name,
+		Path:    path,
+		Dir:     filepath.Dir(path),
+		Ext:     strings.ToLower(filepath.Ext(name)),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}
+}
+
+func dirEntry(path, name string, info fs.FileInfo) FileEntry {
+	return FileEntry{
+		Name:    name,
+		Path:    path,
+		Dir:     filepath.Dir(path),
+		ModTime: info.ModTime(),
+		IsDir:   true,
+	}
+}
+
+// refreshFileLocked copies the size and modification time of e into the
+// live file entry at e.Path, reporting false if the index holds none.
+// idx.mu must be held for writing.
+func (idx *FileIndex) refreshFileLocked(e FileEntry) bool {
+	if idx.fileGrams == nil {
+		return false
+	}
+	for _, pos := range idx.fileGrams.positionsOf(e.Name) {
+		if idx.files[pos].Path == e.Path {
+			idx.files[pos].Size = e.Size
+			idx.files[pos].ModTime = e.ModTime
+			return true
+		}
+	}
+	return false
+}
+
+// removePathLocked drops the entry for path and, if it was a directory,
+// every entry beneath it. idx.mu must be held for writing.
+func (idx *FileIndex) removePathLocked(path string) {
+	if idx.fileGrams == nil {
+		return
+	}
+	name := filepath.Base(path)
+	for _, pos := range idx.fileGrams.positionsOf(name) {
+		if idx.files[pos].Path == path {
+			idx.fileGrams.remove(int(pos))
+		}
+	}
+	wasDir := false
+	for _, pos := range idx.dirGrams.positionsOf(name) {
+		if idx.dirs[pos].Path == path && !idx.dirGrams.isRemoved(int(pos)) {
+			idx.dirGrams.remove(int(pos))
+			wasDir = true
+		}
+	}
+	if !wasDir {
+		return
+	}
+	prefix := path + string(filepath.Separator)
+	for i, f := range idx.files {
+		if strings.HasPrefix(f.Path, prefix) {
+			idx.fileGrams.remove(i)
+		}
+	}
+	for i, d := range idx.dirs {
+		if strings.HasPrefix(d.Path, prefix) {
+			idx.dirGrams.remove(i)
+		}
+	}
+}
+
+// addEntryLocked appends e to the index. idx.mu must be held for writing, and
+// the trigram keys re-sorted before it is released.
+func (idx *FileIndex) addEntryLocked(e FileEntry) {
+	if idx.fileGrams == nil {
+		idx.fileGrams = newEntryIndex(nil)
+		idx.dirGrams = newEntryIndex(nil)
+	}
+	if e.IsDir {
+		idx.dirs = append(idx.dirs, e)
+		idx.dirNames = append(idx.dirNames, e.Name)
+		idx.dirGrams.addEntry(e)
+		return
+	}
+	if len(idx.files)-idx.fileGrams.dead >= maxIndexFiles {
+		return
+	}
+	idx.files = append(idx.files, e)
+	idx.names = append(idx.names, e.Name)
+	idx.fileGrams.addEntry(e)
+}
+
+// needsCompactionLocked reports whether enough entries have been removed that
+// rebuilding the index beats carrying them around.
+func (idx *FileIndex) needsCompactionLocked() bool {
+	if idx.fileGrams == nil {
+		return false
+	}
+	stale := func(t *trigramIndex) bool { return t.dead > 1024 && t.dead > t.size/4 }
+	return stale(idx.fileGrams) || stale(idx.dirGrams)
+}
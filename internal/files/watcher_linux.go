@@ -0,0 +1,138 @@
+//go:build linux
+
+package files
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO |
+	syscall.IN_MOVE_SELF | syscall.IN_CLOSE_WRITE | syscall.IN_ONLYDIR
+
+// inotifyWatcher is the Linux Watcher. inotify watches are per directory, so
+// the caller adds every directory it indexes.
+type inotifyWatcher struct {
+	fd      int
+	file    *os.File // wraps fd so reads go through the runtime poller and Close unblocks them
+	changes chan Change
+	done    chan struct{} // closed by Close so a blocked send gives up
+
+	mu    sync.Mutex
+	paths map[int32]string // watch descriptor → directory
+
+	closeOnce sync.Once
+}
+
+func newPlatformWatcher() (Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	w := &inotifyWatcher{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		changes: make(chan Change, 4096),
+		done:    make(chan struct{}),
+		paths:   make(map[int32]string),
+	}
+	go w.readEvents()
+	return w, nil
+}
+
+func (w *inotifyWatcher) Add(dir string) error {
+	wd, err := syscall.InotifyAddWatch(w.fd, dir, inotifyMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return ErrWatchLimit
+		}
+		return err
+	}
+	// Adding a watch on an inode that is already watched returns the same
+	// descriptor, which is how a moved directory's watch learns its new path.
+	w.mu.Lock()
+	w.paths[int32(wd)] = dir
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *inotifyWatcher) Changes() <-chan Change {
+	return w.changes
+}
+
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		err = w.file.Close()
+	})
+	return err
+}
+
+func (w *inotifyWatcher) readEvents() {
+	defer close(w.changes)
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for off := 0; off+syscall.SizeofInotifyEvent <= n; {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+syscall.SizeofInotifyEvent : off+syscall.SizeofInotifyEvent+int(ev.Len)]
+			off += syscall.SizeofInotifyEvent + int(ev.Len)
+			if !w.handle(ev.Wd, ev.Mask, string(bytes.TrimRight(nameBytes, "\x00"))) {
+				return
+			}
+		}
+	}
+}
+
+// handle translates one inotify event, reporting false once the watcher has
+// been closed.
+func (w *inotifyWatcher) handle(wd int32, mask uint32, name string) bool {
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		return w.send(Change{Op: ChangeOverflow})
+	}
+
+	w.mu.Lock()
+	dir, ok := w.paths[wd]
+	switch {
+	case mask&syscall.IN_IGNORED != 0:
+		delete(w.paths, wd)
+		ok = false
+	case mask&syscall.IN_MOVE_SELF != 0:
+		// The directory's new path arrives as a create in its new parent;
+		// drop the watch so events are not reported under the old path.
+		syscall.InotifyRmWatch(w.fd, uint32(wd))
+		delete(w.paths, wd)
+		ok = false
+	}
+	w.mu.Unlock()
+	if !ok || name == "" {
+		return true
+	}
+
+	op := ChangeCreate
+	switch {
+	case mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+		op = ChangeRemove
+	case mask&syscall.IN_CLOSE_WRITE != 0:
+		op = ChangeWrite
+	}
+	return w.send(Change{Op: op, Path: filepath.Join(dir, name)})
+}
+
+func (w *inotifyWatcher) send(c Change) bool {
+	select {
+	case w.changes <- c:
+		return true
+	case <-w.done:
+		return false
+	}
+}
@@ -0,0 +1,84 @@
+//go:build linux
+
+package files
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func nextChange(t *testing.T, w Watcher) Change {
+	t.Helper()
+	select {
+	case c := <-w.Changes():
+		return c
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for an inotify event")
+		return Change{}
+	}
+}
+
+func TestInotifyWatcher_ReportsCreateWriteRenameDelete(t *testing.T) {
+	dir := t.TempDir()
+	w, err := newPlatformWatcher()
+	if err != nil {
+		t.Fatalf("newPlatformWatcher: %v", err)
+	}
+	defer w.Close()
+	if err := w.Add(dir); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeCreate, Path: a}) {
+		t.Errorf("create: got %+v", c)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeWrite, Path: a}) {
+		t.Errorf("close after write: got %+v", c)
+	}
+	if err := os.WriteFile(a, []byte("saved"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeWrite, Path: a}) {
+		t.Errorf("write in place: got %+v", c)
+	}
+
+	if err := os.Rename(a, b); err != nil {
+		t.Fatal(err)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeRemove, Path: a}) {
+		t.Errorf("rename from: got %+v", c)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeCreate, Path: b}) {
+		t.Errorf("rename to: got %+v", c)
+	}
+
+	if err := os.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	if c := nextChange(t, w); c != (Change{Op: ChangeRemove, Path: b}) {
+		t.Errorf("delete: got %+v", c)
+	}
+}
+
+func TestInotifyWatcher_CloseEndsChanges(t *testing.T) {
+	w, err := newPlatformWatcher()
+	if err != nil {
+		t.Fatalf("newPlatformWatcher: %v", err)
+	}
+	w.Close()
+	select {
+	case _, ok := <-w.Changes():
+		if ok {
+			t.Error("expected no events after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Changes was not closed after Close")
+	}
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package files
+
+func newPlatformWatcher() (Watcher, error) {
+	return nil, errWatchUnsupported
+}
@@ -0,0 +1,258 @@
+package files
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeWatcher struct {
+	mu      sync.Mutex
+	added   []string
+	limit   int // Add fails with ErrWatchLimit after this many watches; 0 means unlimited
+	closed  bool
+	changes chan Change
+}
+
+func newFakeWatcher(limit int) *fakeWatcher {
+	return &fakeWatcher{limit: limit, changes: make(chan Change, 64)}
+}
+
+func (w *fakeWatcher) Add(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.limit > 0 && len(w.added) >= w.limit {
+		return ErrWatchLimit
+	}
+	w.added = append(w.added, dir)
+	return nil
+}
+
+func (w *fakeWatcher) Changes() <-chan Change { return w.changes }
+
+func (w *fakeWatcher) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *fakeWatcher) isClosed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closed
+}
+
+func writeFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasFile(idx *FileIndex, path string) bool {
+	for _, f := range idx.Files() {
+		if f.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApplyChanges_CreateAndRemoveFile(t *testing.T) {
+	root := t.TempDir()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(nil, nil)
+
+	path := filepath.Join(root, "quarterly report.pdf")
+	writeFile(t, path)
+	idx.applyChanges([]string{path}, newFakeWatcher(0))
+
+	got := idx.SearchFiles(context.Background(), "quarterly", nil)
+	if len(got) != 1 || got[0].Path != path {
+		t.Fatalf("expected the new file to be searchable, got %v", got)
+	}
+
+	os.Remove(path)
+	idx.applyChanges([]string{path}, newFakeWatcher(0))
+	if got := idx.SearchFiles(context.Background(), "quarterly", nil); len(got) != 0 {
+		t.Errorf("expected the removed file to be gone, got %v", got)
+	}
+}
+
+func TestApplyChanges_WriteRefreshesEntryInPlace(t *testing.T) {
+	root := t.TempDir()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(nil, nil)
+
+	path := filepath.Join(root, "notes.txt")
+	writeFile(t, path)
+	idx.applyChanges([]string{path}, newFakeWatcher(0))
+
+	saved := time.Now().Add(time.Hour).Truncate(time.Second)
+	if err := os.WriteFile(path, []byte("longer content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, saved, saved); err != nil {
+		t.Fatal(err)
+	}
+	idx.applyChanges([]string{path}, newFakeWatcher(0))
+
+	idx.mu.RLock()
+	n, dead := len(idx.files), idx.fileGrams.dead
+	idx.mu.RUnlock()
+	if n != 1 || dead != 0 {
+		t.Errorf("expected the entry to be updated in place, got %d entries with %d removed", n, dead)
+	}
+	f := idx.Files()[0]
+	if f.Size != int64(len("longer content")) || !f.ModTime.Equal(saved) {
+		t.Errorf("entry = size %d, modified %v; want %d, %v", f.Size, f.ModTime, len("longer content"), saved)
+	}
+}
+
+func TestApplyChanges_NewDirectoryIsWalkedAndWatched(t *testing.T) {
+	root := t.TempDir()
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(nil, nil)
+
+	dir := filepath.Join(root, "photos")
+	writeFile(t, filepath.Join(dir, "beach.jpg"))
+	writeFile(t, filepath.Join(dir, "2024", "snow.jpg"))
+
+	w := newFakeWatcher(0)
+	idx.applyChanges([]string{dir, filepath.Join(dir, "beach.jpg")}, w)
+
+	if !hasFile(idx, filepath.Join(dir, "beach.jpg")) || !hasFile(idx, filepath.Join(dir, "2024", "snow.jpg")) {
+		t.Errorf("expected files inside the new directory to be indexed, got %v", idx.Files())
+	}
+	if n := len(idx.Files()); n != 2 {
+		t.Errorf("expected 2 files without duplicates, got %d", n)
+	}
+	if len(w.added) != 2 {
+		t.Errorf("expected the directory and its subdirectory to be watched, got %v", w.added)
+	}
+}
+
+func TestApplyChanges_RenamedDirectoryMovesDescendants(t *testing.T) {
+	root := t.TempDir()
+	oldDir := filepath.Join(root, "drafts")
+	writeFile(t, filepath.Join(oldDir, "essay.md"))
+
+	idx := NewFileIndex(nil, nil)
+	idx.setEntries(nil, nil)
+	idx.applyChanges([]string{oldDir}, newFakeWatcher(0))
+
+	newDir := filepath.Join(root, "published")
+	if err := os.Rename(oldDir, newDir); err != nil {
+		t.Fatal(err)
+	}
+	idx.applyChanges([]string{oldDir, newDir}, newFakeWatcher(0))
+
+	if hasFile(idx, filepath.Join(oldDir, "essay.md")) {
+		t.Error("expected entries under the old path to be removed")
+	}
+	if !hasFile(idx, filepath.Join(newDir, "essay.md")) {
+		t.Error("expected entries under the new path to be added")
+	}
+	if got := idx.SearchDirs(context.Background(), "drafts", nil); len(got) != 0 {
+		t.Errorf("expected the old folder to be gone, got %v", got)
+	}
+}
+
+func TestApplyChanges_CompactsAfterManyRemovals(t *testing.T) {
+	root := t.TempDir()
+	var paths []string
+	for i := 0; i < 1100; i++ {
+		paths = append(paths, filepath.Join(root, fmt.Sprintf("f%04d.txt", i)))
+	}
+	idx := NewFileIndex(nil, nil)
+	entries := make([]FileEntry, len(paths))
+	for i, p := range paths {
+		entries[i] = FileEntry{Name: filepath.Base(p), Path: p, Dir: root}
+	}
+	idx.setEntries(entries, nil)
+
+	// None of the paths exist on disk, so every one is removed.
+	idx.applyChanges(paths, newFakeWatcher(0))
+
+	idx.mu.RLock()
+	n, dead := len(idx.files), idx.fileGrams.dead
+	idx.mu.RUnlock()
+	if n != 0 || dead != 0 {
+		t.Errorf("expected a compacted empty index, got %d entries with %d removed", n, dead)
+	}
+}
+
+func useFakeWatcher(t *testing.T, w *fakeWatcher) {
+	t.Helper()
+	prevWatcher, prevQuiet := newWatcher, coalesceQuiet
+	newWatcher = func() (Watcher, error) { return w, nil }
+	coalesceQuiet = 10 * time.Millisecond
+	t.Cleanup(func() { newWatcher, coalesceQuiet = prevWatcher, prevQuiet })
+}
+
+func TestWatch_AppliesCoalescedChanges(t *testing.T) {
+	root := t.TempDir()
+	w := newFakeWatcher(0)
+	useFakeWatcher(t, w)
+
+	idx := NewFileIndex([]Root{{Path: root}}, nil)
+	idx.cachePath = ""
+	idx.setEntries(nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go idx.watch(ctx)
+
+	path := filepath.Join(root, "notes.txt")
+	writeFile(t, path)
+	for i := 0; i < 10; i++ {
+		w.changes <- Change{Op: ChangeCreate, Path: path}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !hasFile(idx, path) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the change to be applied")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := len(idx.Files()); n != 1 {
+		t.Errorf("expected repeated events to coalesce into one entry, got %d", n)
+	}
+}
+
+func TestWatch_FallsBackWhenWatchLimitHit(t *testing.T) {
+	w := newFakeWatcher(1)
+	useFakeWatcher(t, w)
+
+	idx := NewFileIndex([]Root{{Path: "/r"}}, nil)
+	idx.setEntries(nil, []FileEntry{{Name: "a", Path: "/r/a", Dir: "/r", IsDir: true}})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		idx.watch(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !w.isClosed() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the watcher to be closed once the limit was hit")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	select {
+	case <-done:
+		t.Fatal("expected watch to keep running in periodic rescan mode")
+	case <-time.After(20 * time.Millisecond):
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBudget is how long a provider may run before its results are
+// discarded. Providers that scan large indexes can ask for more by
+// implementing Budgeted.
+const DefaultBudget = 250 * time.Millisecond
+
+// Budgeted is implemented by providers that need a time budget other than
+// DefaultBudget.
+type Budgeted interface {
+	Budget() time.Duration
+}
+
+func budgetOf[T any](p Provider[T]) time.Duration {
+	if b, ok := p.(Budgeted); ok && b.Budget() > 0 {
+		return b.Budget()
+	}
+	return DefaultBudget
+}
+
+// Fanout queries every provider concurrently under ctx. Each provider gets its
+// own deadline (see Budgeted); results that arrive after that deadline are
+// dropped.
+//
+// Fanout returns once every provider has answered or wait has elapsed,
+// whichever comes first. Providers still running at that point keep going in
+// the background; each time one of them finishes, late is called with all
+// results gathered so far. late is never called after ctx is done, so a
+// superseded query stops producing updates as soon as it is cancelled.
+func Fanout[T any](ctx context.Context, providers []Provider[T], query string, wait time.Duration, late func(all []Scored[T])) []Scored[T] {
+	batches := make(chan []Scored[T], len(providers))
+	var wg sync.WaitGroup
+	for _, p := range providers {
+		wg.Add(1)
+		go func(p Provider[T]) {
+			defer wg.Done()
+			pctx, cancel := context.WithTimeout(ctx, budgetOf(p))
+			defer cancel()
+			items := queryProvider(pctx, p, query)
+			if pctx.Err() != nil {
+				// Over budget or superseded: the answer may be partial.
+				items = nil
+			}
+			batches <- items
+		}(p)
+	}
+	go func() {
+		wg.Wait()
+		close(batches)
+	}()
+
+	var out []Scored[T]
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	pending := len(providers)
+	for pending > 0 {
+		select {
+		case b := <-batches:
+			pending--
+			out = append(out, b...)
+		case <-timer.C:
+			go drainLate(ctx, batches, append([]Scored[T](nil), out...), late)
+			return out
+		case <-ctx.Done():
+			return out
+		}
+	}
+	return out
+}
+
+// drainLate collects the batches of providers that missed the wait window and
+// reports the growing result set through late.
+func drainLate[T any](ctx context.Context, batches <-chan []Scored[T], all []Scored[T], late func([]Scored[T])) {
+	for b := range batches {
+		if len(b) == 0 || ctx.Err() != nil {
+			continue
+		}
+		all = append(all, b...)
+		if late != nil {
+			late(append([]Scored[T](nil), all...))
+		}
+	}
+}
+
+// queryProvider runs p.Query, treating a panicking provider as one that
+// returned nothing so a single broken source cannot take down the search.
+func queryProvider[T any](ctx context.Context, p Provider[T], query string) (items []Scored[T]) {
+	defer func() {
+		if recover() != nil {
+			items = nil
+		}
+	}()
+	return p.Query(ctx, query)
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type slowProvider struct {
+	stubProvider
+	delay  time.Duration
+	budget time.Duration
+}
+
+func (p slowProvider) Budget() time.Duration { return p.budget }
+
+func (p slowProvider) Query(ctx context.Context, query string) []Scored[string] {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil
+	}
+	return p.stubProvider.Query(ctx, query)
+}
+
+func TestFanout_CollectsAllFastProviders(t *testing.T) {
+	providers := []Provider[string]{
+		stubProvider{name: "apps"},
+		stubProvider{name: "system"},
+	}
+	got := Fanout(context.Background(), providers, "q", time.Second, nil)
+	if len(got) != 2 {
+		t.Fatalf("want 2 results, got %d", len(got))
+	}
+}
+
+func TestFanout_SlowProviderArrivesLate(t *testing.T) {
+	providers := []Provider[string]{
+		stubProvider{name: "apps"},
+		slowProvider{stubProvider: stubProvider{name: "files"}, delay: 50 * time.Millisecond, budget: time.Second},
+	}
+	lateCh := make(chan []Scored[string], 1)
+	got := Fanout(context.Background(), providers, "q", 10*time.Millisecond, func(all []Scored[string]) {
+		lateCh <- all
+	})
+	if len(got) != 1 || got[0].Item != "apps:q" {
+		t.Fatalf("want only the fast result before the wait window closes, got %v", got)
+	}
+	select {
+	case all := <-lateCh:
+		if len(all) != 2 {
+			t.Errorf("want late update with both results, got %v", all)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("late results were never delivered")
+	}
+}
+
+func TestFanout_DropsProviderOverBudget(t *testing.T) {
+	providers := []Provider[string]{
+		slowProvider{stubProvider: stubProvider{name: "files"}, delay: 200 * time.Millisecond, budget: 20 * time.Millisecond},
+	}
+	got := Fanout(context.Background(), providers, "q", time.Second, nil)
+	if len(got) != 0 {
+		t.Errorf("want results over budget to be dropped, got %v", got)
+	}
+}
+
+func TestFanout_CancelledQueryGetsNoLateResults(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	providers := []Provider[string]{
+		slowProvider{stubProvider: stubProvider{name: "files"}, delay: 30 * time.Millisecond, budget: time.Second},
+	}
+	called := make(chan struct{}, 1)
+	Fanout(ctx, providers, "q", time.Millisecond, func([]Scored[string]) { called <- struct{}{} })
+	cancel()
+	select {
+	case <-called:
+		t.Error("late callback fired for a cancelled query")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+type panicProvider struct{ stubProvider }
+
+func (p panicProvider) Query(context.Context, string) []Scored[string] { panic("boom") }
+
+func TestFanout_PanickingProviderIsIgnored(t *testing.T) {
+	providers := []Provider[string]{
+		panicProvider{stubProvider{name: "broken"}},
+		stubProvider{name: "apps"},
+	}
+	got := Fanout(context.Background(), providers, "q", time.Second, nil)
+	if len(got) != 1 || got[0].Item != "apps:q" {
+		t.Errorf("want the healthy provider's result only, got %v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold lowercases s and strips diacritics ("Café" → "cafe"), so a query typed
+// without accents still matches. It maps rune to rune: rune i of the result
+// always stands for rune i of s, which keeps match positions valid in s.
+func Fold(s string) string {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return strings.ToLower(s)
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		b.WriteRune(foldRune(r))
+	}
+	return b.String()
+}
+
+// foldLetters covers letters whose stroke or slash is part of the letter
+// itself, so canonical decomposition leaves them alone.
+var foldLetters = map[rune]rune{
+	'ø': 'o', 'đ': 'd', 'ł': 'l', 'ħ': 'h', 'ı': 'i', 'ŧ': 't', 'ƀ': 'b', 'ƶ': 'z',
+}
+
+func foldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+	r = unicode.ToLower(r)
+	if f, ok := foldLetters[r]; ok {
+		return f
+	}
+	var buf [utf8.UTFMax]byte
+	d := norm.NFD.Properties(buf[:utf8.EncodeRune(buf[:], r)]).Decomposition()
+	if len(d) == 0 {
+		return r
+	}
+	// Only drop combining marks: Hangul syllables also decompose, into
+	// letters that must stay.
+	base, n := utf8.DecodeRune(d)
+	for rest := d[n:]; len(rest) > 0; rest = rest[n:] {
+		var m rune
+		m, n = utf8.DecodeRune(rest)
+		if !unicode.Is(unicode.Mn, m) {
+			return r
+		}
+	}
+	return base
+}
@@ -1,90 +1,177 @@
 package search
 
 import (
-	"sort"
+	"cmp"
+	"context"
+	"slices"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 )
 
 // Match is a single scored result from a Fuzzy search.
 type Match struct {
 	Score int
 	Index int
+	// Positions holds the rune offsets of the target that the query matched,
+	// ascending; see Ranges. It is empty for an empty query.
+	Positions []int
 }
 
+// Fuzzy scores every target against query and returns the matches, best
+// first. Targets that miss as a subsequence may still match with a typo or
+// two ("fierfox", "chrmoe"), scored below every subsequence match.
+// usageScores adds 100 per usage point to each target's score and orders
+// ties; pass nil for no boosting.
 func Fuzzy(query string, targets []string, usageScores []int) []Match {
+	return fuzzy(context.Background(), query, targets, usageScores, true)
+}
+
+// ctxCheckInterval is how many targets FuzzyContext scores between checks of
+// its context.
+const ctxCheckInterval = 4096
+
+// FuzzyContext is Fuzzy for large target lists such as the file index. It
+// checks ctx periodically and returns nil once ctx is done, so a stale query
+// stops scoring as soon as the next keystroke cancels it. It skips the typo
+// fallback, which would cost an edit-distance computation per target.
+func FuzzyContext(ctx context.Context, query string, targets []string, usageScores []int) []Match {
+	return fuzzy(ctx, query, targets, usageScores, false)
+}
+
+func fuzzy(ctx context.Context, query string, targets []string, usageScores []int, typos bool) []Match {
+	if usageScores == nil {
+		usageScores = make([]int, len(targets))
+	}
 	if query == "" {
 		matches := make([]Match, len(targets))
 		for i := range targets {
 			matches[i] = Match{Score: usageScores[i] * 100, Index: i}
 		}
-		sortByScore(matches)
+		sortMatches(matches, targets, usageScores)
 		return matches
 	}
 
-	queryNorm := strings.ToLower(strings.TrimSpace(query))
+	queryNorm := Fold(strings.TrimSpace(query))
+	queryRunes := []rune(queryNorm)
+	typos = typos && maxTypos(len(queryRunes)) > 0
 	var matches []Match
 
-	const minScore = 50
-
 	for i, target := range targets {
-		targetNorm := strings.ToLower(target)
-		s := score(queryNorm, targetNorm)
+		if i%ctxCheckInterval == 0 && ctx.Err() != nil {
+			return nil
+		}
+		targetNorm := Fold(target)
+		s := scoreCased(queryNorm, targetNorm, target)
 		if s >= minScore {
 			s += usageScores[i] * 100
-			matches = append(matches, Match{Score: s, Index: i})
+			matches = append(matches, Match{Score: s, Index: i, Positions: positions(queryNorm, targetNorm, target)})
+		} else if typos {
+			if s, start, end := typoMatch(queryRunes, newRuneText(targetNorm, target)); s > 0 {
+				s += usageScores[i] * 100
+				matches = append(matches, Match{Score: s, Index: i, Positions: span(start, end)})
+			}
 		}
 	}
 
-	sortByScore(matches)
+	sortMatches(matches, targets, usageScores)
 	return matches
 }
 
+// minScore is the lowest score Fuzzy accepts as a match.
+const minScore = 50
+
+// MatchScore scores a query against a single target and returns 0 when it
+// does not match. Both must already be folded with Fold (and the query
+// trimmed); it lets callers with pre-normalized corpora skip Fuzzy's
+// per-target folding and keep only their best few results. Without the
+// original casing, camelCase humps do not count as word starts.
+func MatchScore(queryNorm, targetNorm string) int {
+	if s := score(queryNorm, targetNorm); s >= minScore {
+		return s
+	}
+	return 0
+}
+
+// score rates a folded query against a folded target.
 func score(query, target string) int {
+	return scoreCased(query, target, "")
+}
+
+// scoreCased is score given also the target before folding, which is used
+// only to find camelCase word starts.
+func scoreCased(query, target, orig string) int {
 	if target == query {
 		return 10000
 	}
+	qn := utf8.RuneCountInString(query)
 	if strings.HasPrefix(target, query) {
-		return 5000 + len(query)*10
+		return 5000 + qn*10
 	}
 	if strings.Contains(target, query) {
-		return 2000 + len(query)*5
+		return 2000 + qn*5
 	}
 
-	if s := acronymScore(query, target); s > 0 {
+	t := newRuneText(target, orig)
+	if s := acronymScore([]rune(query), t); s > 0 {
 		return s
 	}
 
 	terms := strings.Fields(query)
 	if len(terms) > 1 {
-		if s := multiTermScore(terms, target); s > 0 {
+		if s := multiTermScore(terms, target, t); s > 0 {
 			return s
 		}
 	}
 
-	return fuzzyScore(query, target)
+	return fuzzyScore([]rune(query), t)
 }
 
-func acronymScore(query, target string) int {
-	if len(query) == 0 || len(target) == 0 {
-		return 0
+// runeText is a folded target split into runes, with the runes that start a
+// word marked.
+type runeText struct {
+	runes []rune
+	start []bool
+}
+
+func newRuneText(folded, orig string) runeText {
+	runes := []rune(folded)
+	var origRunes []rune
+	if orig != "" {
+		origRunes = []rune(orig)
+		if len(origRunes) != len(runes) {
+			origRunes = nil
+		}
+	}
+	start := make([]bool, len(runes))
+	for i := range runes {
+		switch {
+		case i == 0:
+			start[i] = true
+		case isSeparator(runes[i-1]):
+			start[i] = !isSeparator(runes[i])
+		case origRunes != nil:
+			start[i] = unicode.IsUpper(origRunes[i]) && !unicode.IsUpper(origRunes[i-1]) && unicode.IsLetter(origRunes[i-1])
+		}
 	}
+	return runeText{runes: runes, start: start}
+}
+
+// isSeparator reports whether r sits between words: spaces, punctuation
+// ("-", "_", ".", "/", "・") and symbols such as emoji.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
 
-	type acChar struct {
-		ch  byte
-		pos int
+func acronymScore(query []rune, t runeText) int {
+	if len(query) == 0 || len(t.runes) == 0 {
+		return 0
 	}
 
-	var acronym []acChar
-	for i := 0; i < len(target); i++ {
-		ch := target[i]
-		isAcronym := i == 0
-		if !isAcronym && i > 0 {
-			prev := target[i-1]
-			isAcronym = prev == ' ' || prev == '-' || prev == '_' || prev == '.' || prev == '/' || prev == '\\'
-		}
-		if isAcronym {
-			acronym = append(acronym, acChar{ch: ch, pos: i})
+	var acronym []rune
+	for i, r := range t.runes {
+		if t.start[i] {
+			acronym = append(acronym, r)
 		}
 	}
 
@@ -94,11 +181,9 @@ func acronymScore(query, target string) int {
 
 	// Check query is a subsequence of acronym chars
 	qi := 0
-	matchCount := 0
-	for _, ac := range acronym {
-		if qi < len(query) && ac.ch == query[qi] {
+	for _, r := range acronym {
+		if qi < len(query) && r == query[qi] {
 			qi++
-			matchCount++
 		}
 	}
 
@@ -108,16 +193,16 @@ func acronymScore(query, target string) int {
 
 	// Perfect acronym (all initials consumed)
 	base := 3000
-	if matchCount == len(acronym) {
+	if len(query) == len(acronym) {
 		base = 4000
 	}
 	return base + len(query)*15
 }
 
-func multiTermScore(terms []string, target string) int {
+func multiTermScore(terms []string, target string, t runeText) int {
 	totalScore := 0
 	for _, term := range terms {
-		s := singleTermBestScore(term, target)
+		s := singleTermBestScore(term, target, t)
 		if s == 0 {
 			return 0 // all terms must match
 		}
@@ -128,20 +213,22 @@ func multiTermScore(terms []string, target string) int {
 	return avg + len(terms)*50
 }
 
-func singleTermBestScore(term, target string) int {
+func singleTermBestScore(term, target string, t runeText) int {
+	n := utf8.RuneCountInString(term)
 	if strings.HasPrefix(target, term) {
-		return 5000 + len(term)*10
+		return 5000 + n*10
 	}
 	if strings.Contains(target, term) {
-		return 2000 + len(term)*5
+		return 2000 + n*5
 	}
-	if s := acronymScore(term, target); s > 0 {
+	q := []rune(term)
+	if s := acronymScore(q, t); s > 0 {
 		return s
 	}
-	return fuzzyScore(term, target)
+	return fuzzyScore(q, t)
 }
 
-func fuzzyScore(query, target string) int {
+func fuzzyScore(query []rune, t runeText) int {
 	if len(query) == 0 {
 		return 0
 	}
@@ -154,8 +241,8 @@ func fuzzyScore(query, target string) int {
 	wordBoundaryBonus := 0
 	consecutiveBonus := 0
 
-	for ti := 0; ti < len(target) && queryIndex < len(query); ti++ {
-		if target[ti] == query[queryIndex] {
+	for ti := 0; ti < len(t.runes) && queryIndex < len(query); ti++ {
+		if t.runes[ti] == query[queryIndex] {
 			if firstMatchIndex == -1 {
 				firstMatchIndex = ti
 			}
@@ -171,7 +258,7 @@ func fuzzyScore(query, target string) int {
 			// first character at position 0
 			if queryIndex == 0 && ti == 0 {
 				wordBoundaryBonus += 50
-			} else if ti > 0 && isWordBoundary(rune(target[ti-1])) {
+			} else if t.start[ti] {
 				wordBoundaryBonus += 25
 			}
 			queryIndex++
@@ -189,7 +276,7 @@ func fuzzyScore(query, target string) int {
 	base := 100 * (len(query) + 1) / ((1 + firstMatchIndex) + (matchSpan + 1))
 
 	// Length proximity bonus (target close in length to query)
-	lengthDiff := len(target) - len(query)
+	lengthDiff := len(t.runes) - len(query)
 	lengthBonus := 0
 	switch {
 	case lengthDiff <= 5:
@@ -201,12 +288,32 @@ func fuzzyScore(query, target string) int {
 	return base + wordBoundaryBonus + consecutiveBonus + lengthBonus
 }
 
-func isWordBoundary(r rune) bool {
-	return r == ' ' || r == '-' || r == '_' || r == '.' || r == '/' || r == '\\' || unicode.IsUpper(r)
+// sortMatches orders matches best first. Equal scores go to the more used
+// target, then the shorter one, then alphabetically and finally by index, so
+// the order is the same every time the same targets match.
+func sortMatches(matches []Match, targets []string, usageScores []int) {
+	slices.SortFunc(matches, func(a, b Match) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(usageScores[b.Index], usageScores[a.Index]); c != 0 {
+			return c
+		}
+		if c := compareNames(targets[a.Index], targets[b.Index]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Index, b.Index)
+	})
 }
 
-func sortByScore(matches []Match) {
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].Score > matches[j].Score
-	})
+// compareNames orders names for breaking ties: shorter first, then
+// alphabetically ignoring case and accents, then by exact spelling.
+func compareNames(a, b string) int {
+	if c := cmp.Compare(utf8.RuneCountInString(a), utf8.RuneCountInString(b)); c != 0 {
+		return c
+	}
+	if c := strings.Compare(Fold(a), Fold(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
 }
@@ -1,7 +1,9 @@
 package search
 
 import (
+	"slices"
 	"testing"
+	"unicode/utf8"
 )
 
 func TestFuzzy_EmptyQuery_ReturnsAllTargetsSortedByUsage(t *testing.T) {
@@ -89,6 +91,19 @@ func TestFuzzy_UsageScore_BoostsMatch(t *testing.T) {
 	}
 }
 
+func TestFuzzy_NilUsageScores_ScoresMatchAlone(t *testing.T) {
+	targets := []string{"Firefox", "Fire HD"}
+	results := Fuzzy("fire", targets, nil)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if want := MatchScore("fire", Fold(targets[r.Index])); r.Score != want {
+			t.Errorf("index %d: score %d, want the bare match score %d", r.Index, r.Score, want)
+		}
+	}
+}
+
 func TestFuzzy_ResultsSortedByScoreDescending(t *testing.T) {
 	targets := []string{"abcdef", "ab", "abc"}
 	scores := []int{0, 0, 0}
@@ -136,3 +151,88 @@ func TestFuzzy_EmptyTargets_ReturnsEmpty(t *testing.T) {
 		t.Errorf("expected empty results for empty targets, got %d", len(results))
 	}
 }
+
+func TestFold(t *testing.T) {
+	cases := map[string]string{
+		"Café":         "cafe",
+		"Ångström":     "angstrom",
+		"Øresund Łódź": "oresund lodz",
+		"İstanbul":     "istanbul",
+		"한글":           "한글",
+		"写真.JPG":       "写真.jpg",
+		"🚀 Launch":     "🚀 launch",
+	}
+	for in, want := range cases {
+		got := Fold(in)
+		if got != want {
+			t.Errorf("Fold(%q) = %q, want %q", in, got, want)
+		}
+		if utf8.RuneCountInString(got) != utf8.RuneCountInString(in) {
+			t.Errorf("Fold(%q) changed the rune count", in)
+		}
+	}
+}
+
+func TestFuzzy_NonASCII(t *testing.T) {
+	cases := []struct {
+		query   string
+		targets []string
+		want    string // best match; "" for none
+	}{
+		{"cafe", []string{"Café Racer", "Cascade"}, "Café Racer"},
+		{"café", []string{"Cafe Racer"}, "Cafe Racer"},
+		{"zurich", []string{"Zürich Transit", "Zug"}, "Zürich Transit"},
+		{"写真", []string{"メモ", "写真ビューア"}, "写真ビューア"},
+		{"ビューア", []string{"写真ビューア", "ビデオ"}, "写真ビューア"},
+		{"한글", []string{"한컴 한글 2024", "메모장"}, "한컴 한글 2024"},
+		{"ддт", []string{"Диспетчер Для Телефона", "Дневник"}, "Диспетчер Для Телефона"},
+		{"rocket", []string{"🚀 Rocket Launcher"}, "🚀 Rocket Launcher"},
+		{"rl", []string{"🚀Rocket🚀Launcher"}, "🚀Rocket🚀Launcher"},
+		{"naive", []string{"Naïve Bayes"}, "Naïve Bayes"},
+		{"xyz", []string{"Café", "写真"}, ""},
+	}
+	for _, c := range cases {
+		results := Fuzzy(c.query, c.targets, make([]int, len(c.targets)))
+		got := ""
+		if len(results) > 0 {
+			got = c.targets[results[0].Index]
+		}
+		if got != c.want {
+			t.Errorf("Fuzzy(%q) best = %q, want %q", c.query, got, c.want)
+		}
+	}
+}
+
+func TestFuzzy_CamelCaseBoundaries(t *testing.T) {
+	// "um" starts both camelCase words of "ÜberMaß" only once the humps are
+	// found on the unfolded, multibyte text.
+	results := Fuzzy("um", []string{"Summary", "ÜberMaß"}, []int{0, 0})
+	if len(results) == 0 || results[0].Index != 1 || results[0].Score < 4000 {
+		t.Errorf("expected an acronym match on the camelCase humps, got %+v", results)
+	}
+	// Byte offsets inside a multibyte rune must not register as boundaries.
+	if s := fuzzyScore([]rune("ue"), newRuneText(Fold("Müller Eis"), "Müller Eis")); s == 0 {
+		t.Error("expected a rune-wise subsequence match")
+	}
+}
+
+func TestFuzzy_ScoresCountRunesNotBytes(t *testing.T) {
+	ascii := Fuzzy("cafe", []string{"cafeteria"}, []int{0})
+	accented := Fuzzy("café", []string{"caféteria"}, []int{0})
+	if len(ascii) != 1 || len(accented) != 1 || ascii[0].Score != accented[0].Score {
+		t.Errorf("expected equal prefix scores, got %+v and %+v", ascii, accented)
+	}
+}
+
+func TestFuzzy_TiesAreDeterministic(t *testing.T) {
+	targets := []string{"Paint 3D", "paint", "Paint", "Paint.NET", "Paint"}
+	got := Fuzzy("", targets, []int{0, 0, 0, 0, 3})
+	var order []int
+	for _, m := range got {
+		order = append(order, m.Index)
+	}
+	// Used first, then shorter, then alphabetically with exact spelling last.
+	if want := []int{4, 2, 1, 0, 3}; !slices.Equal(order, want) {
+		t.Errorf("got order %v, want %v", order, want)
+	}
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Range is a half-open span [Start, End) of rune offsets into a result's
+// title or subtitle. Runes are Unicode code points, which is also what
+// Array.from splits a string into on the frontend.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Ranges collapses ascending rune positions into ranges of consecutive runes.
+func Ranges(positions []int) []Range {
+	var out []Range
+	for _, p := range positions {
+		if n := len(out); n > 0 && out[n-1].End == p {
+			out[n-1].End++
+			continue
+		}
+		out = append(out, Range{Start: p, End: p + 1})
+	}
+	return out
+}
+
+// Highlight returns the parts of text that query matches the way Fuzzy scores
+// it, or nil when it does not match.
+func Highlight(query, text string) []Range {
+	q := Fold(strings.TrimSpace(query))
+	if q == "" {
+		return nil
+	}
+	return Ranges(positions(q, Fold(text), text))
+}
+
+// HighlightTerms returns every place a term of query appears in text as a
+// substring, splitting the query at spaces and path separators. It suits
+// secondary text such as a folder path, where a scattered fuzzy match would
+// only be noise.
+func HighlightTerms(query, text string) []Range {
+	terms, _ := PathTerms(Fold(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	folded := Fold(text)
+	hit := make([]bool, utf8.RuneCountInString(folded))
+	for _, term := range terms {
+		n := utf8.RuneCountInString(term)
+		for off, r := 0, 0; off < len(folded); {
+			i := strings.Index(folded[off:], term)
+			if i < 0 {
+				break
+			}
+			r += utf8.RuneCountInString(folded[off : off+i])
+			for j := r; j < r+n; j++ {
+				hit[j] = true
+			}
+			off += i + len(term)
+			r += n
+		}
+	}
+	var pos []int
+	for i, h := range hit {
+		if h {
+			pos = append(pos, i)
+		}
+	}
+	return Ranges(pos)
+}
+
+// positions returns the rune positions of target that scoreCased matched for
+// query, trying its strategies in the same order and then the typo fallback,
+// or nil for no match.
+func positions(query, target, orig string) []int {
+	if i := strings.Index(target, query); i >= 0 {
+		start := utf8.RuneCountInString(target[:i])
+		return span(start, start+utf8.RuneCountInString(query))
+	}
+
+	t := newRuneText(target, orig)
+	q := []rune(query)
+	if acronymScore(q, t) > 0 {
+		return subsequence(q, t, true)
+	}
+
+	terms := strings.Fields(query)
+	if len(terms) > 1 && multiTermScore(terms, target, t) > 0 {
+		hit := make([]bool, len(t.runes))
+		for _, term := range terms {
+			for _, p := range termPositions(term, target, t) {
+				hit[p] = true
+			}
+		}
+		var out []int
+		for i, h := range hit {
+			if h {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+
+	if fuzzyScore(q, t) > 0 {
+		return subsequence(q, t, false)
+	}
+	if s, start, end := typoMatch(q, t); s > 0 {
+		return span(start, end)
+	}
+	return nil
+}
+
+// termPositions is positions for one term of a multi-term query, mirroring
+// singleTermBestScore.
+func termPositions(term, target string, t runeText) []int {
+	if i := strings.Index(target, term); i >= 0 {
+		start := utf8.RuneCountInString(target[:i])
+		return span(start, start+utf8.RuneCountInString(term))
+	}
+	q := []rune(term)
+	if acronymScore(q, t) > 0 {
+		return subsequence(q, t, true)
+	}
+	return subsequence(q, t, false)
+}
+
+// subsequence greedily matches q against t from the left, as acronymScore and
+// fuzzyScore do, considering only word starts when startsOnly is set.
+func subsequence(q []rune, t runeText, startsOnly bool) []int {
+	var out []int
+	qi := 0
+	for i, r := range t.runes {
+		if qi == len(q) {
+			break
+		}
+		if (!startsOnly || t.start[i]) && r == q[qi] {
+			out = append(out, i)
+			qi++
+		}
+	}
+	if qi < len(q) {
+		return nil
+	}
+	return out
+}
+
+func span(start, end int) []int {
+	out := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		out = append(out, i)
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package search
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRanges(t *testing.T) {
+	got := Ranges([]int{0, 1, 2, 5, 7, 8})
+	want := []Range{{0, 3}, {5, 6}, {7, 9}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Ranges = %v, want %v", got, want)
+	}
+	if Ranges(nil) != nil {
+		t.Error("expected no ranges for no positions")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	cases := []struct {
+		query, text string
+		want        []Range
+	}{
+		{"fire", "Firefox", []Range{{0, 4}}},
+		{"studio", "Visual Studio Code", []Range{{7, 13}}},
+		{"vsc", "Visual Studio Code", []Range{{0, 1}, {7, 8}, {14, 15}}},
+		{"vscode", "Visual Studio Code", []Range{{0, 1}, {2, 3}, {14, 18}}}, // greedy, as scored
+		{"studio vis", "Visual Studio Code", []Range{{0, 3}, {7, 13}}},
+		{"cafe", "Le Café", []Range{{3, 7}}},
+		{"写真", "🚀 写真ビューア", []Range{{2, 4}}},
+		{"zzz", "Firefox", nil},
+		{"", "Firefox", nil},
+	}
+	for _, c := range cases {
+		if got := Highlight(c.query, c.text); !slices.Equal(got, c.want) {
+			t.Errorf("Highlight(%q, %q) = %v, want %v", c.query, c.text, got, c.want)
+		}
+	}
+}
+
+func TestHighlightTerms(t *testing.T) {
+	got := HighlightTerms("proj/blight main", "~/Proj/blight/blight-main")
+	want := []Range{{2, 6}, {7, 13}, {14, 20}, {21, 25}}
+	if !slices.Equal(got, want) {
+		t.Errorf("HighlightTerms = %v, want %v", got, want)
+	}
+}
+
+func TestFuzzy_PositionsMatchHighlight(t *testing.T) {
+	targets := []string{"Visual Studio Code", "Visio", "Café Révolution"}
+	for _, q := range []string{"vs", "code", "cafe rev", "vsco"} {
+		for _, m := range Fuzzy(q, targets, make([]int, len(targets))) {
+			if got, want := Ranges(m.Positions), Highlight(q, targets[m.Index]); !slices.Equal(got, want) {
+				t.Errorf("%q in %q: positions %v, Highlight %v", q, targets[m.Index], got, want)
+			}
+			if len(m.Positions) == 0 {
+				t.Errorf("%q in %q: expected positions for a match", q, targets[m.Index])
+			}
+		}
+	}
+}
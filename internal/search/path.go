@@ -0,0 +1,285 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PathQuery reports whether query should be scored against full paths with
+// PathScore rather than against names alone: it names folders with a
+// separator ("proj/blight/main") or holds more than one term ("docs report").
+func PathQuery(query string) bool {
+	return strings.ContainsAny(query, `/\`) || len(strings.Fields(query)) > 1
+}
+
+// PathTerms splits a path query into its terms. ordered reports whether the
+// query used separators, in which case its terms name path segments from left
+// to right and the last names the entry itself; otherwise the terms may match
+// segments in any order.
+func PathTerms(query string) (terms []string, ordered bool) {
+	return appendFields(nil, query, isPathSpace), strings.ContainsAny(query, `/\`)
+}
+
+// appendFields is strings.FieldsFunc appending to dst, so that PathScore can
+// split the query into a stack array rather than allocate for every path it
+// scores.
+func appendFields(dst []string, s string, sep func(rune) bool) []string {
+	start := -1
+	for i, r := range s {
+		switch {
+		case sep(r):
+			if start >= 0 {
+				dst = append(dst, s[start:i])
+				start = -1
+			}
+		case start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		dst = append(dst, s[start:])
+	}
+	return dst
+}
+
+func isPathSpace(r rune) bool {
+	return isPathSeparator(r) || unicode.IsSpace(r)
+}
+
+// appendSegments appends the non-empty segments of path to dst.
+func appendSegments(dst []string, path string) []string {
+	start := 0
+	for i := 0; i <= len(path); i++ {
+		if i == len(path) || path[i] == '/' || path[i] == '\\' {
+			if i > start {
+				dst = append(dst, path[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return dst
+}
+
+func isPathSeparator(r rune) bool {
+	return r == '/' || r == '\\'
+}
+
+// adjacentSegmentBonus rewards separated terms that match consecutive folders,
+// so "blight/main" prefers .../blight/main.go over .../blight/cmd/main.go.
+const adjacentSegmentBonus = 100
+
+// PathScore scores a path query against a full path and returns 0 when it does
+// not match. Both must already be folded with Fold. Every term has to match
+// some segment of the path and at least one has to match the basename;
+// basename hits count in full while parent folders weigh less the further
+// they are from it.
+func PathScore(queryNorm, pathNorm string) int {
+	var termBuf [16]string
+	var folderBuf [16]int
+	p := PathScorer{
+		query:   queryNorm,
+		terms:   appendFields(termBuf[:0], queryNorm, isPathSpace),
+		ordered: strings.ContainsAny(queryNorm, `/\`),
+	}
+	folder, name := splitPath(pathNorm)
+	return p.scoreName(p.scoreFolder(folder, folderBuf[:0]), name)
+}
+
+// PathScorer is PathScore for one query against many paths, each split into
+// its folder and name, so a caller can score every distinct folder once and
+// reuse that for each path in it.
+type PathScorer struct {
+	query   string
+	terms   []string
+	ordered bool
+}
+
+// NewPathScorer returns a PathScorer for a query folded with Fold.
+func NewPathScorer(queryNorm string) *PathScorer {
+	terms, ordered := PathTerms(queryNorm)
+	return &PathScorer{query: queryNorm, terms: terms, ordered: ordered}
+}
+
+// FolderScore is what a folder contributes to the score of a path in it.
+type FolderScore []int
+
+// Folder scores folderNorm, a folded path up to and including its last
+// separator, for Score.
+func (p *PathScorer) Folder(folderNorm string) FolderScore {
+	return p.scoreFolder(folderNorm, nil)
+}
+
+// Score returns PathScore(query, folderNorm+nameNorm) given the FolderScore
+// of folderNorm.
+func (p *PathScorer) Score(folder FolderScore, nameNorm string) int {
+	return p.scoreName(folder, nameNorm)
+}
+
+// splitPath splits a path after its last separator, ignoring trailing ones.
+func splitPath(path string) (folder, name string) {
+	path = strings.TrimRight(path, `/\`)
+	i := strings.LastIndexAny(path, `/\`)
+	return path[:i+1], path[i+1:]
+}
+
+// scoreFolder appends to dst what the folder segments contribute to a path
+// in folder: the best segment score of each term for unordered queries, or
+// for ordered ones the best total of every term but the last.
+func (p *PathScorer) scoreFolder(folder string, dst []int) []int {
+	var segBuf [16]string
+	segs := appendSegments(segBuf[:0], folder)
+	if p.ordered {
+		if len(p.terms) == 0 {
+			return append(dst, 0)
+		}
+		return append(dst, orderedFolderScore(p.terms[:len(p.terms)-1], segs))
+	}
+	// The name is one level below the last folder.
+	n := len(segs) + 1
+	for _, term := range p.terms {
+		best := 0
+		for j, seg := range segs {
+			best = max(best, segmentScore(term, seg, n-1-j))
+		}
+		dst = append(dst, best)
+	}
+	return dst
+}
+
+// scoreName combines the folder scores f from scoreFolder with the name.
+func (p *PathScorer) scoreName(f []int, name string) int {
+	if len(p.terms) == 0 || name == "" {
+		return 0
+	}
+	var total int
+	allInName := false
+	if p.ordered {
+		// The last term sits on the name, the others on folders.
+		if s := segmentScore(p.terms[len(p.terms)-1], name, 0); s > 0 && (len(p.terms) == 1 || f[0] > 0) {
+			total = f[0] + s
+		}
+	} else {
+		inName := false
+		allInName = true
+		for i, term := range p.terms {
+			s := segmentScore(term, name, 0)
+			inName = inName || s > 0
+			allInName = allInName && s > 0
+			best := max(s, f[i])
+			if best == 0 {
+				return 0
+			}
+			total += best
+		}
+		if !inName {
+			return 0
+		}
+	}
+	s := 0
+	if total > 0 {
+		s = total/len(p.terms) + len(p.terms)*50
+	}
+	if allInName && strings.Contains(name, p.query) {
+		// Terms that all land in the name score at least as they would
+		// against the name alone. That only beats s when the name holds
+		// the whole query: otherwise MatchScore averages the same per-term
+		// name scores s is made of.
+		s = max(s, MatchScore(p.query, name))
+	}
+	if s < minScore {
+		return 0
+	}
+	return s
+}
+
+// orderedFolderScore places each term on a later folder segment than the one
+// before it and returns the best total, counting the bonus for a last term on
+// the folder holding the name, or 0 if the terms do not fit.
+func orderedFolderScore(terms, segs []string) int {
+	if len(terms) == 0 || len(terms) > len(segs) {
+		return 0
+	}
+	// Depths count from the name, one level below the last folder.
+	n := len(segs) + 1
+	// best[j] is the best total for the terms so far with the latest on segs[j];
+	// 0 means it cannot sit there.
+	best := make([]int, len(segs))
+	for j, seg := range segs {
+		best[j] = segmentScore(terms[0], seg, n-1-j)
+	}
+	for i := 1; i < len(terms); i++ {
+		next := make([]int, len(segs))
+		for j := i; j < len(segs); j++ {
+			s := segmentScore(terms[i], segs[j], n-1-j)
+			if s == 0 {
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if best[k] == 0 {
+					continue
+				}
+				t := best[k] + s
+				if k == j-1 {
+					t += adjacentSegmentBonus
+				}
+				next[j] = max(next[j], t)
+			}
+		}
+		best = next
+	}
+	total := 0
+	for j, b := range best {
+		if b == 0 {
+			continue
+		}
+		if j == len(segs)-1 {
+			b += adjacentSegmentBonus
+		}
+		total = max(total, b)
+	}
+	return total
+}
+
+// bytesInOrder reports whether the bytes of term occur in s in order, which
+// every kind of match of a single term needs; it rejects most names without
+// the allocations of MatchScore.
+func bytesInOrder(term, s string) bool {
+	i := 0
+	for j := 0; i < len(term) && j < len(s); j++ {
+		if s[j] == term[i] {
+			i++
+		}
+	}
+	return i == len(term)
+}
+
+// segmentScore scores term against the path segment depth levels above the
+// basename. Folders only count for prefix, substring and acronym hits, since
+// a subsequence scattered across a folder name is mostly noise, and a folder
+// d levels up is worth 1/(d+1) of the same hit on the basename.
+func segmentScore(term, seg string, depth int) int {
+	if depth == 0 {
+		if !strings.Contains(seg, term) && !bytesInOrder(term, seg) {
+			return 0
+		}
+		return MatchScore(term, seg)
+	}
+	var s int
+	n := utf8.RuneCountInString(term)
+	switch {
+	case seg == term:
+		s = 10000
+	case strings.HasPrefix(seg, term):
+		s = 5000 + n*10
+	case strings.Contains(seg, term):
+		s = 2000 + n*5
+	case !bytesInOrder(term, seg) || n > 1 && !strings.ContainsFunc(seg, isSeparator):
+		// Initials are in order, and a single word's acronym is its first
+		// letter.
+		return 0
+	default:
+		s = acronymScore([]rune(term), newRuneText(seg, ""))
+	}
+	return s / (depth + 1)
+}
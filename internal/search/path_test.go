@@ -0,0 +1,84 @@
+package search
+
+import "testing"
+
+func TestPathQuery(t *testing.T) {
+	cases := map[string]bool{
+		"report":           false,
+		"docs report":      true,
+		"proj/blight/main": true,
+		`src\main`:         true,
+		"  report  ":       false,
+	}
+	for q, want := range cases {
+		if got := PathQuery(q); got != want {
+			t.Errorf("PathQuery(%q) = %v, want %v", q, got, want)
+		}
+	}
+}
+
+func TestPathScore_SeparatedTermsMatchInOrder(t *testing.T) {
+	q := "proj/blight/main"
+	want := PathScore(q, "/home/u/proj/blight/main.go")
+	if want == 0 {
+		t.Fatal("expected a match for the named folders")
+	}
+	for _, path := range []string{
+		"/home/u/proj/other/main.go",
+		"/home/u/blight/proj/main.go",
+		"/home/u/proj/blight/main/notes.txt",
+	} {
+		if s := PathScore(q, path); s != 0 {
+			t.Errorf("PathScore(%q, %q) = %d, want no match", q, path, s)
+		}
+	}
+	if s := PathScore(q, "/home/u/proj/blight/cmd/main.go"); s == 0 || s >= want {
+		t.Errorf("expected a skipped folder to score below adjacent folders, got %d vs %d", s, want)
+	}
+}
+
+func TestPathScore_BasenameOutweighsParents(t *testing.T) {
+	q := "docs report"
+	inDocs := PathScore(q, "/home/u/docs/report.pdf")
+	deep := PathScore(q, "/home/u/docs/2023/q1/report.pdf")
+	if inDocs == 0 || deep == 0 {
+		t.Fatalf("expected both to match, got %d and %d", inDocs, deep)
+	}
+	if deep >= inDocs {
+		t.Errorf("expected a nearer folder to score higher: %d vs %d", deep, inDocs)
+	}
+	if s := PathScore(q, "/home/u/docs/report/notes.txt"); s != 0 {
+		t.Errorf("expected no match when no term hits the name, got %d", s)
+	}
+	if s := PathScore("report", "/home/u/report/notes.txt"); s != 0 {
+		t.Errorf("expected a single term to need the name, got %d", s)
+	}
+}
+
+func TestPathScore_NameOnlyMatchesKeepTheirScore(t *testing.T) {
+	q := "docs report"
+	path := "/home/u/misc/docs report.pdf"
+	if got, name := PathScore(q, path), MatchScore(q, "docs report.pdf"); got < name {
+		t.Errorf("PathScore = %d, want at least the name score %d", got, name)
+	}
+}
+
+func TestPathScorer_MatchesPathScore(t *testing.T) {
+	paths := []string{
+		"/home/u/proj/blight/main.go",
+		"/home/u/proj/blight/cmd/main.go",
+		"/home/u/docs/2023/q1/report.pdf",
+		"/home/u/misc/docs report.pdf",
+		"/home/u/docs/report/notes.txt",
+		`c:\users\u\docs\report.pdf`,
+	}
+	for _, q := range []string{"proj/blight/main", "docs report", "blight main", `docs\report`, "report"} {
+		scorer := NewPathScorer(q)
+		for _, path := range paths {
+			folder, name := splitPath(path)
+			if got, want := scorer.Score(scorer.Folder(folder), name), PathScore(q, path); got != want {
+				t.Errorf("Score(%q, %q) = %d, want PathScore %d", q, path, got, want)
+			}
+		}
+	}
+}
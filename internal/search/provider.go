@@ -0,0 +1,104 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Provider is a pluggable source of search results. T is the result type the
+// caller ranks and displays; the search package never inspects it.
+type Provider[T any] interface {
+	// Name identifies the provider in logs, conventionally by the category of
+	// the results it produces.
+	Name() string
+	// Prefix is the trigger that routes a query exclusively to this provider
+	// (e.g. ">" for the command palette). Providers with an empty prefix take
+	// part in every untriggered query.
+	Prefix() string
+	// Query returns scored results for query. Implementations should return
+	// early once ctx is done.
+	Query(ctx context.Context, query string) []Scored[T]
+	// Owns reports whether id was produced by this provider.
+	Owns(id string) bool
+	// Execute runs action on the result identified by id. The empty action is
+	// the primary action (Enter).
+	Execute(id, action string) string
+}
+
+// Claimer is implemented by providers whose trigger cannot be expressed as a
+// fixed prefix (e.g. filesystem paths). A claiming provider receives the query
+// untrimmed and exclusively.
+type Claimer interface {
+	Claims(query string) bool
+}
+
+// Registry holds the registered providers in priority order.
+type Registry[T any] struct {
+	mu        sync.RWMutex
+	providers []Provider[T]
+}
+
+func NewRegistry[T any]() *Registry[T] {
+	return &Registry[T]{}
+}
+
+// Register appends p to the registry. Providers registered earlier win both
+// trigger routing and ID ownership.
+func (r *Registry[T]) Register(p Provider[T]) {
+	r.mu.Lock()
+	r.providers = append(r.providers, p)
+	r.mu.Unlock()
+}
+
+// Providers returns a snapshot of all registered providers.
+func (r *Registry[T]) Providers() []Provider[T] {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Provider[T](nil), r.providers...)
+}
+
+// Route picks the providers that should answer query. If a provider claims the
+// query or its prefix matches, that provider alone is returned together with
+// the query it should see and exclusive=true. Otherwise every provider without
+// a prefix is returned with the query unchanged.
+func (r *Registry[T]) Route(query string) (providers []Provider[T], routed string, exclusive bool) {
+	all := r.Providers()
+	for _, p := range all {
+		if c, ok := p.(Claimer); ok && c.Claims(query) {
+			return []Provider[T]{p}, query, true
+		}
+		if pre := p.Prefix(); pre != "" && strings.HasPrefix(query, pre) {
+			return []Provider[T]{p}, strings.TrimPrefix(query, pre), true
+		}
+	}
+	for _, p := range all {
+		if _, ok := p.(Claimer); ok {
+			continue
+		}
+		if p.Prefix() == "" {
+			providers = append(providers, p)
+		}
+	}
+	return providers, query, false
+}
+
+// Owner returns the first provider that owns id.
+func (r *Registry[T]) Owner(id string) (Provider[T], bool) {
+	for _, p := range r.Providers() {
+		if p.Owns(id) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// Execute dispatches action on id to its owning provider. ok is false when no
+// provider owns id.
+func (r *Registry[T]) Execute(id, action string) (result string, ok bool) {
+	p, ok := r.Owner(id)
+	if !ok {
+		return "", false
+	}
+	return p.Execute(id, action), true
+}
@@ -0,0 +1,99 @@
+package search
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type stubProvider struct {
+	name   string
+	prefix string
+	owns   string
+	claims string
+}
+
+func (p stubProvider) Name() string   { return p.name }
+func (p stubProvider) Prefix() string { return p.prefix }
+
+func (p stubProvider) Query(_ context.Context, query string) []Scored[string] {
+	return []Scored[string]{{Item: p.name + ":" + query, Score: 1, Cat: p.name}}
+}
+
+func (p stubProvider) Owns(id string) bool { return p.owns != "" && strings.HasPrefix(id, p.owns) }
+
+func (p stubProvider) Execute(id, action string) string { return p.name + "/" + action }
+
+type claimingProvider struct{ stubProvider }
+
+func (p claimingProvider) Claims(query string) bool { return strings.HasPrefix(query, p.claims) }
+
+func newTestRegistry() *Registry[string] {
+	reg := NewRegistry[string]()
+	reg.Register(claimingProvider{stubProvider{name: "path", claims: "~"}})
+	reg.Register(stubProvider{name: "palette", prefix: ">"})
+	reg.Register(stubProvider{name: "files", owns: "file:"})
+	reg.Register(stubProvider{name: "apps", owns: ""})
+	return reg
+}
+
+func TestRegistry_Route_GlobalQuery(t *testing.T) {
+	providers, routed, exclusive := newTestRegistry().Route("firefox")
+	if exclusive {
+		t.Fatal("expected a global route")
+	}
+	if routed != "firefox" {
+		t.Errorf("routed query = %q, want %q", routed, "firefox")
+	}
+	if len(providers) != 2 || providers[0].Name() != "files" || providers[1].Name() != "apps" {
+		t.Errorf("expected files and apps, got %v", providerNames(providers))
+	}
+}
+
+func TestRegistry_Route_PrefixTrimsQuery(t *testing.T) {
+	providers, routed, exclusive := newTestRegistry().Route(">gh blight")
+	if !exclusive {
+		t.Fatal("expected an exclusive route")
+	}
+	if len(providers) != 1 || providers[0].Name() != "palette" {
+		t.Fatalf("expected palette, got %v", providerNames(providers))
+	}
+	if routed != "gh blight" {
+		t.Errorf("routed query = %q, want %q", routed, "gh blight")
+	}
+}
+
+func TestRegistry_Route_ClaimKeepsQuery(t *testing.T) {
+	providers, routed, exclusive := newTestRegistry().Route("~/Documents")
+	if !exclusive || len(providers) != 1 || providers[0].Name() != "path" {
+		t.Fatalf("expected path to claim the query, got %v", providerNames(providers))
+	}
+	if routed != "~/Documents" {
+		t.Errorf("routed query = %q, want it untrimmed", routed)
+	}
+}
+
+func TestRegistry_Execute_DispatchesToOwner(t *testing.T) {
+	reg := newTestRegistry()
+	got, ok := reg.Execute("file:/tmp/a.txt", "copy-path")
+	if !ok {
+		t.Fatal("expected files provider to own the id")
+	}
+	if got != "files/copy-path" {
+		t.Errorf("Execute = %q, want %q", got, "files/copy-path")
+	}
+}
+
+func TestRegistry_Execute_UnownedID(t *testing.T) {
+	if _, ok := newTestRegistry().Execute("unknown", ""); ok {
+		t.Error("expected no owner for an unknown id")
+	}
+}
+
+func providerNames(ps []Provider[string]) []string {
+	names := make([]string, len(ps))
+	for i, p := range ps {
+		names[i] = p.Name()
+	}
+	return names
+}
@@ -1,6 +1,12 @@
 package search
 
-import "sort"
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
 
 // DefaultCaps returns the standard per-category result limits used by the ranking pass.
 func DefaultCaps() map[string]int {
@@ -15,24 +21,170 @@ func DefaultCaps() map[string]int {
 	}
 }
 
+// Profile tunes how RankAndCap merges results from different providers.
+type Profile struct {
+	// Weights scales each category's normalized scores. A category without a
+	// weight counts 1; one weighted 0 is left out.
+	Weights map[string]float64 `json:"weights,omitempty"`
+	// Caps limits how many results each category shows. A category without
+	// a cap is not limited (use this for special categories like Calculator).
+	Caps map[string]int `json:"caps,omitempty"`
+	// Priority orders categories whose results tie on everything else, first
+	// listed first (see RankAndCap). Unlisted categories come after the
+	// listed ones.
+	Priority []string `json:"priority,omitempty"`
+}
+
+// DefaultProfile returns the ranking profile used unless the user overrides
+// it. Files and folders are weighted down so that a launchable match beats an
+// equally good match buried in the file index.
+func DefaultProfile() Profile {
+	return Profile{
+		Weights: map[string]float64{
+			"Folders": 0.8,
+			"Files":   0.7,
+		},
+		Caps: DefaultCaps(),
+		Priority: []string{
+			"Calculator", "Web", "Commands", "Applications", "System",
+			"Aliases", "Folders", "Files", "Recent Files", "Clipboard", "Content",
+		},
+	}
+}
+
+// With returns p overridden by o: o's weights and caps replace p's for the
+// categories they name, and a non-empty o.Priority replaces p's.
+func (p Profile) With(o Profile) Profile {
+	out := Profile{
+		Weights:  make(map[string]float64, len(p.Weights)+len(o.Weights)),
+		Caps:     make(map[string]int, len(p.Caps)+len(o.Caps)),
+		Priority: p.Priority,
+	}
+	for _, m := range []map[string]float64{p.Weights, o.Weights} {
+		for cat, w := range m {
+			out.Weights[cat] = w
+		}
+	}
+	for _, m := range []map[string]int{p.Caps, o.Caps} {
+		for cat, c := range m {
+			out.Caps[cat] = c
+		}
+	}
+	if len(o.Priority) > 0 {
+		out.Priority = o.Priority
+	}
+	return out
+}
+
+// maxWeight bounds category weights, so no single category can bury all
+// others however its provider scores.
+const maxWeight = 10
+
+// Validate reports weights outside [0, maxWeight] and negative caps.
+func (p Profile) Validate() error {
+	for cat, w := range p.Weights {
+		if w < 0 || w > maxWeight || math.IsNaN(w) {
+			return fmt.Errorf("ranking weight for %q must be between 0 and %d", cat, maxWeight)
+		}
+	}
+	for cat, c := range p.Caps {
+		if c < 0 {
+			return fmt.Errorf("ranking cap for %q must not be negative", cat)
+		}
+	}
+	return nil
+}
+
 // Scored wraps any value with a relevance score and a category label.
 type Scored[T any] struct {
-	Item  T
+	Item T
+	// Score is how well the item matches the query, on the scale of
+	// MatchScore: 10000 for an exact match, about 5000 for a prefix, and so
+	// on. Higher scores count as an exact match.
 	Score int
+	// Usage is the item's usage score (see UsageTracker.Score), kept apart
+	// from Score so every category is boosted alike.
+	Usage int
 	Cat   string
+	// Name is the item's display name and Key a unique identity for it; both
+	// only break ties.
+	Name string
+	Key  string
+}
+
+// maxRelevance is the top of the Score scale, an exact match.
+const maxRelevance = 10000
+
+// usageBoostBase is the relevance a first launch adds. Each doubling of usage
+// adds as much again, so habits count without sheer launch counts drowning
+// out how well items match.
+const usageBoostBase = 2000
+
+// Normalize puts a Scored item's score on the scale RankAndCap compares
+// categories by: its relevance capped at an exact match, plus a boost for
+// usage that grows with its logarithm, times its category's weight.
+func (p Profile) Normalize(score, usage int, cat string) int {
+	s := float64(min(max(score, 0), maxRelevance))
+	if usage > 0 {
+		s += usageBoostBase * math.Log2(1+float64(usage)/100)
+	}
+	if w, ok := p.Weights[cat]; ok {
+		s *= w
+	}
+	return int(s)
 }
 
-// RankAndCap sorts a slice of Scored items by descending score, then caps each
-// category to the limit defined in caps. Items whose category is not in caps
-// are passed through uncapped (use this for special categories like Calculator).
-func RankAndCap[T any](items []Scored[T], caps map[string]int) []T {
-	sort.Slice(items, func(i, j int) bool {
-		return items[i].Score > items[j].Score
+// RankAndCap normalizes the scores of items with p, sorts them best first,
+// and caps each category to its limit in p. Items of a category weighted 0
+// are dropped.
+//
+// Items with equal scores are ordered by usage, then by name (see
+// compareNames), then by p.Priority and finally by key, so the same results
+// come out in the same order however providers delivered them and the
+// selection does not jump between keystrokes.
+func RankAndCap[T any](items []Scored[T], p Profile) []T {
+	priority := make(map[string]int, len(p.Priority))
+	for i, cat := range p.Priority {
+		priority[cat] = i + 1
+	}
+	rank := func(cat string) int {
+		if r, ok := priority[cat]; ok {
+			return r
+		}
+		return len(priority) + 1
+	}
+
+	norm := make([]int, len(items))
+	order := make([]int, 0, len(items))
+	for i, s := range items {
+		if w, ok := p.Weights[s.Cat]; ok && w == 0 {
+			continue
+		}
+		norm[i] = p.Normalize(s.Score, s.Usage, s.Cat)
+		order = append(order, i)
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		x, y := &items[a], &items[b]
+		if c := cmp.Compare(norm[b], norm[a]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(y.Usage, x.Usage); c != 0 {
+			return c
+		}
+		if c := compareNames(x.Name, y.Name); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(rank(x.Cat), rank(y.Cat)); c != 0 {
+			return c
+		}
+		return strings.Compare(x.Key, y.Key)
 	})
-	counts := make(map[string]int, len(caps))
-	out := make([]T, 0, len(items))
-	for _, s := range items {
-		if cap, hasCap := caps[s.Cat]; hasCap {
+
+	counts := make(map[string]int, len(p.Caps))
+	out := make([]T, 0, len(order))
+	for _, i := range order {
+		s := items[i]
+		if cap, hasCap := p.Caps[s.Cat]; hasCap {
 			if counts[s.Cat] >= cap {
 				continue
 			}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"math/rand"
+	"slices"
 	"testing"
 )
 
@@ -32,7 +34,7 @@ func TestRankAndCap_SortsByScore(t *testing.T) {
 		{Item: "mid", Score: 500, Cat: "A"},
 	}
 	caps := map[string]int{"A": 10}
-	got := RankAndCap(items, caps)
+	got := RankAndCap(items, Profile{Caps: caps})
 	if len(got) != 3 {
 		t.Fatalf("want 3 results, got %d", len(got))
 	}
@@ -49,7 +51,7 @@ func TestRankAndCap_AppliesCap(t *testing.T) {
 		{Item: "f1", Score: 6000, Cat: "Files"},
 	}
 	caps := map[string]int{"Apps": 2, "Files": 5}
-	got := RankAndCap(items, caps)
+	got := RankAndCap(items, Profile{Caps: caps})
 	if len(got) != 3 {
 		t.Fatalf("want 3 results (2 Apps + 1 Files), got %d", len(got))
 	}
@@ -65,7 +67,7 @@ func TestRankAndCap_UncappedCategory(t *testing.T) {
 	}
 	caps := map[string]int{"Applications": 1}
 	// Calculator is not in caps — should always pass through
-	got := RankAndCap(items, caps)
+	got := RankAndCap(items, Profile{Caps: caps})
 	if len(got) != 2 {
 		t.Fatalf("want 2 results, got %d", len(got))
 	}
@@ -78,7 +80,7 @@ func TestRankAndCap_CrossCategoryOrdering(t *testing.T) {
 		{Item: "weak-app", Score: 200, Cat: "Applications"},
 	}
 	caps := map[string]int{"Files": 5, "Applications": 5}
-	got := RankAndCap(items, caps)
+	got := RankAndCap(items, Profile{Caps: caps})
 	if len(got) != 2 {
 		t.Fatalf("want 2 results, got %d", len(got))
 	}
@@ -87,6 +89,107 @@ func TestRankAndCap_CrossCategoryOrdering(t *testing.T) {
 	}
 }
 
+func TestRankAndCap_Weights(t *testing.T) {
+	items := []Scored[string]{
+		{Item: "file", Score: 5000, Cat: "Files"},
+		{Item: "app", Score: 4000, Cat: "Applications"},
+		{Item: "hidden", Score: 10000, Cat: "Clipboard"},
+	}
+	p := Profile{Weights: map[string]float64{"Files": 0.5, "Clipboard": 0}}
+	got := RankAndCap(items, p)
+	if len(got) != 2 || got[0] != "app" || got[1] != "file" {
+		t.Errorf("expected the weighted-down file after the app and no clipboard, got %v", got)
+	}
+}
+
+func TestRankAndCap_NormalizesScoresAndUsage(t *testing.T) {
+	items := []Scored[string]{
+		{Item: "inflated", Score: 50000, Cat: "A"},
+		{Item: "exact-used", Score: 10000, Usage: 101, Cat: "B"},
+	}
+	got := RankAndCap(items, Profile{})
+	if got[0] != "exact-used" {
+		t.Errorf("expected scores above an exact match to be capped, got %v", got)
+	}
+
+	p := Profile{}
+	once, often := p.Normalize(0, 101, "A"), p.Normalize(0, 10000, "A")
+	if once < usageBoostBase || often > 2*maxRelevance {
+		t.Errorf("usage boost out of range: once %d, often %d", once, often)
+	}
+	if prefix := p.Normalize(5000, 0, "A"); once >= prefix {
+		t.Errorf("a single launch (%d) should not beat a prefix match (%d)", once, prefix)
+	}
+}
+
+func TestRankAndCap_PriorityBreaksTies(t *testing.T) {
+	items := []Scored[string]{
+		{Item: "file", Score: 4000, Cat: "Files"},
+		{Item: "other", Score: 4000, Cat: "Other"},
+		{Item: "alias", Score: 4000, Cat: "Aliases"},
+	}
+	got := RankAndCap(items, Profile{Priority: []string{"Aliases", "Files"}})
+	if got[0] != "alias" || got[1] != "file" || got[2] != "other" {
+		t.Errorf("expected listed categories first in order, got %v", got)
+	}
+}
+
+// TestRankAndCap_OrderDoesNotDependOnInputOrder checks that ties are broken
+// the same way whatever order providers delivered their results in.
+func TestRankAndCap_OrderDoesNotDependOnInputOrder(t *testing.T) {
+	items := []Scored[string]{
+		{Item: "notes.txt", Score: 5000, Cat: "Files", Name: "notes.txt", Key: "/home/ana/notes.txt"},
+		{Item: "Desktop/notes.txt", Score: 5000, Cat: "Files", Name: "notes.txt", Key: "/home/ana/Desktop/notes.txt"},
+		{Item: "Notes", Score: 5000, Cat: "Applications", Name: "Notes", Key: "Notes"},
+		{Item: "Notepad", Score: 5000, Usage: 2, Cat: "Applications", Name: "Notepad", Key: "Notepad"},
+		{Item: "Sticky Notes", Score: 3000, Cat: "Applications", Name: "Sticky Notes", Key: "Sticky Notes"},
+		{Item: "notes/", Score: 5000, Cat: "Folders", Name: "notes", Key: "/home/ana/Documents/notes"},
+	}
+	want := RankAndCap(slices.Clone(items), DefaultProfile())
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+		if got := RankAndCap(slices.Clone(items), DefaultProfile()); !slices.Equal(got, want) {
+			t.Fatalf("order depends on input order:\n%v\nvs\n%v", got, want)
+		}
+	}
+}
+
+func TestProfile_With(t *testing.T) {
+	base := DefaultProfile()
+	p := base.With(Profile{
+		Weights: map[string]float64{"Files": 1.5},
+		Caps:    map[string]int{"Applications": 3},
+	})
+	if p.Weights["Files"] != 1.5 || p.Weights["Folders"] != base.Weights["Folders"] {
+		t.Errorf("unexpected weights %v", p.Weights)
+	}
+	if p.Caps["Applications"] != 3 || p.Caps["Files"] != base.Caps["Files"] {
+		t.Errorf("unexpected caps %v", p.Caps)
+	}
+	if len(p.Priority) != len(base.Priority) {
+		t.Errorf("expected the default priority to be kept, got %v", p.Priority)
+	}
+	if base.Caps["Applications"] == 3 {
+		t.Error("With must not modify the profile it is called on")
+	}
+}
+
+func TestProfile_Validate(t *testing.T) {
+	if err := DefaultProfile().Validate(); err != nil {
+		t.Errorf("default profile: %v", err)
+	}
+	for _, p := range []Profile{
+		{Weights: map[string]float64{"Files": -1}},
+		{Weights: map[string]float64{"Files": 100}},
+		{Caps: map[string]int{"Files": -2}},
+	} {
+		if p.Validate() == nil {
+			t.Errorf("expected %+v to be rejected", p)
+		}
+	}
+}
+
 func TestFuzzyScoreExactBeatsPrefix(t *testing.T) {
 	exact := score("chrome", "chrome")
 	prefix := score("chrome", "chromebook")
@@ -0,0 +1,119 @@
+package search
+
+import "strings"
+
+// Typo matches are the last resort: their scores stay below minScore, so any
+// subsequence match outranks them before usage boosts are added.
+const (
+	typoScoreBase   = 45
+	typoScoreStep   = 15 // lost per edit beyond the first
+	typoPrefixCost  = 5  // lost when only the start of a word matched
+	minTypoQueryLen = 4
+)
+
+// maxTypos is the edit budget for a query of n runes: none below four runes,
+// where a single edit turns most short words into others, then one edit, and
+// two from eight runes.
+func maxTypos(n int) int {
+	switch {
+	case n < minTypoQueryLen:
+		return 0
+	case n < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// TypoScore scores query against target allowing a few typos (see Fuzzy), and
+// returns 0 when target is further away than the query's edit budget. Unlike
+// MatchScore it folds both itself.
+func TypoScore(query, target string) int {
+	q := []rune(Fold(strings.TrimSpace(query)))
+	s, _, _ := typoMatch(q, newRuneText(Fold(target), target))
+	return s
+}
+
+// typoMatch compares q with all of t and with each of its words, in full and
+// by the start of the word for a query still being typed. It returns the best
+// score and the runes [start, end) of t that matched, or a zero score.
+func typoMatch(q []rune, t runeText) (score, start, end int) {
+	k := maxTypos(len(q))
+	if k == 0 {
+		return 0, 0, 0
+	}
+	try := func(from, to int) {
+		c := t.runes[from:to]
+		if d := osaDistance(q, c, k); d <= k {
+			if s := typoScoreBase - (d-1)*typoScoreStep; s > score {
+				score, start, end = s, from, to
+			}
+		}
+		// A word the query has not finished: compare a prefix of about the
+		// query's length, allowing for an inserted or dropped rune.
+		for n := len(q) - 1; n <= len(q)+1; n++ {
+			if n <= 0 || n >= len(c) {
+				continue
+			}
+			if d := osaDistance(q, c[:n], k); d <= k {
+				if s := typoScoreBase - (d-1)*typoScoreStep - typoPrefixCost; s > score {
+					score, start, end = s, from, from+n
+				}
+			}
+		}
+	}
+
+	try(0, len(t.runes))
+	for i := 0; i < len(t.runes); {
+		if isSeparator(t.runes[i]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(t.runes) && !t.start[j] && !isSeparator(t.runes[j]) {
+			j++
+		}
+		if i > 0 || j < len(t.runes) {
+			try(i, j)
+		}
+		i = j
+	}
+	return score, start, end
+}
+
+// osaDistance returns the optimal string alignment distance between a and b:
+// insertions, deletions, substitutions and swaps of adjacent runes each cost
+// one, as in Damerau-Levenshtein, except that no substring is edited twice.
+// It gives up and returns k+1 once the distance must exceed k.
+func osaDistance(a, b []rune, k int) int {
+	if d := len(a) - len(b); d > k || -d > k {
+		return k + 1
+	}
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		rowMin := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d = min(d, prev2[j-2]+1)
+			}
+			cur[j] = d
+			rowMin = min(rowMin, d)
+		}
+		if rowMin > k {
+			return k + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
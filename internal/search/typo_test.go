@@ -0,0 +1,85 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOSADistance(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"firefox", "firefox", 0},
+		{"fierfox", "firefox", 1}, // swapped
+		{"chrmoe", "chrome", 1},   // swapped
+		{"fireox", "firefox", 1},  // dropped
+		{"firefoxx", "firefox", 1},
+		{"firefix", "firefox", 1},
+		{"ca", "abc", 3},          // no substring is edited twice
+		{"spotify", "firefox", 4}, // gives up past k
+	}
+	for _, c := range cases {
+		if got := osaDistance([]rune(c.a), []rune(c.b), 3); got != c.want {
+			t.Errorf("osaDistance(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+	if got := osaDistance([]rune("spotify"), []rune("firefox"), 1); got != 2 {
+		t.Errorf("expected the bounded distance to stop at k+1, got %d", got)
+	}
+}
+
+func TestFuzzy_TypoFallback(t *testing.T) {
+	targets := []string{"Firefox", "Google Chrome", "Thunderbird", "Spotify"}
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"fierfox", "Firefox"},
+		{"firefxo", "Firefox"},
+		{"chrmoe", "Google Chrome"},
+		{"googel chrome", "Google Chrome"},
+		{"thunderbrid", "Thunderbird"},
+		{"thudn", "Thunderbird"}, // a typo while still typing
+		{"sptoify", "Spotify"},
+	}
+	for _, c := range cases {
+		results := Fuzzy(c.query, targets, make([]int, len(targets)))
+		if len(results) == 0 || targets[results[0].Index] != c.want {
+			t.Errorf("Fuzzy(%q) = %+v, want %s first", c.query, results, c.want)
+			continue
+		}
+		if len(results[0].Positions) == 0 {
+			t.Errorf("Fuzzy(%q): expected positions for the typo match", c.query)
+		}
+	}
+}
+
+func TestFuzzy_TypoScoresBelowSubsequence(t *testing.T) {
+	targets := []string{"Firefox", "Fiery Fox Game"}
+	results := Fuzzy("fierfox", targets, []int{0, 0})
+	if len(results) != 2 || results[0].Index != 1 {
+		t.Fatalf("expected the subsequence match first, got %+v", results)
+	}
+	if results[1].Score >= minScore {
+		t.Errorf("typo score %d should stay below minScore", results[1].Score)
+	}
+}
+
+func TestFuzzy_NoTyposForShortQueries(t *testing.T) {
+	if results := Fuzzy("gti", []string{"Git"}, []int{0}); len(results) != 0 {
+		t.Errorf("expected no typo matches for a three-rune query, got %+v", results)
+	}
+	if results := FuzzyContext(context.Background(), "fierfox", []string{"Firefox"}, []int{0}); len(results) != 0 {
+		t.Errorf("expected FuzzyContext to skip the typo fallback, got %+v", results)
+	}
+}
+
+func TestTypoScore(t *testing.T) {
+	if TypoScore("Shutdwon", "Shut Down") == 0 {
+		t.Error("expected a typo match on a word of the target")
+	}
+	if TypoScore("reboot", "Lock Screen") != 0 {
+		t.Error("expected no match beyond the edit budget")
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -13,53 +15,268 @@ import (
 type usageEntry struct {
 	Count    int   `json:"count"`
 	LastUsed int64 `json:"lastUsed"` // Unix timestamp
+	// Hours and Weekdays count launches by local hour (0-23) and weekday
+	// (Sunday = 0). Entries recorded before they existed have neither.
+	Hours    []int `json:"hours,omitempty"`
+	Weekdays []int `json:"weekdays,omitempty"`
+	// Daily counts launches by local day ("2006-01-02") over the last
+	// historyDays days.
+	Daily map[string]int `json:"daily,omitempty"`
+}
+
+// historyDays is how many days of launch history an entry keeps.
+const historyDays = 90
+
+const dayLayout = "2006-01-02"
+
+// maxHistogramLaunches bounds the launches a histogram remembers: past it
+// every bucket is halved, so a changed routine shows within weeks.
+const maxHistogramLaunches = 400
+
+// record counts a launch at now in the histograms.
+func (e *usageEntry) record(now time.Time) {
+	if len(e.Hours) != 24 || len(e.Weekdays) != 7 {
+		e.Hours, e.Weekdays = make([]int, 24), make([]int, 7)
+	}
+	e.Hours[now.Hour()]++
+	e.Weekdays[now.Weekday()]++
+	if e.Daily == nil {
+		e.Daily = make(map[string]int)
+	}
+	e.Daily[now.Format(dayLayout)]++
+	oldest := now.AddDate(0, 0, -historyDays+1).Format(dayLayout)
+	for day := range e.Daily {
+		if day < oldest {
+			delete(e.Daily, day)
+		}
+	}
+	if sum(e.Weekdays) > maxHistogramLaunches {
+		for i := range e.Hours {
+			e.Hours[i] /= 2
+		}
+		for i := range e.Weekdays {
+			e.Weekdays[i] /= 2
+		}
+	}
+}
+
+// minRoutineLaunches is how many recorded launches it takes before the time
+// of day counts in full; fewer blend toward no preference.
+const minRoutineLaunches = 20
+
+// routineFactor scales an item's score by how typical now is for it, between
+// 0.5 and 2: launches within an hour of now and on the same kind of day
+// (weekday or weekend) raise it, and their absence lowers it.
+func (e usageEntry) routineFactor(now time.Time) float64 {
+	total := sum(e.Weekdays)
+	if total == 0 || len(e.Hours) != 24 {
+		return 1
+	}
+	h := now.Hour()
+	near := e.Hours[(h+23)%24] + e.Hours[h] + e.Hours[(h+1)%24]
+	hourLift := (float64(near) / float64(total)) / (3.0 / 24)
+
+	weekend := now.Weekday() == time.Saturday || now.Weekday() == time.Sunday
+	sameKind, kindDays := 0, 5.0
+	for d, n := range e.Weekdays {
+		if isWeekend := d == int(time.Saturday) || d == int(time.Sunday); isWeekend == weekend {
+			sameKind += n
+		}
+	}
+	if weekend {
+		kindDays = 2
+	}
+	dayLift := (float64(sameKind) / float64(total)) / (kindDays / 7)
+
+	lift := math.Min(2, math.Max(0.5, math.Sqrt(hourLift*dayLift)))
+	confidence := math.Min(1, float64(total)/minRoutineLaunches)
+	return 1 + confidence*(lift-1)
+}
+
+func sum(xs []int) int {
+	n := 0
+	for _, x := range xs {
+		n += x
+	}
+	return n
 }
 
 type UsageTracker struct {
 	mu      sync.RWMutex
 	entries map[string]usageEntry
 	path    string
+
+	// learned maps a folded query prefix to the IDs chosen after typing it.
+	learned     map[string]map[string]usageEntry
+	pairs       int // associations across all of learned
+	learnedPath string
+
+	saving sync.WaitGroup // background saves in flight
+
+	// saveMu orders saves: each one snapshots and writes under it, so a
+	// background save can never overwrite a newer one with older data. It
+	// also guards the modification times of the files as last read or
+	// written, which tell Reload whether another process changed them.
+	saveMu     sync.Mutex
+	usageMod   time.Time
+	learnedMod time.Time
 }
 
+// Learned associations are kept for queries up to maxLearnedQueryLen runes,
+// and pruned back to 90% of maxLearnedPairs, weakest first, once they exceed
+// it.
+const (
+	maxLearnedQueryLen = 24
+	maxLearnedPairs    = 4000
+)
+
 func NewUsageTracker() *UsageTracker {
 	home, _ := os.UserHomeDir()
+	dir := filepath.Join(home, ".blight")
 	tracker := &UsageTracker{
-		entries: make(map[string]usageEntry),
-		path:    filepath.Join(home, ".blight", "usage.json"),
+		entries:     make(map[string]usageEntry),
+		path:        filepath.Join(dir, "usage.json"),
+		learned:     make(map[string]map[string]usageEntry),
+		learnedPath: filepath.Join(dir, "learned.json"),
 	}
 	tracker.load()
+	tracker.loadLearned()
 	return tracker
 }
 
 // Record increments the usage count for id and updates the last-used timestamp.
 func (t *UsageTracker) Record(id string) {
+	now := time.Now()
 	t.mu.Lock()
 	e := t.entries[id]
 	e.Count++
-	e.LastUsed = time.Now().Unix()
+	e.LastUsed = now.Unix()
+	e.record(now)
 	t.entries[id] = e
 	t.mu.Unlock()
-	go t.save()
+	t.saveLater(t.save)
 }
 
 // Score returns a decayed usage score for id.
-// Recent uses contribute more than old ones.
-// Score = count * recencyFactor, where recencyFactor ∈ (0, 1] decays over ~30 days.
+// Recent uses contribute more than old ones, and so do items usually launched
+// at this time of day and on this kind of day (see routineFactor).
+// Score = count * recencyFactor * routineFactor, where recencyFactor ∈ (0, 1]
+// decays over ~30 days.
 func (t *UsageTracker) Score(id string) int {
+	return t.scoreAt(id, time.Now())
+}
+
+func (t *UsageTracker) scoreAt(id string, now time.Time) int {
 	t.mu.RLock()
 	e, ok := t.entries[id]
 	t.mu.RUnlock()
 
-	if !ok || e.Count == 0 {
+	if !ok {
 		return 0
 	}
+	return e.score(now)
+}
+
+func (e usageEntry) score(now time.Time) int {
+	if e.Count == 0 {
+		return 0
+	}
+	return max(1, int(float64(decayedScore(e, now))*e.routineFactor(now)))
+}
 
-	daysSince := time.Since(time.Unix(e.LastUsed, 0)).Hours() / 24
+func decayedScore(e usageEntry, now time.Time) int {
+	daysSince := now.Sub(time.Unix(e.LastUsed, 0)).Hours() / 24
 	// Decay half-life ≈ 30 days: factor = 0.5^(days/30)
 	recency := math.Pow(0.5, daysSince/30.0)
 	return int(float64(e.Count)*recency*100) + 1
 }
 
+// Learn records that id was chosen from the results for query, crediting
+// every prefix of it, so typing any of them again favors id (see
+// QueryScores).
+func (t *UsageTracker) Learn(query, id string) {
+	key := learnKey(query)
+	if key == "" || id == "" {
+		return
+	}
+	now := time.Now().Unix()
+	t.mu.Lock()
+	runes := []rune(key)
+	// Make room first, so the associations about to be credited survive
+	// however many others tie with them.
+	if t.pairs+len(runes) > maxLearnedPairs {
+		t.pruneLearnedLocked(maxLearnedPairs * 9 / 10)
+	}
+	for n := 1; n <= len(runes); n++ {
+		prefix := string(runes[:n])
+		ids := t.learned[prefix]
+		if ids == nil {
+			ids = make(map[string]usageEntry)
+			t.learned[prefix] = ids
+		}
+		e, ok := ids[id]
+		if !ok {
+			t.pairs++
+		}
+		e.Count++
+		e.LastUsed = now
+		ids[id] = e
+	}
+	t.mu.Unlock()
+	t.saveLater(t.saveLearned)
+}
+
+// QueryScores returns a decayed score, on the scale of Score, for each ID
+// chosen before after typing query.
+func (t *UsageTracker) QueryScores(query string) map[string]int {
+	key := learnKey(query)
+	now := time.Now()
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ids := t.learned[key]
+	if len(ids) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(ids))
+	for id, e := range ids {
+		out[id] = decayedScore(e, now)
+	}
+	return out
+}
+
+// learnKey normalizes a query for learning: folded, with runs of spaces
+// collapsed, and cut to maxLearnedQueryLen runes.
+func learnKey(query string) string {
+	key := Fold(strings.Join(strings.Fields(query), " "))
+	if runes := []rune(key); len(runes) > maxLearnedQueryLen {
+		key = string(runes[:maxLearnedQueryLen])
+	}
+	return key
+}
+
+// pruneLearnedLocked drops the weakest associations until keep remain.
+func (t *UsageTracker) pruneLearnedLocked(keep int) {
+	type pair struct {
+		prefix, id string
+		score      int
+	}
+	now := time.Now()
+	all := make([]pair, 0, t.pairs)
+	for prefix, ids := range t.learned {
+		for id, e := range ids {
+			all = append(all, pair{prefix, id, decayedScore(e, now)})
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].score < all[j].score })
+	for _, p := range all[:max(0, len(all)-keep)] {
+		delete(t.learned[p.prefix], p.id)
+		if len(t.learned[p.prefix]) == 0 {
+			delete(t.learned, p.prefix)
+		}
+		t.pairs--
+	}
+}
+
 // AllScores returns a snapshot of all scored entries as a map of id → score.
 // Only entries with a positive score are included.
 func (t *UsageTracker) AllScores() map[string]int {
@@ -79,7 +296,146 @@ func (t *UsageTracker) AllScores() map[string]int {
 	return out
 }
 
+// ItemUsage is what the tracker has learned about one item.
+type ItemUsage struct {
+	ID       string `json:"id"`
+	Count    int    `json:"count"`
+	LastUsed int64  `json:"lastUsed"` // Unix timestamp
+	Score    int    `json:"score"`    // as Score returns it now
+	// Hours and Weekdays are launch histograms; see usageEntry.
+	Hours    []int      `json:"hours,omitempty"`
+	Weekdays []int      `json:"weekdays,omitempty"`
+	History  []DayCount `json:"history,omitempty"` // oldest first
+	// Queries are the typed queries the item was chosen from, longest
+	// first (see Learn).
+	Queries []string `json:"queries,omitempty"`
+}
+
+// DayCount is the number of launches on one local day.
+type DayCount struct {
+	Day   string `json:"day"` // "2006-01-02"
+	Count int    `json:"count"`
+}
+
+// Items returns every item that has been launched or learned, highest score
+// first.
+func (t *UsageTracker) Items() []ItemUsage {
+	now := time.Now()
+	t.mu.RLock()
+	queries := make(map[string][]string)
+	for prefix, ids := range t.learned {
+		for id := range ids {
+			queries[id] = append(queries[id], prefix)
+		}
+	}
+	items := make([]ItemUsage, 0, len(t.entries))
+	for id, e := range t.entries {
+		item := ItemUsage{
+			ID:       id,
+			Count:    e.Count,
+			LastUsed: e.LastUsed,
+			Hours:    e.Hours,
+			Weekdays: e.Weekdays,
+			Score:    e.score(now),
+			Queries:  fullQueries(queries[id]),
+		}
+		for day, n := range e.Daily {
+			item.History = append(item.History, DayCount{Day: day, Count: n})
+		}
+		sort.Slice(item.History, func(i, j int) bool { return item.History[i].Day < item.History[j].Day })
+		items = append(items, item)
+	}
+	// Results that are never launched through Record, such as web searches,
+	// are only known from Learn.
+	for id, qs := range queries {
+		if _, ok := t.entries[id]; !ok {
+			items = append(items, ItemUsage{ID: id, Queries: fullQueries(qs)})
+		}
+	}
+	t.mu.RUnlock()
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// fullQueries drops every prefix that is itself a prefix of another, leaving
+// the queries as they were typed, longest first.
+func fullQueries(prefixes []string) []string {
+	sort.Slice(prefixes, func(i, j int) bool {
+		if len(prefixes[i]) != len(prefixes[j]) {
+			return len(prefixes[i]) > len(prefixes[j])
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	var out []string
+	for _, p := range prefixes {
+		covered := false
+		for _, q := range out {
+			if strings.HasPrefix(q, p) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Forget drops everything recorded about id, including what was learned
+// about the queries it was chosen from.
+func (t *UsageTracker) Forget(id string) {
+	t.mu.Lock()
+	delete(t.entries, id)
+	for prefix, ids := range t.learned {
+		if _, ok := ids[id]; ok {
+			delete(ids, id)
+			t.pairs--
+			if len(ids) == 0 {
+				delete(t.learned, prefix)
+			}
+		}
+	}
+	t.mu.Unlock()
+	t.save()
+	t.saveLearned()
+}
+
+// Reset forgets all usage.
+func (t *UsageTracker) Reset() {
+	t.mu.Lock()
+	t.entries = make(map[string]usageEntry)
+	t.learned = make(map[string]map[string]usageEntry)
+	t.pairs = 0
+	t.mu.Unlock()
+	t.save()
+	t.saveLearned()
+}
+
+// Reload re-reads usage from disk if another process, such as the settings
+// window, has changed it since this one last read or wrote it.
+func (t *UsageTracker) Reload() {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	if modTime(t.path).Equal(t.usageMod) && modTime(t.learnedPath).Equal(t.learnedMod) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = make(map[string]usageEntry)
+	t.learned = make(map[string]map[string]usageEntry)
+	t.pairs = 0
+	t.load()
+	t.loadLearned()
+}
+
 func (t *UsageTracker) load() {
+	t.usageMod = modTime(t.path)
 	data, err := os.ReadFile(t.path)
 	if err != nil {
 		return
@@ -98,22 +454,79 @@ func (t *UsageTracker) load() {
 		for id, count := range oldFmt {
 			t.entries[id] = usageEntry{Count: count, LastUsed: time.Now().Unix()}
 		}
-		go t.save() // persist migrated format
+		t.saveLater(t.save) // persist migrated format
+	}
+}
+
+func (t *UsageTracker) loadLearned() {
+	t.learnedMod = modTime(t.learnedPath)
+	data, err := os.ReadFile(t.learnedPath)
+	if err != nil {
+		return
+	}
+	var learned map[string]map[string]usageEntry
+	if err := json.Unmarshal(data, &learned); err != nil {
+		return
+	}
+	t.learned = learned
+	t.pairs = 0
+	for _, ids := range learned {
+		t.pairs += len(ids)
 	}
 }
 
 func (t *UsageTracker) save() {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
 	t.mu.RLock()
 	data, err := json.MarshalIndent(t.entries, "", "  ")
 	t.mu.RUnlock()
 	if err != nil {
 		return
 	}
-	os.MkdirAll(filepath.Dir(t.path), 0755)
-	// Write to a temp file then rename so a mid-write crash never corrupts usage.json.
-	tmp := t.path + ".tmp"
+	writeFileAtomic(t.path, data)
+	t.usageMod = modTime(t.path)
+}
+
+func (t *UsageTracker) saveLearned() {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+	t.mu.RLock()
+	data, err := json.Marshal(t.learned)
+	t.mu.RUnlock()
+	if err != nil {
+		return
+	}
+	writeFileAtomic(t.learnedPath, data)
+	t.learnedMod = modTime(t.learnedPath)
+}
+
+// saveLater runs save in the background.
+func (t *UsageTracker) saveLater(save func()) {
+	t.saving.Add(1)
+	go func() {
+		defer t.saving.Done()
+		save()
+	}()
+}
+
+// writeFileAtomic writes to a temp file then renames it over path, so a
+// mid-write crash never leaves a corrupt file behind.
+func writeFileAtomic(path string, data []byte) {
+	os.MkdirAll(filepath.Dir(path), 0755)
+	tmp := path + ".tmp"
 	if err := os.WriteFile(tmp, data, 0644); err != nil {
 		return
 	}
-	os.Rename(tmp, t.path)
+	os.Rename(tmp, path)
+}
+
+// modTime returns when path was last modified, or the zero time if it does
+// not exist.
+func modTime(path string) time.Time {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return fi.ModTime()
 }
@@ -0,0 +1,219 @@
+package search
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func newTestTracker(t *testing.T) *UsageTracker {
+	t.Helper()
+	dir := t.TempDir()
+	tr := &UsageTracker{
+		entries:     make(map[string]usageEntry),
+		path:        filepath.Join(dir, "usage.json"),
+		learned:     make(map[string]map[string]usageEntry),
+		learnedPath: filepath.Join(dir, "learned.json"),
+	}
+	t.Cleanup(tr.saving.Wait) // before dir is removed
+	return tr
+}
+
+func TestUsageTracker_LearnBoostsPrefixes(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Learn("Term", "Windows Terminal")
+	tr.Learn("term", "Windows Terminal")
+	tr.Learn("te", "TeamViewer")
+
+	for _, q := range []string{"t", "te", "ter", "TERM", " term "} {
+		if tr.QueryScores(q)["Windows Terminal"] == 0 {
+			t.Errorf("QueryScores(%q): expected Windows Terminal to be boosted", q)
+		}
+	}
+	if got := tr.QueryScores("termi"); got != nil {
+		t.Errorf("expected nothing learned for a longer query, got %v", got)
+	}
+	te := tr.QueryScores("te")
+	if te["Windows Terminal"] <= te["TeamViewer"] {
+		t.Errorf("expected the twice-chosen result to score higher: %v", te)
+	}
+	if got := tr.QueryScores("ter")["TeamViewer"]; got != 0 {
+		t.Errorf("expected no boost past the prefix TeamViewer was chosen with, got %d", got)
+	}
+}
+
+func TestUsageTracker_LearnedScoresDecay(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.learned["fi"] = map[string]usageEntry{
+		"Firefox": {Count: 1, LastUsed: time.Now().Unix()},
+		"Finder":  {Count: 1, LastUsed: time.Now().Add(-60 * 24 * time.Hour).Unix()},
+	}
+	got := tr.QueryScores("fi")
+	if got["Firefox"] <= got["Finder"] {
+		t.Errorf("expected the older choice to have decayed: %v", got)
+	}
+}
+
+func TestUsageTracker_LearnCapsStoredPairs(t *testing.T) {
+	tr := newTestTracker(t)
+	old := time.Now().Add(-90 * 24 * time.Hour).Unix()
+	tr.learned["stale"] = map[string]usageEntry{"old": {Count: 1, LastUsed: old}}
+	tr.pairs = 1
+	now := time.Now().Unix()
+	for i := 0; tr.pairs < maxLearnedPairs; i++ {
+		tr.learned[fmt.Sprintf("q%d", i)] = map[string]usageEntry{"x": {Count: 1, LastUsed: now}}
+		tr.pairs++
+	}
+	tr.Learn("one more query", "y")
+	if tr.pairs > maxLearnedPairs {
+		t.Errorf("stored %d pairs, want at most %d", tr.pairs, maxLearnedPairs)
+	}
+	if _, ok := tr.learned["stale"]; ok {
+		t.Error("expected the weakest association to be pruned first")
+	}
+	if tr.QueryScores("one more query")["y"] == 0 {
+		t.Error("expected the newest association to survive pruning")
+	}
+}
+
+func TestUsageTracker_LearnedPersists(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Learn("ff", "Firefox")
+	tr.saveLearned()
+
+	loaded := newTestTracker(t)
+	loaded.learnedPath = tr.learnedPath
+	loaded.loadLearned()
+	if loaded.QueryScores("ff")["Firefox"] == 0 || loaded.pairs != 2 {
+		t.Errorf("expected the association to round-trip, got %v (%d pairs)", loaded.learned, loaded.pairs)
+	}
+}
+
+func TestUsageTracker_FavorsUsualTimes(t *testing.T) {
+	tr := newTestTracker(t)
+	monday := time.Date(2024, 6, 3, 0, 0, 0, 0, time.Local)
+	saturday := time.Date(2024, 6, 8, 0, 0, 0, 0, time.Local)
+	add := func(id string, day time.Time, hour, n int) {
+		e := tr.entries[id]
+		for i := 0; i < n; i++ {
+			at := day.AddDate(0, 0, -7*(i%4)).Add(time.Duration(hour) * time.Hour)
+			e.Count++
+			e.record(at)
+		}
+		tr.entries[id] = e
+	}
+	add("Mail", monday, 8, 30)
+	add("Game Launcher", saturday, 20, 30)
+	last := saturday.Add(21 * time.Hour).Unix()
+	for id, e := range tr.entries {
+		e.LastUsed = last
+		tr.entries[id] = e
+	}
+
+	mondayMorning := monday.AddDate(0, 0, 7).Add(8*time.Hour + 30*time.Minute)
+	if mail, game := tr.scoreAt("Mail", mondayMorning), tr.scoreAt("Game Launcher", mondayMorning); mail <= game {
+		t.Errorf("Monday morning: Mail %d should outrank Game Launcher %d", mail, game)
+	}
+	saturdayEvening := saturday.AddDate(0, 0, 7).Add(20 * time.Hour)
+	if mail, game := tr.scoreAt("Mail", saturdayEvening), tr.scoreAt("Game Launcher", saturdayEvening); game <= mail {
+		t.Errorf("Saturday evening: Game Launcher %d should outrank Mail %d", game, mail)
+	}
+}
+
+func TestUsageEntry_RoutineFactor(t *testing.T) {
+	now := time.Date(2024, 6, 5, 9, 0, 0, 0, time.Local)
+	if f := (usageEntry{Count: 3}).routineFactor(now); f != 1 {
+		t.Errorf("expected no preference without a histogram, got %v", f)
+	}
+
+	var few usageEntry
+	few.record(now)
+	if f := few.routineFactor(now); f <= 1 || f >= 1.2 {
+		t.Errorf("expected one launch to count only a little, got %v", f)
+	}
+
+	var many usageEntry
+	for i := 0; i < 2*maxHistogramLaunches; i++ {
+		many.record(now)
+	}
+	if n := sum(many.Weekdays); n > maxHistogramLaunches {
+		t.Errorf("histogram holds %d launches, want at most %d", n, maxHistogramLaunches)
+	}
+	if f := many.routineFactor(now); f != 2 {
+		t.Errorf("expected the factor to cap at 2, got %v", f)
+	}
+	if f := many.routineFactor(now.Add(12 * time.Hour)); f != 0.5 {
+		t.Errorf("expected an unusual hour to floor at 0.5, got %v", f)
+	}
+}
+
+func TestUsageTracker_Items(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Record("Firefox")
+	tr.Record("Firefox")
+	tr.Record("Notepad")
+	tr.Learn("fire", "Firefox")
+	tr.Learn("fox", "Firefox")
+
+	items := tr.Items()
+	if len(items) != 2 || items[0].ID != "Firefox" || items[1].ID != "Notepad" {
+		t.Fatalf("expected Firefox then Notepad, got %+v", items)
+	}
+	ff := items[0]
+	if ff.Count != 2 || ff.Score == 0 || ff.LastUsed == 0 {
+		t.Errorf("unexpected stats %+v", ff)
+	}
+	today := time.Now().Format(dayLayout)
+	if len(ff.History) != 1 || ff.History[0] != (DayCount{Day: today, Count: 2}) {
+		t.Errorf("expected two launches today, got %v", ff.History)
+	}
+	if !slices.Equal(ff.Queries, []string{"fire", "fox"}) {
+		t.Errorf("expected the typed queries without their prefixes, got %v", ff.Queries)
+	}
+}
+
+func TestUsageEntry_HistoryKeepsRecentDays(t *testing.T) {
+	var e usageEntry
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for d := 0; d < 2*historyDays; d++ {
+		e.record(start.AddDate(0, 0, d))
+	}
+	if len(e.Daily) != historyDays {
+		t.Errorf("expected %d days of history, got %d", historyDays, len(e.Daily))
+	}
+	if _, ok := e.Daily[start.Format(dayLayout)]; ok {
+		t.Error("expected the oldest days to be pruned")
+	}
+}
+
+func TestUsageTracker_ForgetAndReset(t *testing.T) {
+	tr := newTestTracker(t)
+	tr.Record("Firefox")
+	tr.Record("Notepad")
+	tr.Learn("no", "Notepad")
+	tr.Learn("no", "Firefox")
+
+	tr.Forget("Firefox")
+	if tr.Score("Firefox") != 0 || tr.QueryScores("no")["Firefox"] != 0 {
+		t.Error("expected Firefox to be forgotten")
+	}
+	if tr.Score("Notepad") == 0 || tr.QueryScores("no")["Notepad"] == 0 || tr.pairs != 2 {
+		t.Errorf("expected Notepad to be kept, got %d pairs", tr.pairs)
+	}
+
+	// Forget saves synchronously, so another process sees it on Reload.
+	other := newTestTracker(t)
+	other.path, other.learnedPath = tr.path, tr.learnedPath
+	other.Reload()
+	if other.Score("Firefox") != 0 || other.Score("Notepad") == 0 || other.pairs != 2 {
+		t.Errorf("expected the forget to persist, got %v", other.entries)
+	}
+
+	tr.Reset()
+	other.Reload()
+	if len(other.Items()) != 0 || other.QueryScores("no") != nil {
+		t.Error("expected nothing to be left after Reset")
+	}
+}